@@ -38,6 +38,20 @@ func (m *DepSync) IntegrationTests(sourceDir *dagger.Directory, githubToken *dag
 	return c.WithExec([]string{"go", "test", "-tags=integration", "./pkg/adapters/...", "-v"})
 }
 
+// E2ETests runs the end-to-end tests in test/e2e/ with the e2e build tag, against the
+// Forgejo instance at forgejoURL, which must be reachable from the Dagger engine.
+func (m *DepSync) E2ETests(
+	sourceDir *dagger.Directory,
+	forgejoURL string,
+	forgejoToken *dagger.Secret,
+) *dagger.Container {
+	c := dag.Container().From("golang:1.24")
+	c = withGoCodeAndCacheAsWorkDirectory(c, sourceDir)
+	c = c.WithEnvVariable("FORGEJO_URL", forgejoURL).
+		WithSecretVariable("FORGEJO_TOKEN", forgejoToken)
+	return c.WithExec([]string{"go", "test", "-tags=e2e", "./test/e2e/...", "-v"})
+}
+
 // Lint runs golangci-lint on the main repo (./...) only.
 func (m *DepSync) Lint(sourceDir *dagger.Directory) *dagger.Container {
 	c := dag.Container().
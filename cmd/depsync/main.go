@@ -3,15 +3,25 @@ package main
 import (
 	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/daemon"
 	"github.com/cryptellation/depsync/pkg/depsync"
 	"github.com/cryptellation/depsync/pkg/logging"
+	"github.com/cryptellation/depsync/pkg/plan"
+	"github.com/cryptellation/depsync/pkg/webhook"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
 
-var configPath string
+var (
+	configPath string
+	dryRun     bool
+	planOutput string
+	runner     string
+)
 
 func main() {
 	logging.Init()
@@ -20,31 +30,143 @@ func main() {
 		Use:   "depsync",
 		Short: "Depsync synchronizes dependencies across your repositories",
 		Run: func(_ *cobra.Command, _ []string) {
-			cfg, err := config.Load(configPath)
+			_, c := newDepSync()
+			defer c.Close()
+
+			ctx := context.Background()
+			c.RunWithLogging(ctx)
+
+			if err := c.Report().Print(os.Stdout); err != nil {
+				logging.L().Fatal("Failed to print report", zap.Error(err))
+			}
+			if p := c.Plan(); p != nil {
+				if err := p.Print(os.Stdout); err != nil {
+					logging.L().Fatal("Failed to print plan", zap.Error(err))
+				}
+			}
+		},
+	}
+	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the actions depsync would take, without executing them")
+
+	var serveCmd = &cobra.Command{
+		Use:   "serve",
+		Short: "Run depsync continuously, on a schedule",
+		Run: func(_ *cobra.Command, _ []string) {
+			cfg, c := newDepSync()
+			defer c.Close()
+
+			d, err := daemon.New(c, cfg.Serve)
 			if err != nil {
-				logging.L().Fatal("Failed to load config", zap.Error(err))
+				logging.L().Fatal("Failed to create daemon", zap.Error(err))
+			}
+
+			if cfg.Serve.Webhook.Enabled {
+				secret := os.Getenv("GITHUB_WEBHOOK_SECRET")
+				if secret == "" {
+					logging.L().Fatal("GITHUB_WEBHOOK_SECRET environment variable is not set")
+				}
+				d.Handle(cfg.Serve.Webhook.Path, webhook.NewHandler([]byte(secret), c, d))
 			}
 
-			token := os.Getenv("GITHUB_TOKEN")
-			if token == "" {
-				logging.L().Fatal("GITHUB_TOKEN environment variable is not set")
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if err := d.Run(ctx); err != nil {
+				logging.L().Error("Daemon stopped", zap.Error(err))
+			}
+		},
+	}
+	rootCmd.AddCommand(serveCmd)
+
+	var planCmd = &cobra.Command{
+		Use:   "plan",
+		Short: "Write the dependency updates to make in a plan file, to be reviewed then applied",
+		Run: func(_ *cobra.Command, _ []string) {
+			dryRun = true
+			_, c := newDepSync()
+			defer c.Close()
+
+			file, err := c.CreatePlan(context.Background())
+			if err != nil {
+				logging.L().Fatal("Failed to create plan", zap.Error(err))
+			}
+			if err := plan.Write(planOutput, file); err != nil {
+				logging.L().Fatal("Failed to write plan", zap.Error(err))
 			}
+			if err := c.Plan().Print(os.Stdout); err != nil {
+				logging.L().Fatal("Failed to print plan", zap.Error(err))
+			}
+		},
+	}
+	planCmd.Flags().StringVarP(&planOutput, "output", "o", "plan.json", "Path to the plan file")
+	rootCmd.AddCommand(planCmd)
 
-			c, err := depsync.New(cfg, token)
+	var applyCmd = &cobra.Command{
+		Use:   "apply <plan file>",
+		Short: "Apply a plan file, if its preconditions still hold",
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			file, err := plan.Read(args[0])
 			if err != nil {
-				logging.L().Fatal("Failed to create depsync", zap.Error(err))
+				logging.L().Fatal("Failed to read plan", zap.Error(err))
 			}
+
+			_, c := newDepSync()
 			defer c.Close()
 
-			ctx := context.Background()
-			c.RunWithLogging(ctx)
+			if err := c.ApplyPlan(context.Background(), file); err != nil {
+				logging.L().Fatal("Failed to apply plan", zap.Error(err))
+			}
+			if err := c.Report().Print(os.Stdout); err != nil {
+				logging.L().Fatal("Failed to print report", zap.Error(err))
+			}
 		},
 	}
+	rootCmd.AddCommand(applyCmd)
 
 	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "configs/depsync.yaml", "Path to the config file")
+	rootCmd.PersistentFlags().StringVar(&runner, "runner", "",
+		"Runner of the git and go operations: dagger or native (overrides the config)")
 
 	if err := rootCmd.Execute(); err != nil {
 		logging.L().Error("Command execution failed", zap.Error(err))
 		os.Exit(1)
 	}
 }
+
+// newDepSync loads the configuration and creates the depsync instance.
+func newDepSync() (*config.Config, *depsync.DepSync) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		logging.L().Fatal("Failed to load config", zap.Error(err))
+	}
+	if dryRun {
+		cfg.DryRun = true
+	}
+	if runner != "" {
+		cfg.Runner = runner
+	}
+
+	// The token is not required when authenticating as a GitHub App, nor for offline
+	// runs on local repositories
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" && cfg.GitHub.App.ID == 0 && !hasLocalForge(cfg) {
+		logging.L().Fatal("GITHUB_TOKEN environment variable is not set")
+	}
+
+	c, err := depsync.New(cfg, token)
+	if err != nil {
+		logging.L().Fatal("Failed to create depsync", zap.Error(err))
+	}
+	return cfg, c
+}
+
+// hasLocalForge returns true if a local forge is configured.
+func hasLocalForge(cfg *config.Config) bool {
+	for _, f := range cfg.Forges {
+		if f.Type == config.ForgeLocal {
+			return true
+		}
+	}
+	return false
+}
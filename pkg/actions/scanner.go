@@ -0,0 +1,167 @@
+//go:generate go run go.uber.org/mock/mockgen@v0.5.2 -destination=mock_scanner.gen.go -package=actions -source=scanner.go Scanner
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/repo"
+	"golang.org/x/mod/semver"
+)
+
+// Release is the latest release of a tracked repository.
+type Release struct {
+	Version string
+	SHA     string
+}
+
+// Update represents the workflows of a repository that lag behind the latest
+// release of a tracked repository.
+type Update struct {
+	RepoURL string
+	// Repository is the "owner/repo" of the tracked repository.
+	Repository string
+	Release    Release
+	References []Reference
+	// Files contains the updated content of the workflow files, by path.
+	Files map[string][]byte
+}
+
+// Scanner scans the workflows of repositories for references lagging behind
+// the latest release of tracked repositories.
+type Scanner interface {
+	// Scan scans the workflows of the given repositories for references to the tracked
+	// repositories, and returns the updates sorted by repository URL and tracked repository.
+	Scan(ctx context.Context, repositories, tracked []string) ([]Update, error)
+}
+
+type scanner struct {
+	client  forge.Client
+	fetcher repo.FilesFetcher
+}
+
+// NewScanner creates a new Scanner.
+func NewScanner(client forge.Client, fetcher repo.FilesFetcher) Scanner {
+	return &scanner{
+		client:  client,
+		fetcher: fetcher,
+	}
+}
+
+// Scan implements the Scanner interface.
+func (s *scanner) Scan(ctx context.Context, repositories, tracked []string) ([]Update, error) {
+	releases, err := s.latestReleases(ctx, tracked)
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []Update
+	for _, repoURL := range repositories {
+		repoUpdates, err := s.scanRepository(ctx, repoURL, releases)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, repoUpdates...)
+	}
+
+	sort.Slice(updates, func(i, j int) bool {
+		if updates[i].RepoURL != updates[j].RepoURL {
+			return updates[i].RepoURL < updates[j].RepoURL
+		}
+		return updates[i].Repository < updates[j].Repository
+	})
+	return updates, nil
+}
+
+// latestReleases returns the latest release of each tracked repository, by lowercased "owner/repo".
+// Repositories without semantic version tags are ignored.
+func (s *scanner) latestReleases(ctx context.Context, tracked []string) (map[string]Release, error) {
+	releases := make(map[string]Release)
+	for _, repoURL := range tracked {
+		host, owner, name := adapters.ParseRepository(repoURL)
+		if host == "" {
+			return nil, fmt.Errorf("%w: %s", repo.ErrInvalidRepoURL, repoURL)
+		}
+
+		tags, err := s.client.ListTags(ctx, forge.ListTagsParams{Host: host, Owner: owner, Repo: name})
+		if err != nil {
+			return nil, fmt.Errorf("error fetching tags for %s: %w", repoURL, err)
+		}
+
+		latest := repo.LatestSemverTag(tags)
+		if latest == "" {
+			continue
+		}
+		for _, tag := range tags {
+			if tag.Name == latest {
+				releases[strings.ToLower(owner+"/"+name)] = Release{
+					Version: latest,
+					SHA:     tag.CommitSHA,
+				}
+				break
+			}
+		}
+	}
+	return releases, nil
+}
+
+// scanRepository scans the workflows of a repository.
+func (s *scanner) scanRepository(ctx context.Context, repoURL string, releases map[string]Release) ([]Update, error) {
+	paths, err := s.fetcher.List(ctx, repoURL, adapters.DefaultBranch, WorkflowsDir)
+	if err != nil {
+		return nil, fmt.Errorf("error listing workflows of %s: %w", repoURL, err)
+	}
+
+	updates := make(map[string]*Update)
+	for _, path := range paths {
+		if !isWorkflowFile(path) {
+			continue
+		}
+
+		results, err := s.fetcher.Fetch(ctx, repoURL, adapters.DefaultBranch, path)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching workflow %s of %s: %w", path, repoURL, err)
+		}
+		content := results[path]
+
+		for _, ref := range ParseWorkflow(path, content) {
+			key := strings.ToLower(ref.Repository)
+			release, ok := releases[key]
+			if !ok || !isLagging(ref, release) {
+				continue
+			}
+
+			update, ok := updates[key]
+			if !ok {
+				update = &Update{
+					RepoURL:    repoURL,
+					Repository: ref.Repository,
+					Release:    release,
+					Files:      make(map[string][]byte),
+				}
+				updates[key] = update
+			}
+			update.References = append(update.References, ref)
+			update.Files[path] = RewriteWorkflow(content, ref.Repository, release.Version, release.SHA)
+		}
+	}
+
+	res := make([]Update, 0, len(updates))
+	for _, u := range updates {
+		res = append(res, *u)
+	}
+	return res, nil
+}
+
+// isLagging checks if the reference lags behind the release and can be updated.
+func isLagging(ref Reference, release Release) bool {
+	if ref.Version == "" || (ref.Pinned && release.SHA == "") {
+		return false
+	}
+	return semver.Compare(ref.Version, release.Version) < 0
+}
@@ -0,0 +1,53 @@
+//go:build unit
+// +build unit
+
+package actions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/repo"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestScan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockClient := forge.NewMockClient(ctrl)
+	mockFetcher := repo.NewMockFilesFetcher(ctrl)
+	scanner := NewScanner(mockClient, mockFetcher)
+
+	newSHA := "fedcba9876543210fedcba9876543210fedcba98"
+	actionsTags := forge.ListTagsParams{Host: "github.com", Owner: "org", Repo: "actions"}
+	mockClient.EXPECT().ListTags(gomock.Any(), actionsTags).Return([]forge.Tag{
+		{Name: "v1.4.0", CommitSHA: newSHA},
+		{Name: "v1.3.0", CommitSHA: testSHA},
+	}, nil)
+	serviceTags := forge.ListTagsParams{Host: "github.com", Owner: "org", Repo: "service"}
+	mockClient.EXPECT().ListTags(gomock.Any(), serviceTags).Return(nil, nil)
+
+	mockFetcher.EXPECT().List(gomock.Any(), "https://github.com/org/service", "main", ".github/workflows").
+		Return([]string{".github/workflows/ci.yml", ".github/workflows/README.md"}, nil)
+	mockFetcher.EXPECT().Fetch(gomock.Any(), "https://github.com/org/service", "main", ".github/workflows/ci.yml").
+		Return(map[string][]byte{".github/workflows/ci.yml": []byte(testWorkflow)}, nil)
+	mockFetcher.EXPECT().List(gomock.Any(), "https://github.com/org/actions", "main", ".github/workflows").
+		Return(nil, nil)
+
+	repositories := []string{"https://github.com/org/service", "https://github.com/org/actions"}
+	updates, err := scanner.Scan(context.Background(), repositories, repositories)
+	require.NoError(t, err)
+	require.Len(t, updates, 1)
+
+	update := updates[0]
+	require.Equal(t, "https://github.com/org/service", update.RepoURL)
+	require.Equal(t, "org/actions", update.Repository)
+	require.Equal(t, Release{Version: "v1.4.0", SHA: newSHA}, update.Release)
+	require.Len(t, update.References, 2)
+	require.Equal(t,
+		RewriteWorkflow([]byte(testWorkflow), "org/actions", "v1.4.0", newSHA),
+		update.Files[".github/workflows/ci.yml"])
+}
@@ -0,0 +1,104 @@
+package actions
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// WorkflowsDir is the directory containing the GitHub workflows of a repository.
+const WorkflowsDir = ".github/workflows"
+
+// usesRE matches "uses:" lines referencing an action or a reusable workflow with a ref,
+// optionally followed by a comment (e.g. "uses: org/actions/setup@<sha> # v1.3.0").
+var usesRE = regexp.MustCompile(
+	`^(\s*(?:-\s+)?uses:\s*)(['"]?)([\w.-]+/[\w.-]+)((?:/[^@\s'"]*)?)@([^\s'"#]+)(['"]?)(\s*#\s*(\S+))?(.*)$`)
+
+// shaRE matches full commit SHAs used to pin actions.
+var shaRE = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// Reference is a "uses:" reference to an action or a reusable workflow in a workflow file.
+type Reference struct {
+	File string
+	Line int
+	// Repository is the "owner/repo" hosting the action or workflow.
+	Repository string
+	// Path is the path of the action or workflow inside the repository, if any.
+	Path string
+	Ref  string
+	// Version is the semantic version of the reference: the ref itself, or the
+	// version comment for SHA pins. It is empty when it can't be determined.
+	Version string
+	Pinned  bool
+}
+
+// ParseWorkflow returns the references of a workflow file.
+func ParseWorkflow(file string, content []byte) []Reference {
+	var refs []Reference
+	for i, line := range strings.Split(string(content), "\n") {
+		m := usesRE.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		ref := Reference{
+			File:       file,
+			Line:       i + 1,
+			Repository: m[3],
+			Path:       strings.TrimPrefix(m[4], "/"),
+			Ref:        m[5],
+			Pinned:     shaRE.MatchString(m[5]),
+		}
+		if ref.Pinned {
+			ref.Version = m[8]
+		} else {
+			ref.Version = ref.Ref
+		}
+		if !isFullSemver(ref.Version) {
+			ref.Version = ""
+		}
+
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// RewriteWorkflow updates the references of a workflow file to the given repository
+// to the given version. SHA pins are updated to the given SHA, and their version comment
+// to the version.
+func RewriteWorkflow(content []byte, repository, version, sha string) []byte {
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		m := usesRE.FindStringSubmatch(line)
+		if m == nil || !strings.EqualFold(m[3], repository) {
+			continue
+		}
+
+		pinned := shaRE.MatchString(m[5])
+		current := m[5]
+		if pinned {
+			current = m[8]
+		}
+		if !isFullSemver(current) || semver.Compare(current, version) >= 0 || (pinned && sha == "") {
+			continue
+		}
+
+		if pinned {
+			lines[i] = m[1] + m[2] + m[3] + m[4] + "@" + sha + m[6] + " # " + version + m[9]
+		} else {
+			lines[i] = m[1] + m[2] + m[3] + m[4] + "@" + version + m[6] + m[7] + m[9]
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// isWorkflowFile checks if the path is a YAML workflow file.
+func isWorkflowFile(path string) bool {
+	return strings.HasSuffix(path, ".yml") || strings.HasSuffix(path, ".yaml")
+}
+
+// isFullSemver checks if the version is a full semantic version without pre-release (e.g. v1.2.3).
+func isFullSemver(version string) bool {
+	return semver.IsValid(version) && semver.Canonical(version) == version && semver.Prerelease(version) == ""
+}
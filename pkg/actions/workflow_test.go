@@ -0,0 +1,60 @@
+//go:build unit
+// +build unit
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSHA = "0123456789abcdef0123456789abcdef01234567"
+
+const testWorkflow = `name: CI
+jobs:
+  build:
+    uses: org/workflows/.github/workflows/build.yml@v1.2.0
+  test:
+    steps:
+      - uses: actions/checkout@v4
+      - uses: org/actions/setup@` + testSHA + ` # v1.3.0
+      - uses: "org/actions/lint@v1.3.0"
+      - uses: org/actions/test@main
+`
+
+func TestParseWorkflow(t *testing.T) {
+	refs := ParseWorkflow("ci.yml", []byte(testWorkflow))
+	require.Equal(t, []Reference{
+		{File: "ci.yml", Line: 4, Repository: "org/workflows", Path: ".github/workflows/build.yml",
+			Ref: "v1.2.0", Version: "v1.2.0"},
+		{File: "ci.yml", Line: 7, Repository: "actions/checkout", Ref: "v4"},
+		{File: "ci.yml", Line: 8, Repository: "org/actions", Path: "setup",
+			Ref: testSHA, Version: "v1.3.0", Pinned: true},
+		{File: "ci.yml", Line: 9, Repository: "org/actions", Path: "lint", Ref: "v1.3.0", Version: "v1.3.0"},
+		{File: "ci.yml", Line: 10, Repository: "org/actions", Path: "test", Ref: "main"},
+	}, refs)
+}
+
+func TestRewriteWorkflow(t *testing.T) {
+	newSHA := "fedcba9876543210fedcba9876543210fedcba98"
+	out := RewriteWorkflow([]byte(testWorkflow), "org/actions", "v1.4.0", newSHA)
+
+	expected := `name: CI
+jobs:
+  build:
+    uses: org/workflows/.github/workflows/build.yml@v1.2.0
+  test:
+    steps:
+      - uses: actions/checkout@v4
+      - uses: org/actions/setup@` + newSHA + ` # v1.4.0
+      - uses: "org/actions/lint@v1.4.0"
+      - uses: org/actions/test@main
+`
+	require.Equal(t, expected, string(out))
+}
+
+func TestRewriteWorkflow_NoDowngrade(t *testing.T) {
+	out := RewriteWorkflow([]byte(testWorkflow), "org/workflows", "v1.1.0", testSHA)
+	require.Equal(t, testWorkflow, string(out))
+}
@@ -9,3 +9,41 @@ const DepSyncCommitPrefix = "chores(depsync):"
 func FormatCommitMessage(modulePath, targetVersion string) string {
 	return fmt.Sprintf("%s update %s to %s", DepSyncCommitPrefix, modulePath, targetVersion)
 }
+
+// FormatFileSyncCommitMessage formats a commit message for synchronized files.
+func FormatFileSyncCommitMessage(path string) string {
+	return fmt.Sprintf("%s sync %s", DepSyncCommitPrefix, path)
+}
+
+// FormatActionsCommitMessage formats a commit message for GitHub Actions and reusable workflows updates.
+func FormatActionsCommitMessage(repository, targetVersion string) string {
+	return fmt.Sprintf("%s update %s actions to %s", DepSyncCommitPrefix, repository, targetVersion)
+}
+
+// FormatDockerfilesCommitMessage formats a commit message for Dockerfiles base images updates.
+func FormatDockerfilesCommitMessage() string {
+	return fmt.Sprintf("%s align Dockerfiles base images", DepSyncCommitPrefix)
+}
+
+// FormatToolsCommitMessage formats a commit message for tools updates.
+func FormatToolsCommitMessage() string {
+	return fmt.Sprintf("%s align tools versions", DepSyncCommitPrefix)
+}
+
+// FormatGroupCommitMessage formats a commit message for batched dependency updates.
+func FormatGroupCommitMessage(group string, count int) string {
+	return fmt.Sprintf("%s update %d %s dependencies", DepSyncCommitPrefix, count, group)
+}
+
+// FormatMergeRequestDescription formats the description of a merge request for dependency updates.
+func FormatMergeRequestDescription(modulePath, targetVersion string) string {
+	return fmt.Sprintf(`## Dependency Update
+
+This merge request updates the dependency **%s** to version **%s**.
+
+### Changes
+- Updated dependency: `+"`%s`"+`
+- New version: `+"`%s`"+`
+
+This update was automatically generated by DepSync.`, modulePath, targetVersion, modulePath, targetVersion)
+}
@@ -2,68 +2,178 @@ package dagger
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"go/version"
+	"os"
 	"strings"
-	"time"
 
 	"dagger.io/dagger"
 	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/git"
+	"github.com/cryptellation/depsync/pkg/gomod"
 	"github.com/cryptellation/depsync/pkg/logging"
 	"go.uber.org/zap"
 )
 
+// DefaultGoVersion is the Go version used when the repository one is unknown.
+const DefaultGoVersion = "1.24"
+
+// Workspace is the working copy of a repository, returned by CloneRepo and updated by the
+// other operations. Its implementation depends on the runner that created it, such as a
+// Dagger directory, and it is only passed back to this runner. A workspace is reused for
+// several updates of its repository with ResetRepo, until removed with RemoveRepo.
+type Workspace interface {
+	// RepoURL returns the URL of the repository of the working copy.
+	RepoURL() string
+}
+
+// directoryWorkspace is the workspace of the Dagger runner: a directory loaded in Dagger.
+type directoryWorkspace struct {
+	repoURL string
+	dir     *dagger.Directory
+}
+
+// RepoURL implements the Workspace interface.
+func (w *directoryWorkspace) RepoURL() string {
+	return w.repoURL
+}
+
+// VerificationError is returned by the updates when a verification command fails, with its
+// output. The update must not be pushed.
+type VerificationError struct {
+	Command string
+	Output  string
+}
+
+// Error implements the error interface.
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("verification command %q failed", e.Command)
+}
+
 // UpdateGoDependencyParams contains parameters for UpdateGoDependency.
 type UpdateGoDependencyParams struct {
-	Dir           *dagger.Directory
+	Dir           Workspace
 	ModulePath    string
 	TargetVersion string
+	// GoVersion is the Go version of the service, selecting the Go image of the update when
+	// newer than DefaultGoVersion.
+	GoVersion string
+	// Verify are the commands verifying the update, run with "sh -c" after it.
+	Verify []string
+}
+
+// Module is a module at a given version.
+type Module struct {
+	Path    string
+	Version string
+}
+
+// UpdateGoDependenciesParams contains parameters for UpdateGoDependencies.
+type UpdateGoDependenciesParams struct {
+	Dir     Workspace
+	Modules []Module
+	// GoVersion is the Go version of the service, selecting the Go image of the update when
+	// newer than DefaultGoVersion.
+	GoVersion string
+	// Verify are the commands verifying the update, run with "sh -c" after it.
+	Verify []string
+}
+
+// UpdateGoToolsParams contains parameters for UpdateGoTools.
+type UpdateGoToolsParams struct {
+	Dir   Workspace
+	Tools []Module
+	// GoVersion is the Go version of the service, selecting the Go image of the update when
+	// newer than DefaultGoVersion.
+	GoVersion string
+	// Generate runs "go generate ./..." after the update, to regenerate the code with the new tools.
+	Generate bool
+	// Verify are the commands verifying the update, run with "sh -c" after it.
+	Verify []string
 }
 
 // CheckBranchExistsParams contains parameters for CheckBranchExists.
 type CheckBranchExistsParams struct {
-	Dir        *dagger.Directory
+	Dir        Workspace
 	BranchName string
 	RepoURL    string
 }
 
 // CommitAndPushParams contains parameters for CommitAndPush.
 type CommitAndPushParams struct {
-	Dir           *dagger.Directory
+	Dir           Workspace
 	BranchName    string
 	ModulePath    string
 	TargetVersion string
 	AuthorName    string
 	AuthorEmail   string
 	RepoURL       string
+	// CommitMessage overrides the generated dependency update message when set.
+	CommitMessage string
+}
+
+// File is a file to write in a directory.
+type File struct {
+	Path       string
+	Content    []byte
+	Executable bool
+}
+
+// WriteFilesParams contains parameters for WriteFiles.
+type WriteFilesParams struct {
+	Dir   Workspace
+	Files []File
 }
 
 // Dagger defines the interface for Dagger operations.
 //
 //go:generate go run go.uber.org/mock/mockgen@v0.5.2 -destination=mock_dagger.gen.go -package=dagger . Dagger
 type Dagger interface {
-	CloneRepo(ctx context.Context, repoURL, branch string) (*dagger.Directory, error)
-	UpdateGoDependency(ctx context.Context, params UpdateGoDependencyParams) (*dagger.Directory, error)
+	CloneRepo(ctx context.Context, repoURL, branch string) (Workspace, error)
+	ResetRepo(ctx context.Context, dir Workspace) (Workspace, error)
+	RemoveRepo(ctx context.Context, dir Workspace) error
+	UpdateGoDependency(ctx context.Context, params UpdateGoDependencyParams) (Workspace, error)
+	UpdateGoDependencies(ctx context.Context, params UpdateGoDependenciesParams) (Workspace, error)
+	UpdateGoTools(ctx context.Context, params UpdateGoToolsParams) (Workspace, error)
 	CheckBranchExists(ctx context.Context, params CheckBranchExistsParams) (bool, error)
 	CommitAndPush(ctx context.Context, params CommitAndPushParams) (string, error)
+	WriteFiles(ctx context.Context, params WriteFilesParams) (Workspace, error)
+	ResolveImageDigest(ctx context.Context, image string) (string, error)
 	Close() error
 }
 
+// Options contains the options of the Dagger adapter.
+type Options struct {
+	// HostTokens are the tokens of the git hosts other than github.com, by host.
+	// The GitHub token is used for the hosts without token.
+	HostTokens map[string]string
+	// TokenSource provides the GitHub tokens instead of the static token, such as
+	// the installation tokens of a GitHub App.
+	TokenSource adapters.TokenSource
+}
+
 // daggerAdapter implements the Dagger interface.
 type daggerAdapter struct {
-	client      *dagger.Client
-	githubToken string
+	client *dagger.Client
+	creds  git.Credentials
 }
 
 // NewDagger returns a new instance implementing the Dagger interface.
 func NewDagger(ctx context.Context, githubToken string) (Dagger, error) {
+	return NewDaggerWithOptions(ctx, githubToken, Options{})
+}
+
+// NewDaggerWithOptions returns a new instance implementing the Dagger interface, with the given options.
+func NewDaggerWithOptions(ctx context.Context, githubToken string, opts Options) (Dagger, error) {
 	client, err := dagger.Connect(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	return &daggerAdapter{
-		client:      client,
-		githubToken: githubToken,
+		client: client,
+		creds:  git.NewCredentials(githubToken, opts.HostTokens, opts.TokenSource),
 	}, nil
 }
 
@@ -75,55 +185,75 @@ func (d *daggerAdapter) Close() error {
 	return nil
 }
 
-// CloneRepo clones the given repo URL at the given branch using Dagger and returns the cloned directory.
-func (d *daggerAdapter) CloneRepo(ctx context.Context, repoURL, branch string) (*dagger.Directory, error) {
+// CloneRepo clones the given repo URL at the given branch and returns the cloned directory,
+// loaded in Dagger.
+func (d *daggerAdapter) CloneRepo(ctx context.Context, repoURL, branch string) (Workspace, error) {
 	logger := logging.C(ctx)
 	logger.Info("Cloning repository", zap.String("repo_url", repoURL), zap.String("branch", branch))
 
-	// Set up the token as a Dagger secret
-	secret := d.client.SetSecret("github_token", d.githubToken)
-
-	// Use a container to perform the git clone
-	container := d.client.Container().From("alpine/git").
-		WithSecretVariable("GITHUB_TOKEN", secret).
-		WithExec([]string{"sh", "-c",
-			fmt.Sprintf(
-				"git clone --depth=1 --branch %s https://$GITHUB_TOKEN@%s /repo", branch, repoURL[8:], // strip https://
-			),
-		})
-
-	// Ensure we're on main and pull latest changes
-	// This handles the case where Dagger reuses an old cached directory
-	container = container.WithExec([]string{"sh", "-c", "cd /repo && git checkout main"})
-	// Add a cache buster to ensure git pull is always executed
-	container = container.WithExec([]string{"sh", "-c",
-		fmt.Sprintf("cd /repo && git pull origin main && echo 'Pull completed at: %d'", time.Now().Unix())})
-
-	dir := container.Directory("/repo")
+	remote, err := d.creds.Remote(repoURL)
+	if err != nil {
+		return nil, err
+	}
 
-	// Check if the directory exists by listing files (fail fast)
-	entries, err := dir.Entries(ctx)
+	// Clone on the host, as the clone is loaded in Dagger before being removed
+	tmp, err := os.MkdirTemp("", "depsync-")
 	if err != nil {
+		return nil, fmt.Errorf("failed to create clone directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	if err := git.Clone(ctx, tmp, remote, branch); err != nil {
 		logger.Error("Failed to clone repository", zap.Error(err))
 		return nil, fmt.Errorf("failed to clone repository: %w", err)
 	}
-	logger.Info("Repository cloned and updated", zap.Strings("files", entries))
+
+	// Load the clone in Dagger (fail fast)
+	dir, err := d.client.Host().Directory(tmp).Sync(ctx)
+	if err != nil {
+		logger.Error("Failed to load repository", zap.Error(err))
+		return nil, fmt.Errorf("failed to load repository: %w", err)
+	}
+	logger.Info("Repository cloned", zap.String("repo_url", repoURL))
+	return &directoryWorkspace{repoURL: repoURL, dir: dir}, nil
+}
+
+// ResetRepo returns the directory as cloned, as Dagger directories are not modified by the
+// operations, which return new ones.
+func (d *daggerAdapter) ResetRepo(_ context.Context, dir Workspace) (Workspace, error) {
 	return dir, nil
 }
 
+// RemoveRepo does nothing, as Dagger directories are released with the client.
+func (d *daggerAdapter) RemoveRepo(_ context.Context, _ Workspace) error {
+	return nil
+}
+
 // UpdateGoDependency updates a Go dependency in the given directory to the specified version.
 func (d *daggerAdapter) UpdateGoDependency(ctx context.Context, params UpdateGoDependencyParams) (
-	*dagger.Directory, error) {
+	Workspace, error) {
 	logger := logging.C(ctx)
 	logger.Info("Updating Go dependency",
 		zap.String("module_path", params.ModulePath),
 		zap.String("target_version", params.TargetVersion))
 
+	dir, err := directory(params.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	vendor, err := vendored(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
 	// Use a Go container to perform the dependency update
-	container := d.client.Container().From("golang:1.24-alpine").
-		WithMountedDirectory("/repo", params.Dir).
-		WithWorkdir("/repo").
+	container := d.goContainer(params.GoVersion, dir).
 		WithExec([]string{"go", "get", fmt.Sprintf("%s@%s", params.ModulePath, params.TargetVersion)})
+	if vendor {
+		container = container.
+			WithExec([]string{"go", "mod", "tidy"}).
+			WithExec([]string{"go", "mod", "vendor"})
+	}
 
 	// Get the updated directory
 	updatedDir := container.Directory("/repo")
@@ -141,10 +271,238 @@ func (d *daggerAdapter) UpdateGoDependency(ctx context.Context, params UpdateGoD
 		return nil, fmt.Errorf("go.mod file not found after dependency update")
 	}
 
+	// Verify the update in the same container
+	if verifiedDir, err := verify(ctx, container, params.Verify); err != nil {
+		logger.Error("Failed to verify dependency update", zap.Error(err))
+		return nil, err
+	} else if verifiedDir != nil {
+		updatedDir = verifiedDir
+	}
+
 	logger.Info("Dependency updated successfully",
 		zap.String("module_path", params.ModulePath),
 		zap.String("target_version", params.TargetVersion))
-	return updatedDir, nil
+	return withDirectory(params.Dir, updatedDir), nil
+}
+
+// UpdateGoDependencies updates several Go dependencies in the given directory with a single "go get".
+func (d *daggerAdapter) UpdateGoDependencies(ctx context.Context, params UpdateGoDependenciesParams) (
+	Workspace, error) {
+	logger := logging.C(ctx)
+
+	args := []string{"go", "get"}
+	for _, module := range params.Modules {
+		logger.Info("Updating Go dependency",
+			zap.String("module_path", module.Path),
+			zap.String("target_version", module.Version))
+		args = append(args, fmt.Sprintf("%s@%s", module.Path, module.Version))
+	}
+
+	dir, err := directory(params.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	vendor, err := vendored(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use a Go container to perform the dependencies update
+	container := d.goContainer(params.GoVersion, dir).
+		WithExec(args)
+	if vendor {
+		container = container.
+			WithExec([]string{"go", "mod", "tidy"}).
+			WithExec([]string{"go", "mod", "vendor"})
+	}
+
+	// Force evaluation to fail fast
+	updatedDir, err := container.Directory("/repo").Sync(ctx)
+	if err != nil {
+		logger.Error("Failed to update dependencies", zap.Error(err))
+		return nil, fmt.Errorf("failed to update dependencies: %w", err)
+	}
+
+	// Verify the update in the same container
+	if verifiedDir, err := verify(ctx, container, params.Verify); err != nil {
+		logger.Error("Failed to verify dependencies update", zap.Error(err))
+		return nil, err
+	} else if verifiedDir != nil {
+		updatedDir = verifiedDir
+	}
+
+	logger.Info("Dependencies updated successfully", zap.Int("dependency_count", len(params.Modules)))
+	return withDirectory(params.Dir, updatedDir), nil
+}
+
+// UpdateGoTools updates the tool modules in the given directory to the specified versions
+// and optionally regenerates the code with them.
+func (d *daggerAdapter) UpdateGoTools(ctx context.Context, params UpdateGoToolsParams) (Workspace, error) {
+	logger := logging.C(ctx)
+
+	args := []string{"go", "get"}
+	for _, tool := range params.Tools {
+		logger.Info("Updating Go tool",
+			zap.String("module_path", tool.Path),
+			zap.String("target_version", tool.Version))
+		args = append(args, fmt.Sprintf("%s@%s", tool.Path, tool.Version))
+	}
+
+	dir, err := directory(params.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	vendor, err := vendored(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use a Go container to perform the tools update
+	container := d.goContainer(params.GoVersion, dir).
+		WithExec(args).
+		WithExec([]string{"go", "mod", "tidy"})
+	if vendor {
+		container = container.WithExec([]string{"go", "mod", "vendor"})
+	}
+	if params.Generate {
+		container = container.WithExec([]string{"go", "generate", "./..."})
+	}
+
+	// Force evaluation to fail fast
+	updatedDir, err := container.Directory("/repo").Sync(ctx)
+	if err != nil {
+		logger.Error("Failed to update tools", zap.Error(err))
+		return nil, fmt.Errorf("failed to update tools: %w", err)
+	}
+
+	// Verify the update in the same container
+	if verifiedDir, err := verify(ctx, container, params.Verify); err != nil {
+		logger.Error("Failed to verify tools update", zap.Error(err))
+		return nil, err
+	} else if verifiedDir != nil {
+		updatedDir = verifiedDir
+	}
+
+	logger.Info("Tools updated successfully", zap.Int("tool_count", len(params.Tools)))
+	return withDirectory(params.Dir, updatedDir), nil
+}
+
+// Paths of the Go caches in the update containers.
+const (
+	goModCachePath   = "/go/pkg/mod"
+	goBuildCachePath = "/root/.cache/go-build"
+)
+
+// goContainer returns a Go container working in the directory. The module downloads and the
+// build cache persist in cache volumes of the Dagger engine, shared by the updates with the
+// same Go version.
+func (d *daggerAdapter) goContainer(goVersion string, dir *dagger.Directory) *dagger.Container {
+	modCache, buildCache := goCacheVolumes(goVersion)
+	return d.client.Container().From(goImage(goVersion)).
+		WithEnvVariable("GOMODCACHE", goModCachePath).
+		WithEnvVariable("GOCACHE", goBuildCachePath).
+		WithMountedCache(goModCachePath, d.client.CacheVolume(modCache)).
+		WithMountedCache(goBuildCachePath, d.client.CacheVolume(buildCache)).
+		WithMountedDirectory("/repo", dir).
+		WithWorkdir("/repo")
+}
+
+// goCacheVolumes returns the names of the module and build cache volumes for the given Go
+// version. The caches are not shared across Go versions, whose build outputs differ.
+func goCacheVolumes(goVersion string) (modCache, buildCache string) {
+	goVersion = imageGoVersion(goVersion)
+	return "depsync-gomod-" + goVersion, "depsync-gocache-" + goVersion
+}
+
+// vendored checks if the repository commits its vendor directory, which must then be updated
+// with "go mod tidy" and "go mod vendor" after "go get", as it leaves vendor/modules.txt inconsistent.
+func vendored(ctx context.Context, dir *dagger.Directory) (bool, error) {
+	matches, err := dir.Glob(ctx, gomod.VendorModulesFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to detect vendor directory: %w", err)
+	}
+	if len(matches) == 0 {
+		return false, nil
+	}
+	logging.C(ctx).Info("Vendor directory detected, updating it with go mod vendor")
+	return true, nil
+}
+
+// verify runs the verification commands in the container, after the update, and returns the
+// directory once verified, as the commands may change it (e.g. "go mod tidy"). It returns nil
+// without command, and a VerificationError when a command fails.
+func verify(ctx context.Context, container *dagger.Container, commands []string) (*dagger.Directory, error) {
+	if len(commands) == 0 {
+		return nil, nil
+	}
+
+	for _, command := range commands {
+		logging.C(ctx).Info("Verifying update", zap.String("command", command))
+		next, err := container.WithExec([]string{"sh", "-c", command}).Sync(ctx)
+		var execErr *dagger.ExecError
+		if errors.As(err, &execErr) {
+			return nil, &VerificationError{Command: command, Output: execErr.Stdout + execErr.Stderr}
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to verify update: %w", err)
+		}
+		container = next
+	}
+
+	dir, err := container.Directory("/repo").Sync(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify update: %w", err)
+	}
+	return dir, nil
+}
+
+// imageGoVersion returns the Go version of the update image for the Go version of a service:
+// DefaultGoVersion, unless the service requires a newer version. The images of older versions
+// do not understand the directives of recent go.mod files.
+func imageGoVersion(goVersion string) string {
+	if goVersion == "" || version.Compare("go"+goVersion, "go"+DefaultGoVersion) < 0 {
+		return DefaultGoVersion
+	}
+	return goVersion
+}
+
+// goImage returns the Go image for the given Go version of a service.
+func goImage(goVersion string) string {
+	return fmt.Sprintf("golang:%s-alpine", imageGoVersion(goVersion))
+}
+
+// ResolveImageDigest resolves the digest of an image reference (e.g. golang:1.24-alpine)
+// and returns it (e.g. sha256:...).
+func (d *daggerAdapter) ResolveImageDigest(ctx context.Context, image string) (string, error) {
+	logger := logging.C(ctx)
+	logger.Info("Resolving image digest", zap.String("image", image))
+
+	ref, err := d.client.Container().From(image).ImageRef(ctx)
+	if err != nil {
+		logger.Error("Failed to resolve image digest", zap.String("image", image), zap.Error(err))
+		return "", fmt.Errorf("failed to resolve image digest for %s: %w", image, err)
+	}
+
+	_, digest, found := strings.Cut(ref, "@")
+	if !found {
+		return "", fmt.Errorf("no digest in image reference %q", ref)
+	}
+	return digest, nil
+}
+
+// directory returns the Dagger directory of a workspace.
+func directory(ws Workspace) (*dagger.Directory, error) {
+	w, ok := ws.(*directoryWorkspace)
+	if !ok || w == nil {
+		return nil, fmt.Errorf("unsupported workspace: %T", ws)
+	}
+	return w.dir, nil
+}
+
+// withDirectory returns the workspace of the repository of ws, updated to the directory.
+func withDirectory(ws Workspace, dir *dagger.Directory) Workspace {
+	return &directoryWorkspace{repoURL: ws.RepoURL(), dir: dir}
 }
 
 // contains checks if a slice contains a specific string.
@@ -157,6 +515,36 @@ func contains(slice []string, item string) bool {
 	return false
 }
 
+// WriteFiles writes the given files in the directory, replacing existing ones.
+func (d *daggerAdapter) WriteFiles(ctx context.Context, params WriteFilesParams) (Workspace, error) {
+	logger := logging.C(ctx)
+
+	dir, err := directory(params.Dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range params.Files {
+		logger.Info("Writing file", zap.String("path", f.Path), zap.Int("size", len(f.Content)))
+
+		permissions := 0o644
+		if f.Executable {
+			permissions = 0o755
+		}
+		dir = dir.WithNewFile(f.Path, string(f.Content), dagger.DirectoryWithNewFileOpts{
+			Permissions: permissions,
+		})
+	}
+
+	// Force evaluation to fail fast
+	updatedDir, err := dir.Sync(ctx)
+	if err != nil {
+		logger.Error("Failed to write files", zap.Error(err))
+		return nil, fmt.Errorf("failed to write files: %w", err)
+	}
+
+	return withDirectory(params.Dir, updatedDir), nil
+}
+
 // CheckBranchExists checks if a branch already exists in the remote repository.
 func (d *daggerAdapter) CheckBranchExists(ctx context.Context, params CheckBranchExistsParams) (bool, error) {
 	logger := logging.C(ctx)
@@ -164,37 +552,16 @@ func (d *daggerAdapter) CheckBranchExists(ctx context.Context, params CheckBranc
 		zap.String("branch_name", params.BranchName),
 		zap.String("repo_url", params.RepoURL))
 
-	// Set up the token as a Dagger secret
-	secret := d.client.SetSecret("github_token", d.githubToken)
-
-	// Use a container to perform the git ls-remote operation
-	container := d.client.Container().From("alpine/git").
-		WithSecretVariable("GITHUB_TOKEN", secret).
-		WithMountedDirectory("/repo", params.Dir).
-		WithWorkdir("/repo")
-
-	// Set up remote with authentication (same as in CommitAndPush)
-	owner, repo := extractOwnerAndRepoFromURL(params.RepoURL)
-	container = container.WithExec([]string{"sh", "-c",
-		fmt.Sprintf("git remote set-url origin https://$GITHUB_TOKEN@github.com/%s/%s.git",
-			owner, repo)})
-
-	// Add cache-busting parameter to prevent Dagger from caching the git ls-remote result
-	// This ensures we get fresh results each time, even if the operation signature is the same
-	cacheBuster := fmt.Sprintf("check_%d", time.Now().UnixNano())
-	container = container.WithEnvVariable("CACHE_BUSTER", cacheBuster)
-
-	// Perform the git ls-remote operation
-	lsRemoteOutput, err := container.WithExec([]string{"sh", "-c",
-		fmt.Sprintf("git ls-remote --heads origin %s", params.BranchName)}).Stdout(ctx)
+	remote, err := d.creds.Remote(params.RepoURL)
+	if err != nil {
+		return false, err
+	}
+	branchExists, err := git.BranchExists(ctx, remote, params.BranchName)
 	if err != nil {
 		logger.Error("Failed to check branch existence", zap.Error(err))
 		return false, fmt.Errorf("failed to check branch existence: %w", err)
 	}
 
-	// Check if the output is empty (branch doesn't exist) or non-empty (branch exists)
-	branchExists := strings.TrimSpace(lsRemoteOutput) != ""
-
 	if branchExists {
 		logger.Warn("Branch already exists, skipping dependency update",
 			zap.String("branch_name", params.BranchName),
@@ -214,42 +581,40 @@ func (d *daggerAdapter) CommitAndPush(ctx context.Context, params CommitAndPushP
 		zap.String("module_path", params.ModulePath),
 		zap.String("branch_name", params.BranchName))
 
-	// Format the commit message
-	commitMessage := adapters.FormatCommitMessage(params.ModulePath, params.TargetVersion)
-
-	// Set up the token as a Dagger secret
-	secret := d.client.SetSecret("github_token", d.githubToken)
-
-	// Use a container to perform the git operations
-	container := d.client.Container().From("alpine/git").
-		WithSecretVariable("GITHUB_TOKEN", secret).
-		WithMountedDirectory("/repo", params.Dir).
-		WithWorkdir("/repo")
-
-	// Add cache-busting parameter to prevent Dagger from caching the git operations
-	// This ensures we get fresh results each time, even if the operation signature is the same
-	cacheBuster := fmt.Sprintf("commit_%d", time.Now().UnixNano())
-	container = container.WithEnvVariable("CACHE_BUSTER", cacheBuster)
-
-	// Configure git user
-	container = container.WithExec([]string{"git", "config", "user.name", params.AuthorName})
-	container = container.WithExec([]string{"git", "config", "user.email", params.AuthorEmail})
-
-	// Add and commit changes
-	container = container.WithExec([]string{"git", "add", "."})
-	container = container.WithExec([]string{"git", "commit", "-m", commitMessage})
+	// Format the commit message, unless provided
+	commitMessage := params.CommitMessage
+	if commitMessage == "" {
+		commitMessage = adapters.FormatCommitMessage(params.ModulePath, params.TargetVersion)
+	}
 
-	// Create and checkout new branch
-	container = container.WithExec([]string{"git", "checkout", "-b", params.BranchName})
+	dir, err := directory(params.Dir)
+	if err != nil {
+		return "", err
+	}
+	remote, err := d.creds.Remote(params.RepoURL)
+	if err != nil {
+		return "", err
+	}
 
-	// Set up remote with authentication and push
-	owner, repo := extractOwnerAndRepoFromURL(params.RepoURL)
-	container = container.WithExec([]string{"sh", "-c",
-		fmt.Sprintf("git remote set-url origin https://$GITHUB_TOKEN@github.com/%s/%s.git",
-			owner, repo)})
+	// Export the updated directory to the host, with its git metadata, to commit it
+	tmp, err := os.MkdirTemp("", "depsync-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create commit directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	if _, err := dir.Export(ctx, tmp); err != nil {
+		logger.Error("Failed to export directory", zap.Error(err))
+		return "", fmt.Errorf("failed to export directory: %w", err)
+	}
 
-	// Push the branch
-	_, err := container.WithExec([]string{"git", "push", "-u", "origin", params.BranchName}).Sync(ctx)
+	err = git.CommitAndPush(ctx, git.CommitAndPushParams{
+		Dir:         tmp,
+		Remote:      remote,
+		BranchName:  params.BranchName,
+		Message:     commitMessage,
+		AuthorName:  params.AuthorName,
+		AuthorEmail: params.AuthorEmail,
+	})
 	if err != nil {
 		logger.Error("Failed to push branch", zap.Error(err))
 		return "", fmt.Errorf("failed to push branch: %w", err)
@@ -260,17 +625,3 @@ func (d *daggerAdapter) CommitAndPush(ctx context.Context, params CommitAndPushP
 		zap.String("commit_message", commitMessage))
 	return params.BranchName, nil
 }
-
-// extractOwnerAndRepoFromURL extracts owner and repo from a GitHub URL like "https://github.com/owner/repo.git"
-func extractOwnerAndRepoFromURL(repoURL string) (string, string) {
-	// Remove https:// prefix and .git suffix
-	cleanURL := strings.TrimPrefix(repoURL, "https://")
-	cleanURL = strings.TrimSuffix(cleanURL, ".git")
-
-	// Split by / and extract owner and repo
-	parts := strings.Split(cleanURL, "/")
-	if len(parts) >= 3 {
-		return parts[1], parts[2]
-	}
-	return "", ""
-}
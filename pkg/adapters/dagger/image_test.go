@@ -0,0 +1,23 @@
+//go:build unit
+// +build unit
+
+package dagger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoImage(t *testing.T) {
+	// The services requiring an older Go version are updated with the default image
+	assert.Equal(t, "golang:"+DefaultGoVersion+"-alpine", goImage(""))
+	assert.Equal(t, "golang:"+DefaultGoVersion+"-alpine", goImage("1.13"))
+	assert.Equal(t, "golang:"+DefaultGoVersion+"-alpine", goImage("1.21.5"))
+
+	// The newer versions are kept
+	assert.Equal(t, "golang:1.25.1-alpine", goImage("1.25.1"))
+	modCache, buildCache := goCacheVolumes("1.13")
+	assert.Equal(t, "depsync-gomod-"+DefaultGoVersion, modCache)
+	assert.Equal(t, "depsync-gocache-"+DefaultGoVersion, buildCache)
+}
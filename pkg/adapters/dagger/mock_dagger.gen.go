@@ -13,7 +13,6 @@ import (
 	context "context"
 	reflect "reflect"
 
-	dagger "dagger.io/dagger"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -57,10 +56,10 @@ func (mr *MockDaggerMockRecorder) CheckBranchExists(ctx, params any) *gomock.Cal
 }
 
 // CloneRepo mocks base method.
-func (m *MockDagger) CloneRepo(ctx context.Context, repoURL, branch string) (*dagger.Directory, error) {
+func (m *MockDagger) CloneRepo(ctx context.Context, repoURL, branch string) (Workspace, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CloneRepo", ctx, repoURL, branch)
-	ret0, _ := ret[0].(*dagger.Directory)
+	ret0, _ := ret[0].(Workspace)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -100,11 +99,70 @@ func (mr *MockDaggerMockRecorder) CommitAndPush(ctx, params any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitAndPush", reflect.TypeOf((*MockDagger)(nil).CommitAndPush), ctx, params)
 }
 
+// RemoveRepo mocks base method.
+func (m *MockDagger) RemoveRepo(ctx context.Context, dir Workspace) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveRepo", ctx, dir)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveRepo indicates an expected call of RemoveRepo.
+func (mr *MockDaggerMockRecorder) RemoveRepo(ctx, dir any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRepo", reflect.TypeOf((*MockDagger)(nil).RemoveRepo), ctx, dir)
+}
+
+// ResetRepo mocks base method.
+func (m *MockDagger) ResetRepo(ctx context.Context, dir Workspace) (Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetRepo", ctx, dir)
+	ret0, _ := ret[0].(Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetRepo indicates an expected call of ResetRepo.
+func (mr *MockDaggerMockRecorder) ResetRepo(ctx, dir any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetRepo", reflect.TypeOf((*MockDagger)(nil).ResetRepo), ctx, dir)
+}
+
+// ResolveImageDigest mocks base method.
+func (m *MockDagger) ResolveImageDigest(ctx context.Context, image string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveImageDigest", ctx, image)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveImageDigest indicates an expected call of ResolveImageDigest.
+func (mr *MockDaggerMockRecorder) ResolveImageDigest(ctx, image any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveImageDigest", reflect.TypeOf((*MockDagger)(nil).ResolveImageDigest), ctx, image)
+}
+
+// UpdateGoDependencies mocks base method.
+func (m *MockDagger) UpdateGoDependencies(ctx context.Context, params UpdateGoDependenciesParams) (Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateGoDependencies", ctx, params)
+	ret0, _ := ret[0].(Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateGoDependencies indicates an expected call of UpdateGoDependencies.
+func (mr *MockDaggerMockRecorder) UpdateGoDependencies(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGoDependencies", reflect.TypeOf((*MockDagger)(nil).UpdateGoDependencies), ctx, params)
+}
+
 // UpdateGoDependency mocks base method.
-func (m *MockDagger) UpdateGoDependency(ctx context.Context, params UpdateGoDependencyParams) (*dagger.Directory, error) {
+func (m *MockDagger) UpdateGoDependency(ctx context.Context, params UpdateGoDependencyParams) (Workspace, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateGoDependency", ctx, params)
-	ret0, _ := ret[0].(*dagger.Directory)
+	ret0, _ := ret[0].(Workspace)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -114,3 +172,33 @@ func (mr *MockDaggerMockRecorder) UpdateGoDependency(ctx, params any) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGoDependency", reflect.TypeOf((*MockDagger)(nil).UpdateGoDependency), ctx, params)
 }
+
+// UpdateGoTools mocks base method.
+func (m *MockDagger) UpdateGoTools(ctx context.Context, params UpdateGoToolsParams) (Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateGoTools", ctx, params)
+	ret0, _ := ret[0].(Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateGoTools indicates an expected call of UpdateGoTools.
+func (mr *MockDaggerMockRecorder) UpdateGoTools(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGoTools", reflect.TypeOf((*MockDagger)(nil).UpdateGoTools), ctx, params)
+}
+
+// WriteFiles mocks base method.
+func (m *MockDagger) WriteFiles(ctx context.Context, params WriteFilesParams) (Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteFiles", ctx, params)
+	ret0, _ := ret[0].(Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteFiles indicates an expected call of WriteFiles.
+func (mr *MockDaggerMockRecorder) WriteFiles(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteFiles", reflect.TypeOf((*MockDagger)(nil).WriteFiles), ctx, params)
+}
@@ -0,0 +1,192 @@
+//go:generate go run go.uber.org/mock/mockgen@v0.5.2 -source=client.go -destination=mock.gen.go -package=forge
+package forge
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrFileNotFound is returned when the requested file does not exist in the repository.
+var ErrFileNotFound = errors.New("file not found")
+
+// GetFileContentParams contains parameters for GetFileContent.
+type GetFileContentParams struct {
+	// Host is the host of the repository (default: github.com).
+	Host  string
+	Owner string
+	Repo  string
+	Path  string
+	Ref   string
+}
+
+// ListDirectoryParams contains parameters for ListDirectory.
+type ListDirectoryParams struct {
+	// Host is the host of the repository (default: github.com).
+	Host  string
+	Owner string
+	Repo  string
+	Path  string
+	Ref   string
+}
+
+// ListTagsParams contains parameters for ListTags.
+type ListTagsParams struct {
+	// Host is the host of the repository (default: github.com).
+	Host  string
+	Owner string
+	Repo  string
+}
+
+// Tag is a tag of a repository.
+type Tag struct {
+	Name string
+	// CommitSHA is the SHA of the commit of the tag.
+	CommitSHA string
+}
+
+// CreateMergeRequestParams contains parameters for CreateMergeRequest.
+type CreateMergeRequestParams struct {
+	RepoURL       string
+	SourceBranch  string
+	ModulePath    string
+	TargetVersion string
+	// Title and Description override the generated dependency update ones when set.
+	Title       string
+	Description string
+}
+
+// CheckPullRequestExistsParams contains parameters for CheckPullRequestExists.
+type CheckPullRequestExistsParams struct {
+	RepoURL      string
+	SourceBranch string
+}
+
+// GetPullRequestChecksParams contains parameters for GetPullRequestChecks.
+type GetPullRequestChecksParams struct {
+	RepoURL  string
+	PRNumber int
+}
+
+// MergeMergeRequestParams contains parameters for MergeMergeRequest.
+type MergeMergeRequestParams struct {
+	RepoURL       string
+	PRNumber      int
+	ModulePath    string
+	TargetVersion string
+}
+
+// DeleteBranchParams contains parameters for DeleteBranch.
+type DeleteBranchParams struct {
+	RepoURL    string
+	BranchName string
+}
+
+// DeletePullRequestParams contains parameters for DeletePullRequest.
+type DeletePullRequestParams struct {
+	RepoURL  string
+	PRNumber int
+}
+
+// CheckMergeConflictsParams contains parameters for CheckMergeConflicts.
+type CheckMergeConflictsParams struct {
+	RepoURL  string
+	PRNumber int
+}
+
+// BranchExistsParams contains parameters for BranchExists.
+type BranchExistsParams struct {
+	RepoURL    string
+	BranchName string
+}
+
+// GetBranchSHAParams contains parameters for GetBranchSHA.
+type GetBranchSHAParams struct {
+	RepoURL    string
+	BranchName string
+}
+
+// GetPullRequestStateParams contains parameters for GetPullRequestState.
+type GetPullRequestStateParams struct {
+	RepoURL  string
+	PRNumber int
+}
+
+// CommitFile is a file written by CreateCommit.
+type CommitFile struct {
+	Path    string
+	Content []byte
+}
+
+// CreateCommitParams contains parameters for CreateCommit.
+type CreateCommitParams struct {
+	RepoURL string
+	// BaseBranch is the branch the new branch is created from.
+	BaseBranch string
+	// BaseSHA is the commit of the base branch the files were read at, so that the commit
+	// does not revert the changes made on the base branch since then.
+	BaseSHA string
+	// BranchName is the new branch created on the commit.
+	BranchName  string
+	Message     string
+	Files       []CommitFile
+	AuthorName  string
+	AuthorEmail string
+}
+
+// DiscoverRepositoriesParams contains parameters for DiscoverRepositories.
+type DiscoverRepositoriesParams struct {
+	RepoURLs []string
+	// Ref is the branch the files are read from.
+	Ref   string
+	Paths []string
+}
+
+// DiscoveredRepository contains the files and tags of a repository.
+type DiscoveredRepository struct {
+	RepoURL string
+	// Files contains the content of the files, by path. Missing files are absent.
+	Files map[string][]byte
+	// Tags contains the most recent tags, by commit date.
+	Tags []Tag
+}
+
+// Pull request states returned by GetPullRequestState.
+const (
+	PullRequestStateOpen   = "open"
+	PullRequestStateClosed = "closed"
+	PullRequestStateMerged = "merged"
+)
+
+// CheckStatus represents the status of CI/CD checks for a pull request.
+type CheckStatus struct {
+	Status string // "running", "passed", "failed"
+}
+
+// Client is the interface of the forges hosting the repositories, implemented by the
+// adapter of each forge (GitHub, GitLab, Gitea, local filesystem). Pull requests are the
+// merge requests of the forges that name them so.
+type Client interface {
+	// GetFileContent returns the content of a file, or ErrFileNotFound.
+	GetFileContent(ctx context.Context, params GetFileContentParams) ([]byte, error)
+	// ListDirectory returns the paths of the files of a directory, none if it does not exist.
+	ListDirectory(ctx context.Context, params ListDirectoryParams) ([]string, error)
+	ListTags(ctx context.Context, params ListTagsParams) ([]Tag, error)
+	// CreateMergeRequest creates a pull request and returns its number.
+	CreateMergeRequest(ctx context.Context, params CreateMergeRequestParams) (int, error)
+	// CheckPullRequestExists returns the number of the open pull request of a branch, or -1.
+	CheckPullRequestExists(ctx context.Context, params CheckPullRequestExistsParams) (int, error)
+	BranchExists(ctx context.Context, params BranchExistsParams) (bool, error)
+	GetBranchSHA(ctx context.Context, params GetBranchSHAParams) (string, error)
+	// GetPullRequestState returns one of the PullRequestState constants.
+	GetPullRequestState(ctx context.Context, params GetPullRequestStateParams) (string, error)
+	GetPullRequestChecks(ctx context.Context, params GetPullRequestChecksParams) (*CheckStatus, error)
+	MergeMergeRequest(ctx context.Context, params MergeMergeRequestParams) error
+	DeleteBranch(ctx context.Context, params DeleteBranchParams) error
+	// DeletePullRequest closes a pull request.
+	DeletePullRequest(ctx context.Context, params DeletePullRequestParams) error
+	CheckMergeConflicts(ctx context.Context, params CheckMergeConflictsParams) (bool, error)
+	// DiscoverRepositories returns the files and tags of the repositories, in their order.
+	DiscoverRepositories(ctx context.Context, params DiscoverRepositoriesParams) ([]DiscoveredRepository, error)
+	// CreateCommit creates a commit on a new branch and returns its SHA.
+	CreateCommit(ctx context.Context, params CreateCommitParams) (string, error)
+}
@@ -3,17 +3,16 @@
 //
 // Generated by this command:
 //
-//	mockgen -source=client.go -destination=mock.gen.go -package=github
+//	mockgen -source=client.go -destination=mock.gen.go -package=forge
 //
 
-// Package github is a generated GoMock package.
-package github
+// Package forge is a generated GoMock package.
+package forge
 
 import (
 	context "context"
 	reflect "reflect"
 
-	github "github.com/google/go-github/v55/github"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,6 +40,21 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
+// BranchExists mocks base method.
+func (m *MockClient) BranchExists(ctx context.Context, params BranchExistsParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BranchExists", ctx, params)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BranchExists indicates an expected call of BranchExists.
+func (mr *MockClientMockRecorder) BranchExists(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BranchExists", reflect.TypeOf((*MockClient)(nil).BranchExists), ctx, params)
+}
+
 // CheckMergeConflicts mocks base method.
 func (m *MockClient) CheckMergeConflicts(ctx context.Context, params CheckMergeConflictsParams) (bool, error) {
 	m.ctrl.T.Helper()
@@ -71,6 +85,21 @@ func (mr *MockClientMockRecorder) CheckPullRequestExists(ctx, params any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckPullRequestExists", reflect.TypeOf((*MockClient)(nil).CheckPullRequestExists), ctx, params)
 }
 
+// CreateCommit mocks base method.
+func (m *MockClient) CreateCommit(ctx context.Context, params CreateCommitParams) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCommit", ctx, params)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCommit indicates an expected call of CreateCommit.
+func (mr *MockClientMockRecorder) CreateCommit(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCommit", reflect.TypeOf((*MockClient)(nil).CreateCommit), ctx, params)
+}
+
 // CreateMergeRequest mocks base method.
 func (m *MockClient) CreateMergeRequest(ctx context.Context, params CreateMergeRequestParams) (int, error) {
 	m.ctrl.T.Helper()
@@ -114,6 +143,36 @@ func (mr *MockClientMockRecorder) DeletePullRequest(ctx, params any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePullRequest", reflect.TypeOf((*MockClient)(nil).DeletePullRequest), ctx, params)
 }
 
+// DiscoverRepositories mocks base method.
+func (m *MockClient) DiscoverRepositories(ctx context.Context, params DiscoverRepositoriesParams) ([]DiscoveredRepository, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiscoverRepositories", ctx, params)
+	ret0, _ := ret[0].([]DiscoveredRepository)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiscoverRepositories indicates an expected call of DiscoverRepositories.
+func (mr *MockClientMockRecorder) DiscoverRepositories(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiscoverRepositories", reflect.TypeOf((*MockClient)(nil).DiscoverRepositories), ctx, params)
+}
+
+// GetBranchSHA mocks base method.
+func (m *MockClient) GetBranchSHA(ctx context.Context, params GetBranchSHAParams) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBranchSHA", ctx, params)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBranchSHA indicates an expected call of GetBranchSHA.
+func (mr *MockClientMockRecorder) GetBranchSHA(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBranchSHA", reflect.TypeOf((*MockClient)(nil).GetBranchSHA), ctx, params)
+}
+
 // GetFileContent mocks base method.
 func (m *MockClient) GetFileContent(ctx context.Context, params GetFileContentParams) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -144,19 +203,49 @@ func (mr *MockClientMockRecorder) GetPullRequestChecks(ctx, params any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPullRequestChecks", reflect.TypeOf((*MockClient)(nil).GetPullRequestChecks), ctx, params)
 }
 
+// GetPullRequestState mocks base method.
+func (m *MockClient) GetPullRequestState(ctx context.Context, params GetPullRequestStateParams) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPullRequestState", ctx, params)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPullRequestState indicates an expected call of GetPullRequestState.
+func (mr *MockClientMockRecorder) GetPullRequestState(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPullRequestState", reflect.TypeOf((*MockClient)(nil).GetPullRequestState), ctx, params)
+}
+
+// ListDirectory mocks base method.
+func (m *MockClient) ListDirectory(ctx context.Context, params ListDirectoryParams) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDirectory", ctx, params)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDirectory indicates an expected call of ListDirectory.
+func (mr *MockClientMockRecorder) ListDirectory(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDirectory", reflect.TypeOf((*MockClient)(nil).ListDirectory), ctx, params)
+}
+
 // ListTags mocks base method.
-func (m *MockClient) ListTags(ctx context.Context, owner, repo string) ([]*github.RepositoryTag, error) {
+func (m *MockClient) ListTags(ctx context.Context, params ListTagsParams) ([]Tag, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListTags", ctx, owner, repo)
-	ret0, _ := ret[0].([]*github.RepositoryTag)
+	ret := m.ctrl.Call(m, "ListTags", ctx, params)
+	ret0, _ := ret[0].([]Tag)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListTags indicates an expected call of ListTags.
-func (mr *MockClientMockRecorder) ListTags(ctx, owner, repo any) *gomock.Call {
+func (mr *MockClientMockRecorder) ListTags(ctx, params any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTags", reflect.TypeOf((*MockClient)(nil).ListTags), ctx, owner, repo)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTags", reflect.TypeOf((*MockClient)(nil).ListTags), ctx, params)
 }
 
 // MergeMergeRequest mocks base method.
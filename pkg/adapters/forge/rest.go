@@ -0,0 +1,122 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// APIError is an error response of a forge REST API.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("forge API error %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound checks if the error is a forge API "404 Not Found" error.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// RESTClient sends JSON requests to the REST API of a forge.
+type RESTClient struct {
+	baseURL *url.URL
+	header  http.Header
+	http    *http.Client
+}
+
+// NewRESTClient creates a REST client of the API at the base URL, adding the
+// header (e.g. the authentication) to every request.
+func NewRESTClient(baseURL string, header http.Header) (*RESTClient, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return &RESTClient{baseURL: u, header: header, http: http.DefaultClient}, nil
+}
+
+// Do sends a request to the API and decodes the JSON response in out, unless nil.
+// The path is relative to the base URL and must be escaped.
+func (c *RESTClient) Do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	raw, err := c.DoRaw(ctx, method, path, query, body)
+	if err != nil || out == nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("failed to decode forge API response: %w", err)
+	}
+	return nil
+}
+
+// DoRaw sends a request to the API and returns the response body. The body, unless nil,
+// is sent as JSON. The path is relative to the base URL and must be escaped.
+func (c *RESTClient) DoRaw(ctx context.Context, method, path string, query url.Values, body any) ([]byte, error) {
+	u, err := c.baseURL.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: errorMessage(data)}
+	}
+	return data, nil
+}
+
+// errorMessage extracts the message of an error response body.
+func errorMessage(data []byte) string {
+	var message struct {
+		Message any    `json:"message"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &message); err != nil {
+		return strings.TrimSpace(string(data))
+	}
+	if message.Message != nil {
+		return fmt.Sprint(message.Message)
+	}
+	return message.Error
+}
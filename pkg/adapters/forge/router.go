@@ -0,0 +1,151 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+)
+
+// router routes the calls to the forge of the repository host.
+type router struct {
+	fallback Client
+	forges   map[string]Client
+}
+
+// NewRouter returns a client routing the calls to the forge of each host, by host,
+// and to the fallback client (GitHub) for the other hosts.
+func NewRouter(fallback Client, forges map[string]Client) Client {
+	lowered := make(map[string]Client, len(forges))
+	for host, client := range forges {
+		lowered[strings.ToLower(host)] = client
+	}
+	return &router{fallback: fallback, forges: lowered}
+}
+
+// forge returns the client of a host.
+func (r *router) forge(host string) Client {
+	if client, ok := r.forges[strings.ToLower(host)]; ok {
+		return client
+	}
+	return r.fallback
+}
+
+// repository returns the client of a repository, from its URL.
+func (r *router) repository(repoURL string) Client {
+	host, _, _ := adapters.ParseRepository(repoURL)
+	return r.forge(host)
+}
+
+// GetFileContent retrieves the content of a file from the forge of the repository.
+func (r *router) GetFileContent(ctx context.Context, params GetFileContentParams) ([]byte, error) {
+	return r.forge(params.Host).GetFileContent(ctx, params)
+}
+
+// ListDirectory lists the paths of the files in a directory from the forge of the repository.
+func (r *router) ListDirectory(ctx context.Context, params ListDirectoryParams) ([]string, error) {
+	return r.forge(params.Host).ListDirectory(ctx, params)
+}
+
+// ListTags retrieves the tags of a repository from its forge.
+func (r *router) ListTags(ctx context.Context, params ListTagsParams) ([]Tag, error) {
+	return r.forge(params.Host).ListTags(ctx, params)
+}
+
+// CreateMergeRequest creates a merge request on the forge of the repository.
+func (r *router) CreateMergeRequest(ctx context.Context, params CreateMergeRequestParams) (int, error) {
+	return r.repository(params.RepoURL).CreateMergeRequest(ctx, params)
+}
+
+// CheckPullRequestExists checks if a merge request exists on the forge of the repository.
+func (r *router) CheckPullRequestExists(ctx context.Context,
+	params CheckPullRequestExistsParams) (int, error) {
+	return r.repository(params.RepoURL).CheckPullRequestExists(ctx, params)
+}
+
+// BranchExists checks if a branch exists on the forge of the repository.
+func (r *router) BranchExists(ctx context.Context, params BranchExistsParams) (bool, error) {
+	return r.repository(params.RepoURL).BranchExists(ctx, params)
+}
+
+// GetBranchSHA returns the SHA of the head of a branch from the forge of the repository.
+func (r *router) GetBranchSHA(ctx context.Context, params GetBranchSHAParams) (string, error) {
+	return r.repository(params.RepoURL).GetBranchSHA(ctx, params)
+}
+
+// GetPullRequestState returns the state of a merge request from the forge of the repository.
+func (r *router) GetPullRequestState(ctx context.Context, params GetPullRequestStateParams) (string, error) {
+	return r.repository(params.RepoURL).GetPullRequestState(ctx, params)
+}
+
+// GetPullRequestChecks gets the status of the checks of a merge request from the forge of the repository.
+func (r *router) GetPullRequestChecks(ctx context.Context,
+	params GetPullRequestChecksParams) (*CheckStatus, error) {
+	return r.repository(params.RepoURL).GetPullRequestChecks(ctx, params)
+}
+
+// MergeMergeRequest merges a merge request on the forge of the repository.
+func (r *router) MergeMergeRequest(ctx context.Context, params MergeMergeRequestParams) error {
+	return r.repository(params.RepoURL).MergeMergeRequest(ctx, params)
+}
+
+// DeleteBranch deletes a branch on the forge of the repository.
+func (r *router) DeleteBranch(ctx context.Context, params DeleteBranchParams) error {
+	return r.repository(params.RepoURL).DeleteBranch(ctx, params)
+}
+
+// DeletePullRequest closes a merge request on the forge of the repository.
+func (r *router) DeletePullRequest(ctx context.Context, params DeletePullRequestParams) error {
+	return r.repository(params.RepoURL).DeletePullRequest(ctx, params)
+}
+
+// CheckMergeConflicts checks if a merge request has conflicts on the forge of the repository.
+func (r *router) CheckMergeConflicts(ctx context.Context, params CheckMergeConflictsParams) (bool, error) {
+	return r.repository(params.RepoURL).CheckMergeConflicts(ctx, params)
+}
+
+// CreateCommit creates a commit on a new branch on the forge of the repository.
+func (r *router) CreateCommit(ctx context.Context, params CreateCommitParams) (string, error) {
+	return r.repository(params.RepoURL).CreateCommit(ctx, params)
+}
+
+// DiscoverRepositories discovers the repositories of each forge with its client.
+// The results are in the order of the repositories.
+func (r *router) DiscoverRepositories(ctx context.Context, params DiscoverRepositoriesParams) (
+	[]DiscoveredRepository, error) {
+	// Group the repositories by forge, preserving their order
+	var clients []Client
+	indexes := make(map[Client][]int)
+	for i, repoURL := range params.RepoURLs {
+		client := r.repository(repoURL)
+		if _, ok := indexes[client]; !ok {
+			clients = append(clients, client)
+		}
+		indexes[client] = append(indexes[client], i)
+	}
+
+	results := make([]DiscoveredRepository, len(params.RepoURLs))
+	for _, client := range clients {
+		repoURLs := make([]string, 0, len(indexes[client]))
+		for _, i := range indexes[client] {
+			repoURLs = append(repoURLs, params.RepoURLs[i])
+		}
+
+		discovered, err := client.DiscoverRepositories(ctx, DiscoverRepositoriesParams{
+			RepoURLs: repoURLs,
+			Ref:      params.Ref,
+			Paths:    params.Paths,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(discovered) != len(repoURLs) {
+			return nil, fmt.Errorf("discovered %d repositories instead of %d", len(discovered), len(repoURLs))
+		}
+		for j, i := range indexes[client] {
+			results[i] = discovered[j]
+		}
+	}
+	return results, nil
+}
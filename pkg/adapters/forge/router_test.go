@@ -0,0 +1,64 @@
+//go:build unit
+// +build unit
+
+package forge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRouter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	githubClient := NewMockClient(ctrl)
+	gitlabClient := NewMockClient(ctrl)
+	r := NewRouter(githubClient, map[string]Client{"GitLab.example.com": gitlabClient})
+	ctx := context.Background()
+
+	// Calls are routed by the host of the params, or of the repository URL
+	gitlabTags := ListTagsParams{Host: "gitlab.example.com", Owner: "group", Repo: "project"}
+	gitlabClient.EXPECT().ListTags(ctx, gitlabTags).Return([]Tag{}, nil)
+	_, err := r.ListTags(ctx, gitlabTags)
+	require.NoError(t, err)
+
+	githubTags := ListTagsParams{Host: "github.com", Owner: "org", Repo: "repo"}
+	githubClient.EXPECT().ListTags(ctx, githubTags).Return([]Tag{}, nil)
+	_, err = r.ListTags(ctx, githubTags)
+	require.NoError(t, err)
+
+	merge := MergeMergeRequestParams{RepoURL: "https://gitlab.example.com/group/project.git", PRNumber: 1}
+	gitlabClient.EXPECT().MergeMergeRequest(ctx, merge).Return(nil)
+	require.NoError(t, r.MergeMergeRequest(ctx, merge))
+}
+
+func TestRouter_DiscoverRepositories(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	githubClient := NewMockClient(ctrl)
+	gitlabClient := NewMockClient(ctrl)
+	r := NewRouter(githubClient, map[string]Client{"gitlab.example.com": gitlabClient})
+	ctx := context.Background()
+
+	repos := []string{
+		"https://github.com/org/a.git",
+		"https://gitlab.example.com/group/b.git",
+		"https://github.com/org/c.git",
+	}
+	githubClient.EXPECT().DiscoverRepositories(ctx, DiscoverRepositoriesParams{
+		RepoURLs: []string{repos[0], repos[2]}, Ref: "main", Paths: []string{"go.mod"},
+	}).Return([]DiscoveredRepository{{RepoURL: repos[0]}, {RepoURL: repos[2]}}, nil)
+	gitlabClient.EXPECT().DiscoverRepositories(ctx, DiscoverRepositoriesParams{
+		RepoURLs: []string{repos[1]}, Ref: "main", Paths: []string{"go.mod"},
+	}).Return([]DiscoveredRepository{{RepoURL: repos[1]}}, nil)
+
+	results, err := r.DiscoverRepositories(ctx, DiscoverRepositoriesParams{
+		RepoURLs: repos, Ref: "main", Paths: []string{"go.mod"},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	for i, result := range results {
+		require.Equal(t, repos[i], result.RepoURL)
+	}
+}
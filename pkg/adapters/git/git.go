@@ -0,0 +1,214 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+func init() {
+	// Serve the local repositories in-process, instead of with the git binaries
+	client.InstallProtocol("file", server.DefaultServer)
+}
+
+// Credentials provides the tokens of the git hosts.
+type Credentials struct {
+	// GitHubToken is the token of github.com and of the hosts without token.
+	GitHubToken string
+	// HostTokens are the tokens of the git hosts other than github.com, by lowercase host.
+	HostTokens map[string]string
+	// TokenSource provides the GitHub tokens instead of the static token, such as
+	// the installation tokens of a GitHub App.
+	TokenSource adapters.TokenSource
+}
+
+// NewCredentials returns the credentials with the given tokens, whose hosts are lowercased.
+func NewCredentials(githubToken string, hostTokens map[string]string,
+	tokenSource adapters.TokenSource) Credentials {
+	lowered := make(map[string]string, len(hostTokens))
+	for host, token := range hostTokens {
+		lowered[strings.ToLower(host)] = token
+	}
+	return Credentials{GitHubToken: githubToken, HostTokens: lowered, TokenSource: tokenSource}
+}
+
+// Token returns the token of a repository of a git host.
+func (c Credentials) Token(host, owner, repo string) (string, error) {
+	if token, ok := c.HostTokens[host]; ok {
+		return token, nil
+	}
+	if c.TokenSource == nil {
+		return c.GitHubToken, nil
+	}
+
+	token, _, err := c.TokenSource.Token(owner, repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to get GitHub token: %w", err)
+	}
+	return token, nil
+}
+
+// Remote is the remote of a repository, with its authentication.
+type Remote struct {
+	// URL is the remote URL, without credentials.
+	URL string
+	// Auth is the authentication of the remote, nil for none.
+	Auth transport.AuthMethod
+}
+
+// Remote returns the remote of a repository, authenticated with the token of its host.
+// The local repositories (file:// URLs) are not authenticated.
+func (c Credentials) Remote(repoURL string) (Remote, error) {
+	if strings.HasPrefix(repoURL, "file://") {
+		return Remote{URL: repoURL}, nil
+	}
+
+	host, owner, repo := adapters.ParseRepository(repoURL)
+	if host == "" {
+		return Remote{}, fmt.Errorf("invalid repository URL format: %s", repoURL)
+	}
+	token, err := c.Token(host, owner, repo)
+	if err != nil {
+		return Remote{}, err
+	}
+
+	// Plain HTTP is kept for the forges without TLS, such as local ones
+	scheme := "https"
+	if strings.HasPrefix(repoURL, "http://") {
+		scheme = "http"
+	}
+	remote := Remote{URL: fmt.Sprintf("%s://%s/%s/%s.git", scheme, host, owner, repo)}
+	if token != "" {
+		// The x-access-token user is required by installation tokens, and ignored otherwise
+		remote.Auth = &http.BasicAuth{Username: "x-access-token", Password: token}
+	}
+	return remote, nil
+}
+
+// Clone clones a branch of the remote in the directory, with its last commit only.
+// The local repositories are cloned entirely, as they are served in-process without
+// shallow clones support.
+func Clone(ctx context.Context, dir string, remote Remote, branch string) error {
+	depth := 1
+	if strings.HasPrefix(remote.URL, "file://") {
+		depth = 0
+	}
+
+	_, err := gogit.PlainCloneContext(ctx, dir, false, &gogit.CloneOptions{
+		URL:           remote.URL,
+		Auth:          remote.Auth,
+		ReferenceName: plumbing.NewBranchReferenceName(branch),
+		SingleBranch:  true,
+		Depth:         depth,
+		Tags:          gogit.NoTags,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clone %s: %w", remote.URL, err)
+	}
+	return nil
+}
+
+// BranchExists checks if a branch exists on the remote, as "git ls-remote --heads".
+func BranchExists(ctx context.Context, remote Remote, branch string) (bool, error) {
+	r := gogit.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: "origin", URLs: []string{remote.URL}})
+	refs, err := r.ListContext(ctx, &gogit.ListOptions{Auth: remote.Auth})
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to list the branches of %s: %w", remote.URL, err)
+	}
+
+	name := plumbing.NewBranchReferenceName(branch)
+	for _, ref := range refs {
+		if ref.Name() == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Reset resets the working copy to a branch, as "git checkout -f" and "git clean -fd":
+// the changes are discarded, and the commits of the branches created since are left out.
+func Reset(dir, branch string) error {
+	repo, err := gogit.PlainOpen(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to open worktree: %w", err)
+	}
+
+	err = worktree.Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(branch), Force: true})
+	if err != nil {
+		return fmt.Errorf("failed to check out branch %s: %w", branch, err)
+	}
+	if err := worktree.Clean(&gogit.CleanOptions{Dir: true}); err != nil {
+		return fmt.Errorf("failed to remove untracked files: %w", err)
+	}
+	return nil
+}
+
+// CommitAndPushParams contains parameters for CommitAndPush.
+type CommitAndPushParams struct {
+	// Dir is the working copy, cloned from the remote.
+	Dir         string
+	Remote      Remote
+	BranchName  string
+	Message     string
+	AuthorName  string
+	AuthorEmail string
+}
+
+// CommitAndPush commits all the changes of the working copy on a new branch, and pushes
+// this branch to the remote.
+func CommitAndPush(ctx context.Context, params CommitAndPushParams) error {
+	repo, err := gogit.PlainOpen(params.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to open worktree: %w", err)
+	}
+
+	// Create the branch from the current commit, keeping the changes
+	branch := plumbing.NewBranchReferenceName(params.BranchName)
+	if err := worktree.Checkout(&gogit.CheckoutOptions{Branch: branch, Create: true, Keep: true}); err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", params.BranchName, err)
+	}
+
+	// Stage the new files, then commit them with the modified and deleted ones
+	if err := worktree.AddWithOptions(&gogit.AddOptions{All: true}); err != nil {
+		return fmt.Errorf("failed to add changes: %w", err)
+	}
+	author := &object.Signature{Name: params.AuthorName, Email: params.AuthorEmail, When: time.Now()}
+	if _, err := worktree.Commit(params.Message, &gogit.CommitOptions{All: true, Author: author}); err != nil {
+		return fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	refSpec := config.RefSpec(fmt.Sprintf("%s:%s", branch, branch))
+	err = repo.PushContext(ctx, &gogit.PushOptions{
+		RemoteName: gogit.DefaultRemoteName,
+		RemoteURL:  params.Remote.URL,
+		RefSpecs:   []config.RefSpec{refSpec},
+		Auth:       params.Remote.Auth,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to push branch %s: %w", params.BranchName, err)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+//go:build unit
+// +build unit
+
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/stretchr/testify/require"
+)
+
+// run runs a git command in a directory and returns its output.
+func run(t *testing.T, dir string, args ...string) string {
+	args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return string(out)
+}
+
+// newTestRemote creates a bare repository whose main branch has two commits with the
+// given files, and returns its path.
+func newTestRemote(t *testing.T, files map[string]string) string {
+	bare := filepath.Join(t.TempDir(), "repo.git")
+	require.NoError(t, os.MkdirAll(bare, 0o755))
+	run(t, bare, "init", "--bare", "--initial-branch=main")
+
+	work := t.TempDir()
+	run(t, work, "clone", bare, ".")
+	run(t, work, "checkout", "-b", "main")
+	run(t, work, "commit", "--allow-empty", "-m", "initial")
+	for path, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(work, path), []byte(content), 0o600))
+	}
+	run(t, work, "add", ".")
+	run(t, work, "commit", "-m", "files")
+	run(t, work, "push", "origin", "main")
+	return bare
+}
+
+func TestCredentials_Remote(t *testing.T) {
+	creds := NewCredentials("gh-token", map[string]string{"GitLab.example.com": "gl-token"}, nil)
+
+	remote, err := creds.Remote("https://github.com/org/repo")
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/org/repo.git", remote.URL)
+	require.Equal(t, &http.BasicAuth{Username: "x-access-token", Password: "gh-token"}, remote.Auth)
+
+	remote, err = creds.Remote("http://gitlab.example.com/org/repo.git")
+	require.NoError(t, err)
+	require.Equal(t, "http://gitlab.example.com/org/repo.git", remote.URL)
+	require.Equal(t, &http.BasicAuth{Username: "x-access-token", Password: "gl-token"}, remote.Auth)
+
+	remote, err = creds.Remote("file:///srv/repos/org/repo.git")
+	require.NoError(t, err)
+	require.Equal(t, Remote{URL: "file:///srv/repos/org/repo.git"}, remote)
+
+	_, err = creds.Remote("https://github.com/org")
+	require.Error(t, err)
+
+	remote, err = NewCredentials("", nil, nil).Remote("https://github.com/org/repo")
+	require.NoError(t, err)
+	require.Nil(t, remote.Auth)
+}
+
+func TestCloneCommitAndPush(t *testing.T) {
+	bare := newTestRemote(t, map[string]string{"go.mod": "module example.com/repo\n", "old.txt": "old\n"})
+	remote := Remote{URL: "file://" + bare}
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	require.NoError(t, Clone(ctx, dir, remote, "main"))
+	content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+	require.Equal(t, "module example.com/repo\n", string(content))
+
+	exists, err := BranchExists(ctx, remote, "depsync/update")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// Modify, add and delete files
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/repo\n\ngo 1.23\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new\n"), 0o644))
+	require.NoError(t, os.Remove(filepath.Join(dir, "old.txt")))
+	require.NoError(t, CommitAndPush(ctx, CommitAndPushParams{
+		Dir: dir, Remote: remote, BranchName: "depsync/update", Message: "chores(depsync): update",
+		AuthorName: "depsync", AuthorEmail: "depsync@example.com",
+	}))
+
+	exists, err = BranchExists(ctx, remote, "depsync/update")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	files := run(t, bare, "ls-tree", "-r", "--name-only", "depsync/update")
+	require.Equal(t, []string{"go.mod", "new.txt"}, strings.Fields(files))
+	log := run(t, bare, "log", "-1", "--format=%an <%ae> %s", "depsync/update")
+	require.Equal(t, "depsync <depsync@example.com> chores(depsync): update\n", log)
+	require.Equal(t, run(t, bare, "rev-parse", "main"), run(t, bare, "rev-parse", "depsync/update~1"))
+}
+
+func TestCommitAndPush_NoChanges(t *testing.T) {
+	bare := newTestRemote(t, map[string]string{"go.mod": "module example.com/repo\n"})
+	remote := Remote{URL: "file://" + bare}
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	require.NoError(t, Clone(ctx, dir, remote, "main"))
+	require.Error(t, CommitAndPush(ctx, CommitAndPushParams{
+		Dir: dir, Remote: remote, BranchName: "depsync/update", Message: "chores(depsync): update",
+		AuthorName: "depsync", AuthorEmail: "depsync@example.com",
+	}))
+
+	exists, err := BranchExists(ctx, remote, "depsync/update")
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestCommitAndPush_ShallowClone(t *testing.T) {
+	bare := newTestRemote(t, map[string]string{"go.mod": "module example.com/repo\n"})
+	remote := Remote{URL: "file://" + bare}
+
+	// The remote repositories are cloned with their last commit only
+	dir := t.TempDir()
+	run(t, dir, "clone", "--depth=1", "--branch=main", "file://"+bare, ".")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/repo/v2\n"), 0o644))
+	require.NoError(t, CommitAndPush(context.Background(), CommitAndPushParams{
+		Dir: dir, Remote: remote, BranchName: "depsync/update", Message: "chores(depsync): update",
+		AuthorName: "depsync", AuthorEmail: "depsync@example.com",
+	}))
+
+	require.Equal(t, "module example.com/repo/v2\n", run(t, bare, "show", "depsync/update:go.mod"))
+	run(t, bare, "fsck", "--strict")
+}
+
+func TestReset(t *testing.T) {
+	bare := newTestRemote(t, map[string]string{"go.mod": "module example.com/repo\n", "old.txt": "old\n"})
+	remote := Remote{URL: "file://" + bare}
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	require.NoError(t, Clone(ctx, dir, remote, "main"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/repo/v2\n"), 0o644))
+	require.NoError(t, CommitAndPush(ctx, CommitAndPushParams{
+		Dir: dir, Remote: remote, BranchName: "depsync/first", Message: "chores(depsync): first",
+		AuthorName: "depsync", AuthorEmail: "depsync@example.com",
+	}))
+
+	// The next branch starts from main, without the changes of the previous one
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "vendor", "example.com"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "vendor", "example.com", "new.txt"), []byte("new\n"), 0o644))
+	require.NoError(t, os.Remove(filepath.Join(dir, "old.txt")))
+	require.NoError(t, Reset(dir, "main"))
+
+	content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+	require.Equal(t, "module example.com/repo\n", string(content))
+	require.FileExists(t, filepath.Join(dir, "old.txt"))
+	require.NoDirExists(t, filepath.Join(dir, "vendor"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new\n"), 0o644))
+	require.NoError(t, CommitAndPush(ctx, CommitAndPushParams{
+		Dir: dir, Remote: remote, BranchName: "depsync/second", Message: "chores(depsync): second",
+		AuthorName: "depsync", AuthorEmail: "depsync@example.com",
+	}))
+	require.Equal(t, run(t, bare, "rev-parse", "main"), run(t, bare, "rev-parse", "depsync/second~1"))
+	require.Equal(t, "module example.com/repo\n", run(t, bare, "show", "depsync/second:go.mod"))
+}
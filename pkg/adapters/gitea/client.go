@@ -0,0 +1,443 @@
+package gitea
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+)
+
+const (
+	// pageSize is the number of tags and pull requests fetched, the default maximum of Gitea.
+	pageSize = 50
+	// mergeableRetries is the number of checks of a pull request that is not mergeable, as
+	// Gitea also reports it while checking its conflicts.
+	mergeableRetries = 5
+)
+
+// Options contains the options of the Gitea client.
+type Options struct {
+	// BaseURL is the API URL (e.g. https://forgejo.example.com/api/v1/).
+	BaseURL string
+	// Token is an access token.
+	Token string
+}
+
+// client implements the forge client using the Gitea (and Forgejo) REST API.
+type client struct {
+	api        *forge.RESTClient
+	retryDelay time.Duration
+}
+
+// New creates a Gitea or Forgejo client with the given options.
+func New(opts Options) (forge.Client, error) {
+	api, err := forge.NewRESTClient(opts.BaseURL, http.Header{"Authorization": {"token " + opts.Token}})
+	if err != nil {
+		return nil, fmt.Errorf("invalid Gitea API: %w", err)
+	}
+	return &client{api: api, retryDelay: 2 * time.Second}, nil
+}
+
+// repoPath returns the API path of a repository from its owner and name.
+func repoPath(owner, repo string) string {
+	return fmt.Sprintf("repos/%s/%s", url.PathEscape(owner), url.PathEscape(repo))
+}
+
+// repoPathFromURL returns the API path of a repository from its URL.
+func repoPathFromURL(repoURL string) (string, error) {
+	host, owner, repo := adapters.ParseRepository(repoURL)
+	if host == "" {
+		return "", fmt.Errorf("invalid repository URL format: %s", repoURL)
+	}
+	return repoPath(owner, repo), nil
+}
+
+// escapePath escapes each segment of a file path.
+func escapePath(path string) string {
+	return (&url.URL{Path: path}).EscapedPath()
+}
+
+// pullRequest is a Gitea pull request.
+type pullRequest struct {
+	Number    int    `json:"number"`
+	State     string `json:"state"`
+	Merged    bool   `json:"merged"`
+	Mergeable bool   `json:"mergeable"`
+	Head      struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+}
+
+// getPullRequest retrieves a pull request.
+func (c *client) getPullRequest(ctx context.Context, repoURL string, number int) (*pullRequest, error) {
+	repo, err := repoPathFromURL(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr pullRequest
+	if err := c.api.Do(ctx, http.MethodGet, fmt.Sprintf("%s/pulls/%d", repo, number), nil, nil, &pr); err != nil {
+		return nil, fmt.Errorf("failed to get pull request %d: %w", number, err)
+	}
+	return &pr, nil
+}
+
+// GetFileContent retrieves the raw content of a file from a Gitea repository.
+func (c *client) GetFileContent(ctx context.Context, params forge.GetFileContentParams) ([]byte, error) {
+	path := fmt.Sprintf("%s/raw/%s", repoPath(params.Owner, params.Repo), escapePath(params.Path))
+	content, err := c.api.DoRaw(ctx, http.MethodGet, path, url.Values{"ref": {params.Ref}}, nil)
+	if err != nil {
+		if forge.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", forge.ErrFileNotFound, params.Path)
+		}
+		return nil, err
+	}
+	return content, nil
+}
+
+// ListDirectory lists the paths of the files in a directory of a Gitea repository.
+// It returns an empty list if the directory does not exist.
+func (c *client) ListDirectory(ctx context.Context, params forge.ListDirectoryParams) ([]string, error) {
+	var entries []struct {
+		Type string `json:"type"`
+		Path string `json:"path"`
+	}
+	path := fmt.Sprintf("%s/contents/%s", repoPath(params.Owner, params.Repo), escapePath(params.Path))
+	if err := c.api.Do(ctx, http.MethodGet, path, url.Values{"ref": {params.Ref}}, nil, &entries); err != nil {
+		if forge.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type == "file" {
+			paths = append(paths, entry.Path)
+		}
+	}
+	return paths, nil
+}
+
+// ListTags retrieves the most recent tags of a Gitea repository.
+func (c *client) ListTags(ctx context.Context, params forge.ListTagsParams) ([]forge.Tag, error) {
+	var tags []struct {
+		Name   string `json:"name"`
+		Commit struct {
+			SHA string `json:"sha"`
+		} `json:"commit"`
+	}
+	path := repoPath(params.Owner, params.Repo) + "/tags"
+	if err := c.api.Do(ctx, http.MethodGet, path, url.Values{"limit": {fmt.Sprint(pageSize)}}, nil, &tags); err != nil {
+		return nil, err
+	}
+
+	result := make([]forge.Tag, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, forge.Tag{Name: tag.Name, CommitSHA: tag.Commit.SHA})
+	}
+	return result, nil
+}
+
+// CreateMergeRequest creates a pull request in the specified repository.
+func (c *client) CreateMergeRequest(ctx context.Context, params forge.CreateMergeRequestParams) (int, error) {
+	repo, err := repoPathFromURL(params.RepoURL)
+	if err != nil {
+		return -1, err
+	}
+
+	// Generate PR title and description, unless provided
+	title := params.Title
+	if title == "" {
+		title = adapters.FormatCommitMessage(params.ModulePath, params.TargetVersion)
+	}
+	description := params.Description
+	if description == "" {
+		description = adapters.FormatMergeRequestDescription(params.ModulePath, params.TargetVersion)
+	}
+
+	var pr pullRequest
+	err = c.api.Do(ctx, http.MethodPost, repo+"/pulls", nil, map[string]any{
+		"head":  params.SourceBranch,
+		"base":  adapters.DefaultBranch,
+		"title": title,
+		"body":  description,
+	}, &pr)
+	if err != nil {
+		return -1, err
+	}
+	return pr.Number, nil
+}
+
+// CheckPullRequestExists checks if an open pull request already exists for the given branch.
+// Returns the PR number if it exists, or -1 if it doesn't exist.
+func (c *client) CheckPullRequestExists(ctx context.Context, params forge.CheckPullRequestExistsParams) (int, error) {
+	repo, err := repoPathFromURL(params.RepoURL)
+	if err != nil {
+		return -1, err
+	}
+
+	// The pull requests cannot be filtered by head branch: the pages are listed until an
+	// empty one, as the server may return less pull requests than the limit
+	for page := 1; ; page++ {
+		var prs []pullRequest
+		query := url.Values{"state": {"open"}, "limit": {fmt.Sprint(pageSize)}, "page": {fmt.Sprint(page)}}
+		if err := c.api.Do(ctx, http.MethodGet, repo+"/pulls", query, nil, &prs); err != nil {
+			return -1, err
+		}
+		if len(prs) == 0 {
+			return -1, nil
+		}
+		for _, pr := range prs {
+			if pr.Head.Ref == params.SourceBranch {
+				return pr.Number, nil
+			}
+		}
+	}
+}
+
+// getBranch retrieves the SHA of the head of a branch.
+func (c *client) getBranch(ctx context.Context, repoURL, branch string) (string, error) {
+	repo, err := repoPathFromURL(repoURL)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Commit struct {
+			ID string `json:"id"`
+		} `json:"commit"`
+	}
+	path := fmt.Sprintf("%s/branches/%s", repo, escapePath(branch))
+	if err := c.api.Do(ctx, http.MethodGet, path, nil, nil, &result); err != nil {
+		return "", err
+	}
+	return result.Commit.ID, nil
+}
+
+// BranchExists checks if a branch exists in a Gitea repository.
+func (c *client) BranchExists(ctx context.Context, params forge.BranchExistsParams) (bool, error) {
+	if _, err := c.getBranch(ctx, params.RepoURL, params.BranchName); err != nil {
+		if forge.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get branch %s: %w", params.BranchName, err)
+	}
+	return true, nil
+}
+
+// GetBranchSHA returns the SHA of the commit at the head of a branch.
+func (c *client) GetBranchSHA(ctx context.Context, params forge.GetBranchSHAParams) (string, error) {
+	sha, err := c.getBranch(ctx, params.RepoURL, params.BranchName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get branch %s: %w", params.BranchName, err)
+	}
+	return sha, nil
+}
+
+// CreateCommit creates a commit writing the files on a new branch, based on the head of
+// the base branch, with the files API. It returns the SHA of the commit.
+func (c *client) CreateCommit(ctx context.Context, params forge.CreateCommitParams) (string, error) {
+	repo, err := repoPathFromURL(params.RepoURL)
+	if err != nil {
+		return "", err
+	}
+
+	// The existing files are updated from their blob SHA at the base commit, and the others
+	// created: Gitea rejects the commit if they changed on the base branch since then
+	files := make([]map[string]string, 0, len(params.Files))
+	for _, f := range params.Files {
+		var existing struct {
+			SHA string `json:"sha"`
+		}
+		operation := "update"
+		path := fmt.Sprintf("%s/contents/%s", repo, escapePath(f.Path))
+		err := c.api.Do(ctx, http.MethodGet, path, url.Values{"ref": {params.BaseSHA}}, nil, &existing)
+		if forge.IsNotFound(err) {
+			operation = "create"
+		} else if err != nil {
+			return "", fmt.Errorf("failed to get file %s: %w", f.Path, err)
+		}
+		files = append(files, map[string]string{
+			"operation": operation,
+			"path":      f.Path,
+			"content":   base64.StdEncoding.EncodeToString(f.Content),
+			"sha":       existing.SHA,
+		})
+	}
+
+	var result struct {
+		Commit struct {
+			SHA string `json:"sha"`
+		} `json:"commit"`
+	}
+	author := map[string]string{"name": params.AuthorName, "email": params.AuthorEmail}
+	err = c.api.Do(ctx, http.MethodPost, repo+"/contents", nil, map[string]any{
+		"branch":     params.BaseBranch,
+		"new_branch": params.BranchName,
+		"message":    params.Message,
+		"author":     author,
+		"committer":  author,
+		"files":      files,
+	}, &result)
+	if err != nil {
+		return "", fmt.Errorf("failed to create commit: %w", err)
+	}
+	return result.Commit.SHA, nil
+}
+
+// GetPullRequestState returns the state of a pull request: open, closed (without merge) or merged.
+func (c *client) GetPullRequestState(ctx context.Context, params forge.GetPullRequestStateParams) (string, error) {
+	pr, err := c.getPullRequest(ctx, params.RepoURL, params.PRNumber)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case pr.Merged:
+		return forge.PullRequestStateMerged, nil
+	case pr.State == "closed":
+		return forge.PullRequestStateClosed, nil
+	default:
+		return forge.PullRequestStateOpen, nil
+	}
+}
+
+// GetPullRequestChecks gets the combined commit status of the head of a pull request.
+func (c *client) GetPullRequestChecks(ctx context.Context,
+	params forge.GetPullRequestChecksParams) (*forge.CheckStatus, error) {
+	pr, err := c.getPullRequest(ctx, params.RepoURL, params.PRNumber)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := repoPathFromURL(params.RepoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var status struct {
+		State      string `json:"state"`
+		TotalCount int    `json:"total_count"`
+	}
+	path := fmt.Sprintf("%s/commits/%s/status", repo, url.PathEscape(pr.Head.SHA))
+	if err := c.api.Do(ctx, http.MethodGet, path, nil, nil, &status); err != nil {
+		return nil, fmt.Errorf("failed to get commit status: %w", err)
+	}
+
+	// No statuses yet, consider as running
+	if status.TotalCount == 0 {
+		return &forge.CheckStatus{Status: "running"}, nil
+	}
+	switch status.State {
+	case "success", "warning":
+		return &forge.CheckStatus{Status: "passed"}, nil
+	case "failure", "error":
+		return &forge.CheckStatus{Status: "failed"}, nil
+	default:
+		return &forge.CheckStatus{Status: "running"}, nil
+	}
+}
+
+// MergeMergeRequest merges a pull request, squashing its commits.
+func (c *client) MergeMergeRequest(ctx context.Context, params forge.MergeMergeRequestParams) error {
+	repo, err := repoPathFromURL(params.RepoURL)
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("%s/pulls/%d/merge", repo, params.PRNumber)
+	if err := c.api.Do(ctx, http.MethodPost, path, nil, map[string]any{"Do": "squash"}, nil); err != nil {
+		return fmt.Errorf("failed to merge pull request: %w", err)
+	}
+	return nil
+}
+
+// DeleteBranch deletes a branch from a Gitea repository.
+func (c *client) DeleteBranch(ctx context.Context, params forge.DeleteBranchParams) error {
+	repo, err := repoPathFromURL(params.RepoURL)
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("%s/branches/%s", repo, escapePath(params.BranchName))
+	if err := c.api.Do(ctx, http.MethodDelete, path, nil, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete branch %s: %w", params.BranchName, err)
+	}
+	return nil
+}
+
+// DeletePullRequest closes a pull request in a Gitea repository.
+func (c *client) DeletePullRequest(ctx context.Context, params forge.DeletePullRequestParams) error {
+	repo, err := repoPathFromURL(params.RepoURL)
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("%s/pulls/%d", repo, params.PRNumber)
+	if err := c.api.Do(ctx, http.MethodPatch, path, nil, map[string]any{"state": "closed"}, nil); err != nil {
+		return fmt.Errorf("failed to close pull request %d: %w", params.PRNumber, err)
+	}
+	return nil
+}
+
+// CheckMergeConflicts checks if a pull request has conflicts, i.e. is not mergeable. As
+// Gitea does not report whether its conflict check is still running, a pull request is
+// checked again while not mergeable, and only has conflicts if it is never mergeable.
+func (c *client) CheckMergeConflicts(ctx context.Context, params forge.CheckMergeConflictsParams) (bool, error) {
+	for attempt := 0; ; attempt++ {
+		pr, err := c.getPullRequest(ctx, params.RepoURL, params.PRNumber)
+		if err != nil {
+			return false, err
+		}
+		if pr.Mergeable || attempt == mergeableRetries-1 {
+			return !pr.Mergeable, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(c.retryDelay):
+		}
+	}
+}
+
+// DiscoverRepositories fetches the files and tags of the repositories with the REST API.
+// Missing files are absent from the results.
+func (c *client) DiscoverRepositories(ctx context.Context, params forge.DiscoverRepositoriesParams) (
+	[]forge.DiscoveredRepository, error) {
+	results := make([]forge.DiscoveredRepository, 0, len(params.RepoURLs))
+	for _, repoURL := range params.RepoURLs {
+		host, owner, repo := adapters.ParseRepository(repoURL)
+		if host == "" {
+			return nil, fmt.Errorf("invalid repository URL format: %s", repoURL)
+		}
+
+		result := forge.DiscoveredRepository{RepoURL: repoURL, Files: make(map[string][]byte)}
+		for _, path := range params.Paths {
+			content, err := c.GetFileContent(ctx, forge.GetFileContentParams{
+				Host: host, Owner: owner, Repo: repo, Path: path, Ref: params.Ref,
+			})
+			if errors.Is(err, forge.ErrFileNotFound) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			result.Files[path] = content
+		}
+
+		tags, err := c.ListTags(ctx, forge.ListTagsParams{Host: host, Owner: owner, Repo: repo})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags of %s: %w", repoURL, err)
+		}
+		result.Tags = tags
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,272 @@
+//go:build unit
+// +build unit
+
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/stretchr/testify/require"
+)
+
+const testRepoURL = "https://forgejo.example.com/org/repo.git"
+
+// newTestClient creates a client of a Gitea stand-in serving the given handlers, by method and escaped path.
+func newTestClient(t *testing.T, handlers map[string]http.HandlerFunc) *client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "token secret", r.Header.Get("Authorization"))
+		handler, ok := handlers[r.Method+" "+r.URL.EscapedPath()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "The target couldn't be found."}`))
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := New(Options{BaseURL: srv.URL + "/api/v1/", Token: "secret"})
+	require.NoError(t, err)
+	c.(*client).retryDelay = time.Millisecond
+	return c.(*client)
+}
+
+// reply returns a handler replying the value as JSON.
+func reply(t *testing.T, v any) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		require.NoError(t, json.NewEncoder(w).Encode(v))
+	}
+}
+
+// pulls returns a handler replying the pages of open pull requests, and empty pages after them.
+func pulls(t *testing.T, pages [][]map[string]any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "open", r.URL.Query().Get("state"))
+		page, err := strconv.Atoi(r.URL.Query().Get("page"))
+		require.NoError(t, err)
+		if page > len(pages) {
+			reply(t, []map[string]any{})(w, r)
+			return
+		}
+		reply(t, pages[page-1])(w, r)
+	}
+}
+
+func TestGetFileContentAndListDirectory(t *testing.T) {
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"GET /api/v1/repos/org/repo/raw/go.mod": func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "main", r.URL.Query().Get("ref"))
+			_, _ = w.Write([]byte("module forgejo.example.com/org/repo\n"))
+		},
+		"GET /api/v1/repos/org/repo/contents/.github/workflows": reply(t, []map[string]string{
+			{"type": "file", "path": ".github/workflows/ci.yml"},
+			{"type": "dir", "path": ".github/workflows/sub"},
+		}),
+	})
+	ctx := context.Background()
+
+	content, err := c.GetFileContent(ctx, forge.GetFileContentParams{
+		Owner: "org", Repo: "repo", Path: "go.mod", Ref: "main",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "module forgejo.example.com/org/repo\n", string(content))
+
+	_, err = c.GetFileContent(ctx, forge.GetFileContentParams{Owner: "org", Repo: "repo", Path: "tools.go", Ref: "main"})
+	require.ErrorIs(t, err, forge.ErrFileNotFound)
+
+	paths, err := c.ListDirectory(ctx, forge.ListDirectoryParams{
+		Owner: "org", Repo: "repo", Path: ".github/workflows", Ref: "main",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{".github/workflows/ci.yml"}, paths)
+}
+
+func TestListTags(t *testing.T) {
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"GET /api/v1/repos/org/repo/tags": reply(t, []map[string]any{
+			{"name": "v1.1.0", "commit": map[string]string{"sha": "abc"}},
+		}),
+	})
+
+	tags, err := c.ListTags(context.Background(), forge.ListTagsParams{Owner: "org", Repo: "repo"})
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+	require.Equal(t, "v1.1.0", tags[0].Name)
+	require.Equal(t, "abc", tags[0].CommitSHA)
+}
+
+func TestPullRequests(t *testing.T) {
+	var merged, closed bool
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"POST /api/v1/repos/org/repo/pulls": func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "depsync/update", body["head"])
+			require.Equal(t, "main", body["base"])
+			reply(t, map[string]any{"number": 4})(w, r)
+		},
+		"GET /api/v1/repos/org/repo/pulls": pulls(t, [][]map[string]any{{
+			{"number": 2, "head": map[string]string{"ref": "feature"}},
+			{"number": 4, "head": map[string]string{"ref": "depsync/update"}},
+		}}),
+		"GET /api/v1/repos/org/repo/pulls/4": reply(t, map[string]any{
+			"number": 4, "state": "open", "mergeable": false, "head": map[string]string{"sha": "def"},
+		}),
+		"GET /api/v1/repos/org/repo/commits/def/status": reply(t, map[string]any{
+			"state": "failure", "total_count": 2,
+		}),
+		"POST /api/v1/repos/org/repo/pulls/4/merge": func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "squash", body["Do"])
+			merged = true
+		},
+		"PATCH /api/v1/repos/org/repo/pulls/4": func(w http.ResponseWriter, r *http.Request) {
+			closed = true
+			reply(t, map[string]any{"number": 4, "state": "closed"})(w, r)
+		},
+	})
+	ctx := context.Background()
+
+	number, err := c.CreateMergeRequest(ctx, forge.CreateMergeRequestParams{
+		RepoURL: testRepoURL, SourceBranch: "depsync/update", ModulePath: "example.com/dep", TargetVersion: "v1.1.0",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 4, number)
+
+	number, err = c.CheckPullRequestExists(ctx, forge.CheckPullRequestExistsParams{
+		RepoURL: testRepoURL, SourceBranch: "depsync/update",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 4, number)
+
+	state, err := c.GetPullRequestState(ctx, forge.GetPullRequestStateParams{RepoURL: testRepoURL, PRNumber: 4})
+	require.NoError(t, err)
+	require.Equal(t, forge.PullRequestStateOpen, state)
+
+	status, err := c.GetPullRequestChecks(ctx, forge.GetPullRequestChecksParams{RepoURL: testRepoURL, PRNumber: 4})
+	require.NoError(t, err)
+	require.Equal(t, "failed", status.Status)
+
+	conflicts, err := c.CheckMergeConflicts(ctx, forge.CheckMergeConflictsParams{RepoURL: testRepoURL, PRNumber: 4})
+	require.NoError(t, err)
+	require.True(t, conflicts)
+
+	require.NoError(t, c.MergeMergeRequest(ctx, forge.MergeMergeRequestParams{RepoURL: testRepoURL, PRNumber: 4}))
+	require.True(t, merged)
+	require.NoError(t, c.DeletePullRequest(ctx, forge.DeletePullRequestParams{RepoURL: testRepoURL, PRNumber: 4}))
+	require.True(t, closed)
+}
+
+func TestCheckPullRequestExists_Pages(t *testing.T) {
+	// The server returns less pull requests than the limit, and the depsync one is on the second page
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"GET /api/v1/repos/org/repo/pulls": pulls(t, [][]map[string]any{
+			{{"number": 1, "head": map[string]string{"ref": "feature"}}},
+			{{"number": 7, "head": map[string]string{"ref": "depsync/update"}}},
+		}),
+	})
+	ctx := context.Background()
+
+	number, err := c.CheckPullRequestExists(ctx, forge.CheckPullRequestExistsParams{
+		RepoURL: testRepoURL, SourceBranch: "depsync/update",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 7, number)
+
+	number, err = c.CheckPullRequestExists(ctx, forge.CheckPullRequestExistsParams{
+		RepoURL: testRepoURL, SourceBranch: "depsync/missing",
+	})
+	require.NoError(t, err)
+	require.Equal(t, -1, number)
+}
+
+func TestCheckMergeConflicts_Checking(t *testing.T) {
+	var calls int
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"GET /api/v1/repos/org/repo/pulls/4": func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			// The pull request is not mergeable while its conflicts are checked
+			reply(t, map[string]any{"number": 4, "state": "open", "mergeable": calls == 2})(w, r)
+		},
+	})
+
+	conflicts, err := c.CheckMergeConflicts(context.Background(), forge.CheckMergeConflictsParams{
+		RepoURL: testRepoURL, PRNumber: 4,
+	})
+	require.NoError(t, err)
+	require.False(t, conflicts)
+	require.Equal(t, 2, calls)
+}
+
+func TestBranches(t *testing.T) {
+	var deleted bool
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"GET /api/v1/repos/org/repo/branches/main": reply(t, map[string]any{
+			"commit": map[string]string{"id": "abc"},
+		}),
+		"DELETE /api/v1/repos/org/repo/branches/depsync/update": func(w http.ResponseWriter, _ *http.Request) {
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		},
+	})
+	ctx := context.Background()
+
+	sha, err := c.GetBranchSHA(ctx, forge.GetBranchSHAParams{RepoURL: testRepoURL, BranchName: "main"})
+	require.NoError(t, err)
+	require.Equal(t, "abc", sha)
+
+	exists, err := c.BranchExists(ctx, forge.BranchExistsParams{RepoURL: testRepoURL, BranchName: "missing"})
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	require.NoError(t, c.DeleteBranch(ctx, forge.DeleteBranchParams{RepoURL: testRepoURL, BranchName: "depsync/update"}))
+	require.True(t, deleted)
+}
+
+func TestCreateCommit(t *testing.T) {
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"GET /api/v1/repos/org/repo/contents/go.mod": func(w http.ResponseWriter, r *http.Request) {
+			// The blob SHA is read at the base commit, for Gitea to reject a file changed since then
+			require.Equal(t, "base", r.URL.Query().Get("ref"))
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]string{"sha": "blob"}))
+		},
+		"POST /api/v1/repos/org/repo/contents": func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			author := map[string]any{"name": "depsync", "email": "depsync@example.com"}
+			require.Equal(t, map[string]any{
+				"branch":     "main",
+				"new_branch": "depsync/update",
+				"message":    "chores(depsync): update",
+				"author":     author,
+				"committer":  author,
+				"files": []any{
+					map[string]any{"operation": "update", "path": "go.mod", "content": "Z28gMS4yNAo=", "sha": "blob"},
+					map[string]any{"operation": "create", "path": "go.sum", "content": "", "sha": ""},
+				},
+			}, body)
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"commit": map[string]string{"sha": "abc"}}))
+		},
+	})
+
+	sha, err := c.CreateCommit(context.Background(), forge.CreateCommitParams{
+		RepoURL:     testRepoURL,
+		BaseBranch:  "main",
+		BaseSHA:     "base",
+		BranchName:  "depsync/update",
+		Message:     "chores(depsync): update",
+		Files:       []forge.CommitFile{{Path: "go.mod", Content: []byte("go 1.24\n")}, {Path: "go.sum"}},
+		AuthorName:  "depsync",
+		AuthorEmail: "depsync@example.com",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "abc", sha)
+}
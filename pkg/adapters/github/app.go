@@ -0,0 +1,221 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/google/go-github/v55/github"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// appJWTLifetime is the lifetime of the JWTs authenticating as the app, below the
+	// 10 minutes allowed by GitHub.
+	appJWTLifetime = 9 * time.Minute
+	// appJWTClockSkew is the delay the JWTs are backdated by, to allow for clock drift.
+	appJWTClockSkew = time.Minute
+	// AppTokenEarlyExpiry is the delay before their expiry the installation tokens are
+	// refreshed, so that they remain valid during the git operations using them.
+	AppTokenEarlyExpiry = 5 * time.Minute
+)
+
+// AppParams contains parameters for NewAppTokenSource.
+type AppParams struct {
+	// AppID is the ID of the GitHub App.
+	AppID int64
+	// PrivateKey is the PEM encoded private key of the GitHub App.
+	PrivateKey []byte
+	// InstallationID is the ID of the installation the tokens of all the repositories are
+	// minted for. When zero, the installation on the owner of each repository is used.
+	InstallationID int64
+	// BaseURL is the API URL (default: https://api.github.com/).
+	BaseURL string
+}
+
+// appTokenSource mints the installation tokens of a GitHub App, for the installation on the
+// owner of each repository.
+type appTokenSource struct {
+	gh *github.Client
+	// installationID is the configured installation, used for all the repositories when set.
+	installationID int64
+
+	mu sync.Mutex
+	// installations are the IDs of the installations of the app, by lowercase owner.
+	installations map[string]int64
+	// tokens are the token sources of the installations, by installation ID.
+	tokens map[int64]oauth2.TokenSource
+}
+
+// NewAppTokenSource returns a token source of the installation tokens of a GitHub App.
+// The installation of each repository owner is looked up once, and its tokens are minted
+// on demand and refreshed AppTokenEarlyExpiry before their expiry.
+func NewAppTokenSource(params AppParams) (adapters.TokenSource, error) {
+	key, err := parsePrivateKey(params.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	gh := github.NewClient(&http.Client{
+		Transport: &appTransport{
+			next:  http.DefaultTransport,
+			appID: params.AppID,
+			key:   key,
+			now:   time.Now,
+		},
+	})
+	if params.BaseURL != "" {
+		if gh, err = gh.WithEnterpriseURLs(params.BaseURL, params.BaseURL); err != nil {
+			return nil, fmt.Errorf("invalid GitHub App base URL: %w", err)
+		}
+	}
+
+	return &appTokenSource{
+		gh:             gh,
+		installationID: params.InstallationID,
+		installations:  make(map[string]int64),
+		tokens:         make(map[int64]oauth2.TokenSource),
+	}, nil
+}
+
+// Token returns an installation token of the installation of the app on the owner of the
+// repository, whose identity is the installation.
+func (s *appTokenSource) Token(owner, repo string) (string, string, error) {
+	id, src, err := s.installationTokens(owner, repo)
+	if err != nil {
+		return "", "", err
+	}
+	token, err := src.Token()
+	if err != nil {
+		return "", "", err
+	}
+	return token.AccessToken, "installation:" + strconv.FormatInt(id, 10), nil
+}
+
+// installationTokens returns the ID and the token source of the installation of the app on the owner of the
+// repository, looking the installation up the first time.
+func (s *appTokenSource) installationTokens(owner, repo string) (int64, oauth2.TokenSource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.installationID
+	if id == 0 {
+		var ok bool
+		if id, ok = s.installations[strings.ToLower(owner)]; !ok {
+			installation, _, err := s.gh.Apps.FindRepositoryInstallation(context.Background(), owner, repo)
+			if isNotFound(err) {
+				return 0, nil, fmt.Errorf("GitHub App is not installed on %s/%s", owner, repo)
+			} else if err != nil {
+				return 0, nil, fmt.Errorf("failed to find GitHub App installation of %s/%s: %w", owner, repo, err)
+			}
+			id = installation.GetID()
+			s.installations[strings.ToLower(owner)] = id
+		}
+	}
+
+	src, ok := s.tokens[id]
+	if !ok {
+		src = oauth2.ReuseTokenSourceWithExpiry(nil, &installationTokenSource{gh: s.gh, id: id}, AppTokenEarlyExpiry)
+		s.tokens[id] = src
+	}
+	return id, src, nil
+}
+
+// installationTokenSource mints the tokens of an installation of a GitHub App.
+type installationTokenSource struct {
+	gh *github.Client
+	id int64
+}
+
+// Token mints a new installation token.
+func (s *installationTokenSource) Token() (*oauth2.Token, error) {
+	token, _, err := s.gh.Apps.CreateInstallationToken(context.Background(), s.id, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create installation token: %w", err)
+	}
+	return &oauth2.Token{
+		AccessToken: token.GetToken(),
+		Expiry:      token.GetExpiresAt().Time,
+	}, nil
+}
+
+// appTransport authenticates the requests as the GitHub App, with a JWT signed by its private key.
+type appTransport struct {
+	next  http.RoundTripper
+	appID int64
+	key   *rsa.PrivateKey
+	now   func() time.Time
+}
+
+// RoundTrip adds a fresh JWT to the request.
+func (t *appTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	jwt, err := signAppJWT(t.appID, t.key, t.now())
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	return t.next.RoundTrip(req)
+}
+
+// signAppJWT returns a RS256 JWT authenticating as the app at the given time.
+func signAppJWT(appID int64, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoding := base64.RawURLEncoding
+	unsigned := encoding.EncodeToString(header) + "." + encoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+	}
+	return unsigned + "." + encoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey parses a PEM encoded RSA private key, in PKCS #1 (as generated
+// by GitHub) or PKCS #8 form.
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid GitHub App private key: no PEM data")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub App private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("invalid GitHub App private key: not a RSA key")
+	}
+	return rsaKey, nil
+}
@@ -0,0 +1,188 @@
+//go:build unit
+// +build unit
+
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/stretchr/testify/require"
+)
+
+// verifyAppJWT checks that the request is authenticated as the app 42 with the key.
+func verifyAppJWT(t *testing.T, r *http.Request, key *rsa.PrivateKey) {
+	jwt := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	parts := strings.Split(jwt, ".")
+	require.Len(t, parts, 3)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	require.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature))
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	var claims map[string]any
+	require.NoError(t, json.Unmarshal(payload, &claims))
+	require.Equal(t, "42", claims["iss"])
+}
+
+func TestAppTokenSource(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	// The app is installed on two owners. The first token of each installation expires
+	// within the early expiry delay, so it is refreshed
+	lookups := make(map[string]int)
+	minted := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifyAppJWT(t, r, key)
+		switch r.URL.Path {
+		case "/api/v3/repos/org-a/repo/installation", "/api/v3/repos/org-a/other/installation":
+			lookups["org-a"]++
+			_, _ = w.Write([]byte(`{"id": 7}`))
+		case "/api/v3/repos/org-b/repo/installation":
+			lookups["org-b"]++
+			_, _ = w.Write([]byte(`{"id": 8}`))
+		case "/api/v3/repos/org-c/repo/installation":
+			w.WriteHeader(http.StatusNotFound)
+		case "/api/v3/app/installations/7/access_tokens", "/api/v3/app/installations/8/access_tokens":
+			id := strings.Split(r.URL.Path, "/")[5]
+			minted[id]++
+			expiry := time.Now().Add(time.Hour)
+			if minted[id] == 1 {
+				expiry = time.Now().Add(time.Minute)
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]any{
+				"token":      fmt.Sprintf("token-%s-%d", id, minted[id]),
+				"expires_at": expiry.Format(time.RFC3339),
+			}))
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	ts, err := NewAppTokenSource(AppParams{AppID: 42, PrivateKey: keyPEM, BaseURL: srv.URL + "/api/v3/"})
+	require.NoError(t, err)
+
+	for _, c := range []struct{ owner, repo, expected string }{
+		{"org-a", "repo", "token-7-1"},
+		{"org-b", "repo", "token-8-1"},
+		{"org-a", "other", "token-7-2"},
+		{"ORG-A", "repo", "token-7-2"},
+		{"org-b", "repo", "token-8-2"},
+	} {
+		token, identity, err := ts.Token(c.owner, c.repo)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, token)
+		require.Equal(t, "installation:"+strings.Split(c.expected, "-")[1], identity)
+	}
+	require.Equal(t, map[string]int{"org-a": 1, "org-b": 1}, lookups)
+	require.Equal(t, map[string]int{"7": 2, "8": 2}, minted)
+
+	// The owners the app is not installed on have no token
+	_, _, err = ts.Token("org-c", "repo")
+	require.Error(t, err)
+}
+
+func TestAppTokenSource_Installation(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	// The configured installation is used for all the owners, without lookup
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v3/app/installations/9/access_tokens", r.URL.Path)
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]any{
+			"token":      "token-9",
+			"expires_at": time.Now().Add(time.Hour).Format(time.RFC3339),
+		}))
+	}))
+	defer srv.Close()
+
+	ts, err := NewAppTokenSource(AppParams{
+		AppID:          42,
+		PrivateKey:     keyPEM,
+		InstallationID: 9,
+		BaseURL:        srv.URL + "/api/v3/",
+	})
+	require.NoError(t, err)
+	for _, owner := range []string{"org-a", "org-b"} {
+		token, _, err := ts.Token(owner, "repo")
+		require.NoError(t, err)
+		require.Equal(t, "token-9", token)
+	}
+}
+
+func TestAppTokenSource_InvalidKey(t *testing.T) {
+	_, err := NewAppTokenSource(AppParams{AppID: 42, PrivateKey: []byte("not a key")})
+	require.Error(t, err)
+}
+
+// ownerTokenSource returns a token per owner.
+type ownerTokenSource struct{}
+
+func (ownerTokenSource) Token(owner, _ string) (string, string, error) {
+	return "token-" + owner, owner, nil
+}
+
+func TestOwnerTokens(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/repos/org-a/repo/tags":
+			require.Equal(t, "Bearer token-org-a", r.Header.Get("Authorization"))
+			_, _ = w.Write([]byte(`[]`))
+		case "/api/graphql":
+			// The repositories are batched by owner, with the token of the owner
+			var req graphqlRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			require.Equal(t, "Bearer token-"+req.Variables["o0"], r.Header.Get("Authorization"))
+			require.Empty(t, req.Variables["o1"])
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+				"r0": map[string]any{
+					"f0":   map[string]any{"text": "module example.com/" + req.Variables["o0"] + "\n"},
+					"refs": map[string]any{"nodes": []map[string]string{}},
+				},
+			}}))
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewWithOptions("", Options{
+		Hosts:       []Host{{Name: "ghe.example.com", BaseURL: srv.URL + "/api/v3/"}},
+		TokenSource: ownerTokenSource{},
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = c.ListTags(ctx, forge.ListTagsParams{Host: "ghe.example.com", Owner: "org-a", Repo: "repo"})
+	require.NoError(t, err)
+
+	results, err := c.DiscoverRepositories(ctx, forge.DiscoverRepositoriesParams{
+		RepoURLs: []string{"https://ghe.example.com/org-a/repo", "https://ghe.example.com/org-b/repo"},
+		Ref:      "main",
+		Paths:    []string{"go.mod"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "module example.com/org-a\n", string(results[0].Files["go.mod"]))
+	require.Equal(t, "module example.com/org-b\n", string(results[1].Files["go.mod"]))
+}
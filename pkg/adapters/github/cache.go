@@ -0,0 +1,171 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cryptellation/depsync/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// headerFromCache is set on the responses served from the cache. go-github does not
+// update its rate limits from them.
+const headerFromCache = "X-From-Cache"
+
+// cacheEntry is a cached response.
+type cacheEntry struct {
+	ETag   string      `json:"etag"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// cacheTransport is a transport making conditional requests (If-None-Match) for the GET
+// requests whose response is cached on disk. The 304 responses, which do not count against
+// the rate limit, are replaced by the cached ones.
+type cacheTransport struct {
+	next http.RoundTripper
+	dir  string
+}
+
+// newCacheTransport creates a cacheTransport storing the responses in the given directory.
+func newCacheTransport(next http.RoundTripper, dir string) *cacheTransport {
+	return &cacheTransport{
+		next: next,
+		dir:  dir,
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" {
+		return t.next.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	entry, cached := t.get(key)
+	if cached {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case cached && resp.StatusCode == http.StatusNotModified:
+		resp.Body.Close()
+		return entry.response(req, resp), nil
+	case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		err = t.put(key, cacheEntry{ETag: resp.Header.Get("ETag"), Header: resp.Header, Body: body})
+		if err != nil {
+			logging.C(req.Context()).Warn("Failed to cache GitHub response", zap.Error(err))
+		}
+	}
+	return resp, nil
+}
+
+// get returns the cached response of the key, if any.
+func (t *cacheTransport) get(key string) (cacheEntry, bool) {
+	data, err := os.ReadFile(filepath.Join(t.dir, key+".json"))
+	if err != nil {
+		return cacheEntry{}, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.ETag == "" {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+// put caches the response of the key, through a temporary file to never leave it partially
+// written, even with concurrent requests.
+func (t *cacheTransport) put(key string, entry cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	if err := os.MkdirAll(t.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(t.dir, key+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+	_, err = tmp.Write(data)
+	err = errors.Join(err, tmp.Close())
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(t.dir, key+".json"))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// response returns the cached response, with the rate limit headers of the 304 response.
+func (e cacheEntry) response(req *http.Request, notModified *http.Response) *http.Response {
+	header := e.Header.Clone()
+	for name, values := range notModified.Header {
+		if strings.HasPrefix(name, "X-Ratelimit-") {
+			header[name] = values
+		}
+	}
+	header.Set(headerFromCache, "1")
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// identityKey is the context key of the identity the requests are authenticated as.
+type identityKey struct{}
+
+// withIdentity returns a context whose requests are authenticated as the identity.
+func withIdentity(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// cacheKey returns the cache key of a request. The identity the request is authenticated as
+// is part of the key, so that responses are never shared between identities, but not the
+// token itself: the responses remain cached when the tokens rotate, such as the installation
+// tokens of a GitHub App, and the cache is not keyed on secrets.
+func cacheKey(req *http.Request) string {
+	identity, _ := req.Context().Value(identityKey{}).(string)
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		req.Method,
+		req.URL.String(),
+		req.Header.Get("Accept"),
+		identity,
+	}, "\n")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,120 @@
+//go:build unit
+// +build unit
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/google/go-github/v55/github"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCachedClient creates a client of the given server, caching the responses in dir.
+func newTestCachedClient(t *testing.T, srv *httptest.Server, dir string, tokens adapters.TokenSource) *client {
+	gh := newAPIClient(newCacheTransport(http.DefaultTransport, dir), tokens)
+	baseURL, err := url.Parse(srv.URL + "/")
+	require.NoError(t, err)
+	gh.BaseURL = baseURL
+	return &client{hosts: map[string]*github.Client{adapters.DefaultHost: gh}}
+}
+
+// rotatingTokenSource returns a new token of the same identity on each call, as the
+// installation tokens of a GitHub App.
+type rotatingTokenSource struct {
+	calls int
+}
+
+func (s *rotatingTokenSource) Token(_, _ string) (string, string, error) {
+	s.calls++
+	return fmt.Sprintf("token-%d", s.calls), "installation:7", nil
+}
+
+func TestCacheTransport(t *testing.T) {
+	var requests, notModified int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		require.Equal(t, "/repos/owner/repo/tags", r.URL.Path)
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"name": "v1.0.0"}, {"name": "v1.1.0"}]`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	// The first request fills the cache, the next ones are conditional
+	for i := 0; i < 3; i++ {
+		tags, err := newTestCachedClient(t, srv, dir, staticTokenSource("token")).ListTags(ctx, forge.ListTagsParams{Owner: "owner", Repo: "repo"})
+		require.NoError(t, err)
+		require.Len(t, tags, 2)
+		require.Equal(t, "v1.1.0", tags[1].Name)
+	}
+	require.Equal(t, 3, requests)
+	require.Equal(t, 2, notModified)
+
+	// The cache is not shared between tokens
+	_, err := newTestCachedClient(t, srv, dir, staticTokenSource("other")).ListTags(ctx, forge.ListTagsParams{Owner: "owner", Repo: "repo"})
+	require.NoError(t, err)
+	require.Equal(t, 4, requests)
+	require.Equal(t, 2, notModified)
+}
+
+func TestCacheTransport_RotatingTokens(t *testing.T) {
+	var notModified int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`[{"name": "v1.0.0"}]`))
+	}))
+	defer srv.Close()
+
+	// The responses remain cached when the tokens of the identity rotate
+	dir := t.TempDir()
+	tokens := &rotatingTokenSource{}
+	for i := 0; i < 3; i++ {
+		params := forge.ListTagsParams{Owner: "owner", Repo: "repo"}
+		_, err := newTestCachedClient(t, srv, dir, tokens).ListTags(context.Background(), params)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 3, tokens.calls)
+	require.Equal(t, 2, notModified)
+}
+
+func TestCacheTransport_NotCached(t *testing.T) {
+	var conditional int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			conditional++
+		}
+		// No ETag: the response is not cached
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	for i := 0; i < 2; i++ {
+		params := forge.ListTagsParams{Owner: "owner", Repo: "repo"}
+		_, err := newTestCachedClient(t, srv, dir, staticTokenSource("token")).ListTags(context.Background(), params)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 0, conditional)
+}
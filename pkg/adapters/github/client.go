@@ -1,112 +1,223 @@
-//go:generate go run go.uber.org/mock/mockgen@v0.5.2 -source=client.go -destination=mock.gen.go -package=github
 package github
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
 	"github.com/cryptellation/depsync/pkg/logging"
 	"github.com/google/go-github/v55/github"
 	"go.uber.org/zap"
-	"golang.org/x/oauth2"
 )
 
 // DepSyncPRTitlePrefix is the prefix used for DepSync pull request titles.
 const DepSyncPRTitlePrefix = "chores(depsync):"
 
-// GetFileContentParams contains parameters for GetFileContent.
-type GetFileContentParams struct {
-	Owner string
-	Repo  string
-	Path  string
-	Ref   string
+// ErrUnknownHost is returned for the repositories of a host that is not configured.
+var ErrUnknownHost = errors.New("unknown GitHub host")
+
+// client implements forge.Client using go-github.
+type client struct {
+	// hosts contains the API client of each host.
+	hosts map[string]*github.Client
 }
 
-// CreateMergeRequestParams contains parameters for CreateMergeRequest.
-type CreateMergeRequestParams struct {
-	RepoURL       string
-	SourceBranch  string
-	ModulePath    string
-	TargetVersion string
+// Default values of the Options.
+const (
+	DefaultMaxRetries   = 3
+	DefaultMaxRetryWait = 15 * time.Minute
+)
+
+// Options contains the options of the GitHub client.
+type Options struct {
+	// CacheDir is the directory of the conditional requests cache. The cache is disabled when empty.
+	CacheDir string
+	// MaxRetries is the maximum number of retries of the rate limited requests (default: DefaultMaxRetries).
+	MaxRetries int
+	// MaxRetryWait is the maximum delay waited for a rate limit at once (default: DefaultMaxRetryWait).
+	// Requests are not retried when the rate limit would take longer.
+	MaxRetryWait time.Duration
+	// Hosts are the GitHub Enterprise Server hosts, in addition to github.com.
+	Hosts []Host
+	// TokenSource provides the tokens of the github.com repositories instead of the static
+	// token, such as the installation tokens of a GitHub App (see NewAppTokenSource).
+	TokenSource adapters.TokenSource
 }
 
-// CheckPullRequestExistsParams contains parameters for CheckPullRequestExists.
-type CheckPullRequestExistsParams struct {
-	RepoURL      string
-	SourceBranch string
+// Host is a GitHub Enterprise Server host.
+type Host struct {
+	// Name is the host of the repositories URLs (e.g. ghe.example.com).
+	Name string
+	// BaseURL is the API URL (e.g. https://ghe.example.com/api/v3/).
+	BaseURL string
+	// UploadURL is the uploads API URL (default: BaseURL).
+	UploadURL string
+	// Token is the token of the host (default: the github.com tokens).
+	Token string
 }
 
-// GetPullRequestChecksParams contains parameters for GetPullRequestChecks.
-type GetPullRequestChecksParams struct {
-	RepoURL  string
-	PRNumber int
+// New creates a new GitHub client of github.com with the given token and the default options.
+func New(token string) forge.Client {
+	c, _ := NewWithOptions(token, Options{})
+	return c
 }
 
-// MergeMergeRequestParams contains parameters for MergeMergeRequest.
-type MergeMergeRequestParams struct {
-	RepoURL       string
-	PRNumber      int
-	ModulePath    string
-	TargetVersion string
+// NewWithOptions creates a new GitHub client with the given token and options.
+func NewWithOptions(token string, opts Options) (forge.Client, error) {
+	if opts.MaxRetries <= 0 {
+		opts.MaxRetries = DefaultMaxRetries
+	}
+	if opts.MaxRetryWait <= 0 {
+		opts.MaxRetryWait = DefaultMaxRetryWait
+	}
+
+	// Conditional requests are made below the rate limit handling, so that the retries
+	// are conditional too, and the credentials are added above both of them
+	var transport http.RoundTripper = http.DefaultTransport
+	if opts.CacheDir != "" {
+		transport = newCacheTransport(transport, opts.CacheDir)
+	}
+	transport = newRateLimitTransport(transport, opts.MaxRetries, opts.MaxRetryWait)
+
+	var ts adapters.TokenSource = staticTokenSource(token)
+	if opts.TokenSource != nil {
+		ts = opts.TokenSource
+	}
+
+	c := &client{
+		hosts: map[string]*github.Client{
+			adapters.DefaultHost: newAPIClient(transport, ts),
+		},
+	}
+	for _, h := range opts.Hosts {
+		hostTS := ts
+		if h.Token != "" {
+			hostTS = staticTokenSource(h.Token)
+		}
+		uploadURL := h.UploadURL
+		if uploadURL == "" {
+			uploadURL = h.BaseURL
+		}
+
+		gh, err := newAPIClient(transport, hostTS).WithEnterpriseURLs(h.BaseURL, uploadURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URLs for GitHub host %s: %w", h.Name, err)
+		}
+		c.hosts[strings.ToLower(h.Name)] = gh
+	}
+	return c, nil
+}
+
+// newAPIClient creates a go-github client authenticated with the tokens of the source.
+func newAPIClient(transport http.RoundTripper, ts adapters.TokenSource) *github.Client {
+	return github.NewClient(&http.Client{
+		Transport: &authTransport{tokens: ts, next: transport},
+	})
 }
 
-// DeleteBranchParams contains parameters for DeleteBranch.
-type DeleteBranchParams struct {
-	RepoURL    string
-	BranchName string
+// staticTokenSource is a token source returning the same token for every repository.
+type staticTokenSource string
+
+// Token returns the token, whose identity is its hash.
+func (s staticTokenSource) Token(_, _ string) (string, string, error) {
+	if s == "" {
+		return "", "", nil
+	}
+	sum := sha256.Sum256([]byte(s))
+	return string(s), "token:" + hex.EncodeToString(sum[:]), nil
 }
 
-type DeletePullRequestParams struct {
-	RepoURL  string
-	PRNumber int
+// repositoryKey is the context key of the repository of the requests whose URL has none.
+type repositoryKey struct{}
+
+// withRepository returns a context whose requests are authenticated with the token of the
+// repository, such as the GraphQL requests.
+func withRepository(ctx context.Context, owner, repo string) context.Context {
+	return context.WithValue(ctx, repositoryKey{}, [2]string{owner, repo})
 }
 
-// CheckMergeConflictsParams contains parameters for CheckMergeConflicts.
-type CheckMergeConflictsParams struct {
-	RepoURL  string
-	PRNumber int
+// requestRepository returns the owner and the name of the repository of a request, from its
+// URL (e.g. /repos/{owner}/{repo}/pulls) or from its context.
+func requestRepository(req *http.Request) (string, string) {
+	if _, rest, ok := strings.Cut(req.URL.Path, "/repos/"); ok {
+		if parts := strings.SplitN(rest, "/", 3); len(parts) >= 2 {
+			return parts[0], parts[1]
+		}
+	}
+	if repository, ok := req.Context().Value(repositoryKey{}).([2]string); ok {
+		return repository[0], repository[1]
+	}
+	return "", ""
 }
 
-// CheckStatus represents the status of CI/CD checks for a pull request.
-type CheckStatus struct {
-	Status string // "running", "passed", "failed"
+// authTransport authenticates the requests with the token of their repository.
+type authTransport struct {
+	tokens adapters.TokenSource
+	next   http.RoundTripper
 }
 
-// Client defines the interface for interacting with GitHub.
-type Client interface {
-	GetFileContent(ctx context.Context, params GetFileContentParams) ([]byte, error)
-	ListTags(ctx context.Context, owner, repo string) ([]*github.RepositoryTag, error)
-	CreateMergeRequest(ctx context.Context, params CreateMergeRequestParams) (int, error)
-	CheckPullRequestExists(ctx context.Context, params CheckPullRequestExistsParams) (int, error)
-	GetPullRequestChecks(ctx context.Context, params GetPullRequestChecksParams) (*CheckStatus, error)
-	MergeMergeRequest(ctx context.Context, params MergeMergeRequestParams) error
-	DeleteBranch(ctx context.Context, params DeleteBranchParams) error
-	DeletePullRequest(ctx context.Context, params DeletePullRequestParams) error
-	CheckMergeConflicts(ctx context.Context, params CheckMergeConflictsParams) (bool, error)
+// RoundTrip adds the token of the repository of the request, if any, and its identity to the
+// request context, for the cache to key the responses on.
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	owner, repo := requestRepository(req)
+	token, identity, err := t.tokens.Token(owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GitHub token: %w", err)
+	}
+
+	req = req.Clone(withIdentity(req.Context(), identity))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return t.next.RoundTrip(req)
 }
 
-// client implements Client using go-github.
-type client struct {
-	gh *github.Client
+// api returns the API client of a host, github.com when empty.
+func (c *client) api(host string) (*github.Client, error) {
+	if host == "" {
+		host = adapters.DefaultHost
+	}
+	gh, ok := c.hosts[strings.ToLower(host)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownHost, host)
+	}
+	return gh, nil
 }
 
-// New creates a new GitHub client with the given token.
-func New(token string) Client {
-	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-	gh := github.NewClient(oauth2.NewClient(context.Background(), ts))
-	return &client{gh: gh}
+// repository returns the API client, the owner and the name of a repository from its URL.
+func (c *client) repository(repoURL string) (*github.Client, string, string, error) {
+	host, owner, repo := adapters.ParseRepository(repoURL)
+	if host == "" {
+		return nil, "", "", fmt.Errorf("invalid repository URL format: %s", repoURL)
+	}
+	gh, err := c.api(host)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return gh, owner, repo, nil
 }
 
 // GetFileContent retrieves the content of a file from a GitHub repository.
-func (c *client) GetFileContent(ctx context.Context, params GetFileContentParams) ([]byte, error) {
-	fileContent, _, _, err := c.gh.Repositories.GetContents(
+func (c *client) GetFileContent(ctx context.Context, params forge.GetFileContentParams) ([]byte, error) {
+	gh, err := c.api(params.Host)
+	if err != nil {
+		return nil, err
+	}
+	fileContent, _, _, err := gh.Repositories.GetContents(
 		ctx, params.Owner, params.Repo, params.Path,
 		&github.RepositoryContentGetOptions{Ref: params.Ref},
 	)
 	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", forge.ErrFileNotFound, params.Path)
+		}
 		return nil, err
 	}
 	if fileContent == nil {
@@ -119,36 +230,78 @@ func (c *client) GetFileContent(ctx context.Context, params GetFileContentParams
 	return []byte(content), nil
 }
 
+// ListDirectory lists the paths of the files in a directory of a GitHub repository.
+// It returns an empty list if the directory does not exist.
+func (c *client) ListDirectory(ctx context.Context, params forge.ListDirectoryParams) ([]string, error) {
+	gh, err := c.api(params.Host)
+	if err != nil {
+		return nil, err
+	}
+	_, dirContent, _, err := gh.Repositories.GetContents(
+		ctx, params.Owner, params.Repo, params.Path,
+		&github.RepositoryContentGetOptions{Ref: params.Ref},
+	)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(dirContent))
+	for _, entry := range dirContent {
+		if entry.GetType() == "file" {
+			paths = append(paths, entry.GetPath())
+		}
+	}
+	return paths, nil
+}
+
 // ListTags retrieves the tags of a GitHub repository.
-func (c *client) ListTags(ctx context.Context, owner, repo string) ([]*github.RepositoryTag, error) {
-	tags, _, err := c.gh.Repositories.ListTags(ctx, owner, repo, nil)
-	return tags, err
+func (c *client) ListTags(ctx context.Context, params forge.ListTagsParams) ([]forge.Tag, error) {
+	gh, err := c.api(params.Host)
+	if err != nil {
+		return nil, err
+	}
+	tags, _, err := gh.Repositories.ListTags(ctx, params.Owner, params.Repo, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]forge.Tag, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, forge.Tag{Name: tag.GetName(), CommitSHA: tag.GetCommit().GetSHA()})
+	}
+	return result, nil
 }
 
 // CreateMergeRequest creates a merge request in the specified repository.
-func (c *client) CreateMergeRequest(ctx context.Context, params CreateMergeRequestParams) (int, error) {
+func (c *client) CreateMergeRequest(ctx context.Context, params forge.CreateMergeRequestParams) (int, error) {
 	// Extract owner and repo from the repository URL
-	// Format: https://github.com/owner/repo
-	parts := strings.Split(strings.TrimPrefix(params.RepoURL, "https://"), "/")
-	if len(parts) != 3 {
-		return -1, fmt.Errorf("invalid repository URL format: %s", params.RepoURL)
+	gh, owner, repo, err := c.repository(params.RepoURL)
+	if err != nil {
+		return -1, err
 	}
-	owner := parts[1]
-	repo := parts[2]
 
-	// Generate MR title and description
-	title := generateMRTitle(params.ModulePath, params.TargetVersion)
-	description := generateMRDescription(params.ModulePath, params.TargetVersion)
+	// Generate MR title and description, unless provided
+	title := params.Title
+	if title == "" {
+		title = generateMRTitle(params.ModulePath, params.TargetVersion)
+	}
+	description := params.Description
+	if description == "" {
+		description = generateMRDescription(params.ModulePath, params.TargetVersion)
+	}
 
 	// Create the pull request
 	pr := &github.NewPullRequest{
 		Title: &title,
 		Body:  &description,
 		Head:  &params.SourceBranch,
-		Base:  github.String("main"), // Using constant as specified
+		Base:  github.String(adapters.DefaultBranch),
 	}
 
-	createdPR, _, err := c.gh.PullRequests.Create(ctx, owner, repo, pr)
+	createdPR, _, err := gh.PullRequests.Create(ctx, owner, repo, pr)
 	if err != nil {
 		return -1, err
 	}
@@ -158,15 +311,12 @@ func (c *client) CreateMergeRequest(ctx context.Context, params CreateMergeReque
 
 // CheckPullRequestExists checks if a pull request already exists for the given branch.
 // Returns the PR number if it exists, or -1 if it doesn't exist.
-func (c *client) CheckPullRequestExists(ctx context.Context, params CheckPullRequestExistsParams) (int, error) {
+func (c *client) CheckPullRequestExists(ctx context.Context, params forge.CheckPullRequestExistsParams) (int, error) {
 	// Extract owner and repo from the repository URL
-	// Format: https://github.com/owner/repo
-	parts := strings.Split(strings.TrimPrefix(params.RepoURL, "https://"), "/")
-	if len(parts) != 3 {
-		return -1, fmt.Errorf("invalid repository URL format: %s", params.RepoURL)
+	gh, owner, repo, err := c.repository(params.RepoURL)
+	if err != nil {
+		return -1, err
 	}
-	owner := parts[1]
-	repo := parts[2]
 
 	// List pull requests with the specific head branch
 	opts := &github.PullRequestListOptions{
@@ -174,7 +324,7 @@ func (c *client) CheckPullRequestExists(ctx context.Context, params CheckPullReq
 		State: "open",
 	}
 
-	pulls, _, err := c.gh.PullRequests.List(ctx, owner, repo, opts)
+	pulls, _, err := gh.PullRequests.List(ctx, owner, repo, opts)
 	if err != nil {
 		return -1, err
 	}
@@ -188,21 +338,74 @@ func (c *client) CheckPullRequestExists(ctx context.Context, params CheckPullReq
 	return -1, nil
 }
 
+// BranchExists checks if a branch exists in a GitHub repository.
+func (c *client) BranchExists(ctx context.Context, params forge.BranchExistsParams) (bool, error) {
+	gh, owner, repo, err := c.repository(params.RepoURL)
+	if err != nil {
+		return false, err
+	}
+
+	_, _, err = gh.Git.GetRef(ctx, owner, repo, fmt.Sprintf("refs/heads/%s", params.BranchName))
+	if err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get branch %s: %w", params.BranchName, err)
+	}
+	return true, nil
+}
+
+// GetBranchSHA returns the SHA of the commit at the head of a branch.
+func (c *client) GetBranchSHA(ctx context.Context, params forge.GetBranchSHAParams) (string, error) {
+	gh, owner, repo, err := c.repository(params.RepoURL)
+	if err != nil {
+		return "", err
+	}
+
+	ref, _, err := gh.Git.GetRef(ctx, owner, repo, fmt.Sprintf("refs/heads/%s", params.BranchName))
+	if err != nil {
+		return "", fmt.Errorf("failed to get branch %s: %w", params.BranchName, err)
+	}
+	return ref.GetObject().GetSHA(), nil
+}
+
+// GetPullRequestState returns the state of a pull request: open, closed (without merge) or merged.
+func (c *client) GetPullRequestState(ctx context.Context, params forge.GetPullRequestStateParams) (string, error) {
+	gh, owner, repo, err := c.repository(params.RepoURL)
+	if err != nil {
+		return "", err
+	}
+
+	pr, _, err := gh.PullRequests.Get(ctx, owner, repo, params.PRNumber)
+	if err != nil {
+		return "", fmt.Errorf("failed to get pull request %d: %w", params.PRNumber, err)
+	}
+
+	switch {
+	case pr.GetMerged():
+		return forge.PullRequestStateMerged, nil
+	case pr.GetState() == "closed":
+		return forge.PullRequestStateClosed, nil
+	default:
+		return forge.PullRequestStateOpen, nil
+	}
+}
+
 // GetPullRequestChecks gets the status of CI/CD checks for a pull request.
-func (c *client) GetPullRequestChecks(ctx context.Context, params GetPullRequestChecksParams) (*CheckStatus, error) {
-	owner, repo, err := extractOwnerAndRepo(params.RepoURL)
+func (c *client) GetPullRequestChecks(ctx context.Context, params forge.GetPullRequestChecksParams) (*forge.CheckStatus, error) {
+	gh, owner, repo, err := c.repository(params.RepoURL)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get the pull request to find the head SHA
-	pr, _, err := c.gh.PullRequests.Get(ctx, owner, repo, params.PRNumber)
+	pr, _, err := gh.PullRequests.Get(ctx, owner, repo, params.PRNumber)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pull request: %w", err)
 	}
 
 	// Get check runs for the head commit
-	checkRuns, _, err := c.gh.Checks.ListCheckRunsForRef(ctx, owner, repo, *pr.Head.SHA, &github.ListCheckRunsOptions{})
+	checkRuns, _, err := gh.Checks.ListCheckRunsForRef(ctx, owner, repo, *pr.Head.SHA, &github.ListCheckRunsOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get check runs: %w", err)
 	}
@@ -211,13 +414,14 @@ func (c *client) GetPullRequestChecks(ctx context.Context, params GetPullRequest
 }
 
 // checkMergeConflictsWithRetry performs the actual merge conflict check with retry logic.
-func (c *client) checkMergeConflictsWithRetry(ctx context.Context, owner, repo string, prNumber int) (bool, error) {
+func (c *client) checkMergeConflictsWithRetry(ctx context.Context, gh *github.Client,
+	owner, repo string, prNumber int) (bool, error) {
 	maxRetries := 5
 	baseDelay := time.Second * 2
 
 	for attempt := 0; attempt < maxRetries; attempt++ {
 		// Get the pull request to check for conflicts
-		pr, _, err := c.gh.PullRequests.Get(ctx, owner, repo, prNumber)
+		pr, _, err := gh.PullRequests.Get(ctx, owner, repo, prNumber)
 		if err != nil {
 			return false, fmt.Errorf("failed to get pull request: %w", err)
 		}
@@ -272,25 +476,25 @@ func (c *client) checkMergeConflictsWithRetry(ctx context.Context, owner, repo s
 
 // CheckMergeConflicts checks if a pull request has merge conflicts.
 func (c *client) CheckMergeConflicts(ctx context.Context,
-	params CheckMergeConflictsParams) (bool, error) {
-	owner, repo, err := extractOwnerAndRepo(params.RepoURL)
+	params forge.CheckMergeConflictsParams) (bool, error) {
+	gh, owner, repo, err := c.repository(params.RepoURL)
 	if err != nil {
 		return false, err
 	}
 
-	return c.checkMergeConflictsWithRetry(ctx, owner, repo, params.PRNumber)
+	return c.checkMergeConflictsWithRetry(ctx, gh, owner, repo, params.PRNumber)
 }
 
 // MergeMergeRequest merges a pull request.
-func (c *client) MergeMergeRequest(ctx context.Context, params MergeMergeRequestParams) error {
-	owner, repo, err := extractOwnerAndRepo(params.RepoURL)
+func (c *client) MergeMergeRequest(ctx context.Context, params forge.MergeMergeRequestParams) error {
+	gh, owner, repo, err := c.repository(params.RepoURL)
 	if err != nil {
 		return err
 	}
 
 	// Merge the pull request with squash strategy
 	// The commit message will be the PR title (which is already in the correct format)
-	_, _, err = c.gh.PullRequests.Merge(ctx, owner, repo, params.PRNumber, "", &github.PullRequestOptions{
+	_, _, err = gh.PullRequests.Merge(ctx, owner, repo, params.PRNumber, "", &github.PullRequestOptions{
 		MergeMethod: "squash",
 	})
 	if err != nil {
@@ -301,14 +505,14 @@ func (c *client) MergeMergeRequest(ctx context.Context, params MergeMergeRequest
 }
 
 // DeleteBranch deletes a branch from a GitHub repository.
-func (c *client) DeleteBranch(ctx context.Context, params DeleteBranchParams) error {
-	owner, repo, err := extractOwnerAndRepo(params.RepoURL)
+func (c *client) DeleteBranch(ctx context.Context, params forge.DeleteBranchParams) error {
+	gh, owner, repo, err := c.repository(params.RepoURL)
 	if err != nil {
 		return err
 	}
 
 	// Delete the branch using GitHub API
-	_, err = c.gh.Git.DeleteRef(ctx, owner, repo, fmt.Sprintf("refs/heads/%s", params.BranchName))
+	_, err = gh.Git.DeleteRef(ctx, owner, repo, fmt.Sprintf("refs/heads/%s", params.BranchName))
 	if err != nil {
 		return fmt.Errorf("failed to delete branch %s: %w", params.BranchName, err)
 	}
@@ -317,14 +521,14 @@ func (c *client) DeleteBranch(ctx context.Context, params DeleteBranchParams) er
 }
 
 // DeletePullRequest closes a pull request in a GitHub repository.
-func (c *client) DeletePullRequest(ctx context.Context, params DeletePullRequestParams) error {
-	owner, repo, err := extractOwnerAndRepo(params.RepoURL)
+func (c *client) DeletePullRequest(ctx context.Context, params forge.DeletePullRequestParams) error {
+	gh, owner, repo, err := c.repository(params.RepoURL)
 	if err != nil {
 		return err
 	}
 
 	// Close the pull request by updating its state to "closed"
-	_, _, err = c.gh.PullRequests.Edit(ctx, owner, repo, params.PRNumber, &github.PullRequest{
+	_, _, err = gh.PullRequests.Edit(ctx, owner, repo, params.PRNumber, &github.PullRequest{
 		State: github.String("closed"),
 	})
 	if err != nil {
@@ -334,38 +538,35 @@ func (c *client) DeletePullRequest(ctx context.Context, params DeletePullRequest
 	return nil
 }
 
-// extractOwnerAndRepo extracts owner and repo from a GitHub URL.
-func extractOwnerAndRepo(repoURL string) (string, string, error) {
-	parts := strings.Split(strings.TrimPrefix(repoURL, "https://"), "/")
-	if len(parts) != 3 {
-		return "", "", fmt.Errorf("invalid repository URL format: %s", repoURL)
-	}
-	return parts[1], parts[2], nil
+// isNotFound checks if the error is a GitHub API "404 Not Found" error.
+func isNotFound(err error) bool {
+	var errResp *github.ErrorResponse
+	return errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusNotFound
 }
 
 // determineCheckStatus determines the overall status of check runs.
-func determineCheckStatus(checkRuns []*github.CheckRun) *CheckStatus {
+func determineCheckStatus(checkRuns []*github.CheckRun) *forge.CheckStatus {
 	if len(checkRuns) == 0 {
 		// No checks found, consider as running
-		return &CheckStatus{Status: "running"}
+		return &forge.CheckStatus{Status: "running"}
 	}
 
 	// Check if any checks are still running
 	for _, check := range checkRuns {
 		if *check.Status == "in_progress" || *check.Status == "queued" {
-			return &CheckStatus{Status: "running"}
+			return &forge.CheckStatus{Status: "running"}
 		}
 	}
 
 	// Check if any checks failed
 	for _, check := range checkRuns {
 		if *check.Conclusion == "failure" || *check.Conclusion == "cancelled" || *check.Conclusion == "timed_out" {
-			return &CheckStatus{Status: "failed"}
+			return &forge.CheckStatus{Status: "failed"}
 		}
 	}
 
 	// All checks passed
-	return &CheckStatus{Status: "passed"}
+	return &forge.CheckStatus{Status: "passed"}
 }
 
 // generateMRTitle generates the title for a merge request.
@@ -375,13 +576,5 @@ func generateMRTitle(modulePath, targetVersion string) string {
 
 // generateMRDescription generates the description for a merge request.
 func generateMRDescription(modulePath, targetVersion string) string {
-	return fmt.Sprintf(`## Dependency Update
-
-This merge request updates the dependency **%s** to version **%s**.
-
-### Changes
-- Updated dependency: `+"`%s`"+`
-- New version: `+"`%s`"+`
-
-This update was automatically generated by DepSync.`, modulePath, targetVersion, modulePath, targetVersion)
+	return adapters.FormatMergeRequestDescription(modulePath, targetVersion)
 }
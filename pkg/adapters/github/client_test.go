@@ -7,6 +7,8 @@ import (
 	"context"
 	"os"
 	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
 )
 
 func TestGetFileContent(t *testing.T) {
@@ -23,7 +25,7 @@ func TestGetFileContent(t *testing.T) {
 	path := "README"
 	ref := "master"
 
-	content, err := client.GetFileContent(ctx, GetFileContentParams{
+	content, err := client.GetFileContent(ctx, forge.GetFileContentParams{
 		Owner: owner,
 		Repo:  repo,
 		Path:  path,
@@ -49,7 +51,7 @@ func TestListTags(t *testing.T) {
 	owner := "kubernetes"
 	repo := "kubernetes"
 
-	tags, err := client.ListTags(ctx, owner, repo)
+	tags, err := client.ListTags(ctx, forge.ListTagsParams{Owner: owner, Repo: repo})
 	if err != nil {
 		t.Fatalf("failed to list tags: %v", err)
 	}
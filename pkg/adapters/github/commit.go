@@ -0,0 +1,66 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/google/go-github/v55/github"
+)
+
+// CreateCommit creates a commit writing the files on a new branch, whose parent is the base
+// commit, with the Git Data API: without clone. It returns the SHA of the commit.
+// The author is left to GitHub, which signs the commits created by GitHub Apps without
+// author, so that they are verified.
+func (c *client) CreateCommit(ctx context.Context, params forge.CreateCommitParams) (string, error) {
+	gh, owner, repo, err := c.repository(params.RepoURL)
+	if err != nil {
+		return "", err
+	}
+
+	parent, _, err := gh.Git.GetCommit(ctx, owner, repo, params.BaseSHA)
+	if err != nil {
+		return "", fmt.Errorf("failed to get commit %s: %w", params.BaseSHA, err)
+	}
+
+	// Create the blobs of the files, and a tree replacing them in the tree of the parent
+	entries := make([]*github.TreeEntry, 0, len(params.Files))
+	for _, f := range params.Files {
+		blob, _, err := gh.Git.CreateBlob(ctx, owner, repo, &github.Blob{
+			Content:  github.String(base64.StdEncoding.EncodeToString(f.Content)),
+			Encoding: github.String("base64"),
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to create blob of %s: %w", f.Path, err)
+		}
+		entries = append(entries, &github.TreeEntry{
+			Path: github.String(f.Path),
+			Mode: github.String("100644"),
+			Type: github.String("blob"),
+			SHA:  blob.SHA,
+		})
+	}
+	tree, _, err := gh.Git.CreateTree(ctx, owner, repo, parent.GetTree().GetSHA(), entries)
+	if err != nil {
+		return "", fmt.Errorf("failed to create tree: %w", err)
+	}
+
+	commit, _, err := gh.Git.CreateCommit(ctx, owner, repo, &github.Commit{
+		Message: github.String(params.Message),
+		Tree:    &github.Tree{SHA: tree.SHA},
+		Parents: []*github.Commit{{SHA: parent.SHA}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create commit: %w", err)
+	}
+
+	_, _, err = gh.Git.CreateRef(ctx, owner, repo, &github.Reference{
+		Ref:    github.String(fmt.Sprintf("refs/heads/%s", params.BranchName)),
+		Object: &github.GitObject{SHA: commit.SHA},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create branch %s: %w", params.BranchName, err)
+	}
+	return commit.GetSHA(), nil
+}
@@ -0,0 +1,72 @@
+//go:build unit
+// +build unit
+
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateCommit(t *testing.T) {
+	var steps []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if r.Method == http.MethodPost {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		}
+		steps = append(steps, r.Method+" "+r.URL.Path)
+
+		var reply any
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v3/repos/org/repo/git/commits/parent":
+			reply = map[string]any{"sha": "parent", "tree": map[string]string{"sha": "base-tree"}}
+		case "POST /api/v3/repos/org/repo/git/blobs":
+			content, err := base64.StdEncoding.DecodeString(body["content"].(string))
+			require.NoError(t, err)
+			require.Equal(t, "module ghe.example.com/org/repo\n", string(content))
+			reply = map[string]string{"sha": "blob"}
+		case "POST /api/v3/repos/org/repo/git/trees":
+			require.Equal(t, "base-tree", body["base_tree"])
+			require.Equal(t, []any{map[string]any{
+				"path": "go.mod", "mode": "100644", "type": "blob", "sha": "blob",
+			}}, body["tree"])
+			reply = map[string]string{"sha": "tree"}
+		case "POST /api/v3/repos/org/repo/git/commits":
+			// The author is left to GitHub, for the commit to be signed
+			require.Equal(t, map[string]any{
+				"message": "chores(depsync): update", "tree": "tree", "parents": []any{"parent"},
+			}, body)
+			reply = map[string]string{"sha": "commit"}
+		case "POST /api/v3/repos/org/repo/git/refs":
+			require.Equal(t, map[string]any{"ref": "refs/heads/depsync/update", "sha": "commit"}, body)
+			reply = map[string]string{"ref": "refs/heads/depsync/update"}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(reply))
+	}))
+	defer srv.Close()
+
+	sha, err := newTestEnterpriseClient(t, srv).CreateCommit(context.Background(), forge.CreateCommitParams{
+		RepoURL:     "https://ghe.example.com/org/repo",
+		BaseBranch:  "main",
+		BaseSHA:     "parent",
+		BranchName:  "depsync/update",
+		Message:     "chores(depsync): update",
+		Files:       []forge.CommitFile{{Path: "go.mod", Content: []byte("module ghe.example.com/org/repo\n")}},
+		AuthorName:  "depsync",
+		AuthorEmail: "depsync@example.com",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "commit", sha)
+	require.Len(t, steps, 5)
+}
@@ -0,0 +1,209 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/google/go-github/v55/github"
+)
+
+const (
+	// discoveryBatchSize is the number of repositories discovered by each GraphQL query.
+	discoveryBatchSize = 25
+	// discoveryTagCount is the number of most recent tags discovered for each repository.
+	discoveryTagCount = 100
+)
+
+// graphqlRequest is the body of a GraphQL request.
+type graphqlRequest struct {
+	Query     string            `json:"query"`
+	Variables map[string]string `json:"variables"`
+}
+
+// graphqlResponse is the body of a GraphQL response, whose data are the repositories by alias.
+type graphqlResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// graphqlBlob is a file of a GraphQL repository. Text is nil for binary files.
+type graphqlBlob struct {
+	Text *string `json:"text"`
+}
+
+// graphqlRefs are the tags of a GraphQL repository.
+type graphqlRefs struct {
+	Nodes []struct {
+		Name string `json:"name"`
+	} `json:"nodes"`
+}
+
+// DiscoverRepositories fetches the files and the most recent tags of repositories with the
+// GraphQL API, batching several repositories of the same host and owner in each query. The
+// results are in the order of the repositories.
+func (c *client) DiscoverRepositories(ctx context.Context, params forge.DiscoverRepositoriesParams) (
+	[]forge.DiscoveredRepository, error) {
+	// Group the repositories by host, as each host has its own GraphQL endpoint, and by owner,
+	// as the tokens may differ by owner (e.g. GitHub App installations)
+	type group struct{ host, owner string }
+	var groups []group
+	indexes := make(map[group][]int)
+	for i, repoURL := range params.RepoURLs {
+		host, owner, _ := adapters.ParseRepository(repoURL)
+		if host == "" {
+			return nil, fmt.Errorf("invalid repository URL format: %s", repoURL)
+		}
+		g := group{host: host, owner: strings.ToLower(owner)}
+		if _, ok := indexes[g]; !ok {
+			groups = append(groups, g)
+		}
+		indexes[g] = append(indexes[g], i)
+	}
+
+	results := make([]forge.DiscoveredRepository, len(params.RepoURLs))
+	for _, g := range groups {
+		gh, err := c.api(g.host)
+		if err != nil {
+			return nil, err
+		}
+
+		groupIndexes := indexes[g]
+		for start := 0; start < len(groupIndexes); start += discoveryBatchSize {
+			end := min(start+discoveryBatchSize, len(groupIndexes))
+			repoURLs := make([]string, 0, end-start)
+			for _, i := range groupIndexes[start:end] {
+				repoURLs = append(repoURLs, params.RepoURLs[i])
+			}
+
+			batch, err := c.discoverBatch(ctx, gh, repoURLs, params.Ref, params.Paths)
+			if err != nil {
+				return nil, err
+			}
+			for j, i := range groupIndexes[start:end] {
+				results[i] = batch[j]
+			}
+		}
+	}
+	return results, nil
+}
+
+// discoverBatch fetches the files and tags of the repositories with a single GraphQL query.
+func (c *client) discoverBatch(ctx context.Context, gh *github.Client, repoURLs []string, ref string,
+	paths []string) ([]forge.DiscoveredRepository, error) {
+	query, variables, err := buildDiscoveryQuery(repoURLs, ref, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := gh.NewRequest("POST", graphqlURL(gh), graphqlRequest{Query: query, Variables: variables})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GraphQL request: %w", err)
+	}
+	// The repositories of the batch share their owner, and thus their token
+	_, owner, repo := adapters.ParseRepository(repoURLs[0])
+	var resp graphqlResponse
+	if _, err := gh.Do(withRepository(ctx, owner, repo), req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to discover repositories: %w", err)
+	}
+	if len(resp.Errors) > 0 {
+		messages := make([]string, 0, len(resp.Errors))
+		for _, e := range resp.Errors {
+			messages = append(messages, e.Message)
+		}
+		return nil, fmt.Errorf("failed to discover repositories: %s", strings.Join(messages, "; "))
+	}
+
+	results := make([]forge.DiscoveredRepository, 0, len(repoURLs))
+	for i, repoURL := range repoURLs {
+		result, err := parseDiscoveredRepository(repoURL, resp.Data[fmt.Sprintf("r%d", i)], paths)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// graphqlURL returns the GraphQL endpoint of a client: GitHub Enterprise Server serves it
+// at /api/graphql, next to the /api/v3/ REST API, and github.com at /graphql.
+func graphqlURL(gh *github.Client) string {
+	if strings.HasSuffix(gh.BaseURL.Path, "/api/v3/") {
+		u := *gh.BaseURL
+		u.Path = strings.TrimSuffix(u.Path, "/api/v3/") + "/api/graphql"
+		return u.String()
+	}
+	return "graphql"
+}
+
+// buildDiscoveryQuery builds the GraphQL query fetching the files and tags of the repositories,
+// aliased r0, r1, etc. The repositories and file expressions are passed as variables.
+func buildDiscoveryQuery(repoURLs []string, ref string, paths []string) (string, map[string]string, error) {
+	variables := make(map[string]string)
+	var declarations []string
+	for j, path := range paths {
+		declarations = append(declarations, fmt.Sprintf("$e%d: String!", j))
+		variables[fmt.Sprintf("e%d", j)] = ref + ":" + path
+	}
+
+	var body strings.Builder
+	for i, repoURL := range repoURLs {
+		host, owner, repo := adapters.ParseRepository(repoURL)
+		if host == "" {
+			return "", nil, fmt.Errorf("invalid repository URL format: %s", repoURL)
+		}
+		declarations = append(declarations, fmt.Sprintf("$o%d: String!, $n%d: String!", i, i))
+		variables[fmt.Sprintf("o%d", i)] = owner
+		variables[fmt.Sprintf("n%d", i)] = repo
+
+		fmt.Fprintf(&body, "  r%d: repository(owner: $o%d, name: $n%d) {\n", i, i, i)
+		for j := range paths {
+			fmt.Fprintf(&body, "    f%d: object(expression: $e%d) { ... on Blob { text } }\n", j, j)
+		}
+		fmt.Fprintf(&body, "    refs(refPrefix: \"refs/tags/\", first: %d, "+
+			"orderBy: {field: TAG_COMMIT_DATE, direction: DESC}) { nodes { name } }\n", discoveryTagCount)
+		body.WriteString("  }\n")
+	}
+
+	query := fmt.Sprintf("query(%s) {\n%s}", strings.Join(declarations, ", "), body.String())
+	return query, variables, nil
+}
+
+// parseDiscoveredRepository parses the files and tags of a repository from its GraphQL data.
+func parseDiscoveredRepository(repoURL string, data json.RawMessage, paths []string) (forge.DiscoveredRepository, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return forge.DiscoveredRepository{}, fmt.Errorf("failed to parse repository %s: %w", repoURL, err)
+	} else if fields == nil {
+		return forge.DiscoveredRepository{}, fmt.Errorf("repository not found: %s", repoURL)
+	}
+
+	result := forge.DiscoveredRepository{
+		RepoURL: repoURL,
+		Files:   make(map[string][]byte),
+	}
+	for j, path := range paths {
+		var blob *graphqlBlob
+		if err := json.Unmarshal(fields[fmt.Sprintf("f%d", j)], &blob); err != nil {
+			return forge.DiscoveredRepository{}, fmt.Errorf("failed to parse %s of %s: %w", path, repoURL, err)
+		}
+		if blob != nil && blob.Text != nil {
+			result.Files[path] = []byte(*blob.Text)
+		}
+	}
+
+	var refs graphqlRefs
+	if err := json.Unmarshal(fields["refs"], &refs); err != nil {
+		return forge.DiscoveredRepository{}, fmt.Errorf("failed to parse tags of %s: %w", repoURL, err)
+	}
+	for _, node := range refs.Nodes {
+		result.Tags = append(result.Tags, forge.Tag{Name: node.Name})
+	}
+	return result, nil
+}
@@ -0,0 +1,115 @@
+//go:build unit
+// +build unit
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/google/go-github/v55/github"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient creates a client of the given server.
+func newTestClient(t *testing.T, srv *httptest.Server) *client {
+	gh := github.NewClient(nil)
+	baseURL, err := url.Parse(srv.URL + "/")
+	require.NoError(t, err)
+	gh.BaseURL = baseURL
+	return &client{hosts: map[string]*github.Client{adapters.DefaultHost: gh}}
+}
+
+func TestDiscoverRepositories(t *testing.T) {
+	var queries int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries++
+		require.Equal(t, "/graphql", r.URL.Path)
+
+		var req graphqlRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "main:go.mod", req.Variables["e0"])
+		require.Equal(t, "main:tools.go", req.Variables["e1"])
+
+		// Reply for each repository of the batch: only repo0 has a tools file
+		data := make(map[string]any)
+		for i := 0; req.Variables[fmt.Sprintf("n%d", i)] != ""; i++ {
+			name := req.Variables[fmt.Sprintf("n%d", i)]
+			require.Equal(t, "org", req.Variables[fmt.Sprintf("o%d", i)])
+			require.Contains(t, req.Query, fmt.Sprintf("r%d: repository(owner: $o%d, name: $n%d)", i, i, i))
+
+			var tools any
+			if name == "repo0" {
+				tools = map[string]any{"text": "package tools\n"}
+			}
+			data[fmt.Sprintf("r%d", i)] = map[string]any{
+				"f0": map[string]any{"text": "module github.com/org/" + name + "\n"},
+				"f1": tools,
+				"refs": map[string]any{"nodes": []map[string]string{
+					{"name": "v1.1.0"}, {"name": "v1.0.0"},
+				}},
+			}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"data": data}))
+	}))
+	defer srv.Close()
+
+	repoURLs := make([]string, 30)
+	for i := range repoURLs {
+		repoURLs[i] = fmt.Sprintf("https://github.com/org/repo%d", i)
+	}
+	results, err := newTestClient(t, srv).DiscoverRepositories(context.Background(), forge.DiscoverRepositoriesParams{
+		RepoURLs: repoURLs,
+		Ref:      "main",
+		Paths:    []string{"go.mod", "tools.go"},
+	})
+	require.NoError(t, err)
+
+	// 30 repositories are discovered in 2 queries, in order
+	require.Equal(t, 2, queries)
+	require.Len(t, results, 30)
+	for i, r := range results {
+		require.Equal(t, repoURLs[i], r.RepoURL)
+		require.Equal(t, fmt.Sprintf("module github.com/org/repo%d\n", i), string(r.Files["go.mod"]))
+		require.Len(t, r.Tags, 2)
+		require.Equal(t, "v1.1.0", r.Tags[0].Name)
+	}
+	require.Equal(t, "package tools\n", string(results[0].Files["tools.go"]))
+	require.NotContains(t, results[1].Files, "tools.go")
+}
+
+func TestDiscoverRepositories_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data": {"r0": null}, "errors": [` +
+			`{"type": "NOT_FOUND", "message": "Could not resolve to a Repository with the name 'org/missing'."}]}`))
+	}))
+	defer srv.Close()
+
+	_, err := newTestClient(t, srv).DiscoverRepositories(context.Background(), forge.DiscoverRepositoriesParams{
+		RepoURLs: []string{"https://github.com/org/missing"},
+		Ref:      "main",
+		Paths:    []string{"go.mod"},
+	})
+	require.ErrorContains(t, err, "Could not resolve to a Repository")
+}
+
+func TestBuildDiscoveryQuery(t *testing.T) {
+	query, variables, err := buildDiscoveryQuery([]string{"https://github.com/org/repo"}, "main", []string{"go.mod"})
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]string{"e0": "main:go.mod", "o0": "org", "n0": "repo"}, variables)
+	require.True(t, strings.HasPrefix(query, "query($e0: String!, $o0: String!, $n0: String!) {"))
+	require.Contains(t, query, "f0: object(expression: $e0) { ... on Blob { text } }")
+	require.Contains(t, query, "orderBy: {field: TAG_COMMIT_DATE, direction: DESC}")
+
+	_, _, err = buildDiscoveryQuery([]string{"not a repository"}, "main", nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,79 @@
+//go:build unit
+// +build unit
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestEnterpriseClient creates a client whose ghe.example.com host is the given server.
+func newTestEnterpriseClient(t *testing.T, srv *httptest.Server) *client {
+	c, err := NewWithOptions("token", Options{
+		Hosts: []Host{{Name: "GHE.example.com", BaseURL: srv.URL + "/api/v3/", Token: "ghe-token"}},
+	})
+	require.NoError(t, err)
+	return c.(*client)
+}
+
+func TestEnterpriseHostRouting(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v3/repos/org/repo/tags", r.URL.Path)
+		require.Equal(t, "Bearer ghe-token", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`[{"name": "v1.0.0"}]`))
+	}))
+	defer srv.Close()
+
+	tags, err := newTestEnterpriseClient(t, srv).ListTags(context.Background(), forge.ListTagsParams{
+		Host:  "ghe.example.com",
+		Owner: "org",
+		Repo:  "repo",
+	})
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+	require.Equal(t, "v1.0.0", tags[0].Name)
+}
+
+func TestUnknownHost(t *testing.T) {
+	c, err := NewWithOptions("token", Options{})
+	require.NoError(t, err)
+
+	_, err = c.ListTags(context.Background(), forge.ListTagsParams{Host: "ghe.example.com", Owner: "org", Repo: "repo"})
+	require.ErrorIs(t, err, ErrUnknownHost)
+
+	_, err = c.GetPullRequestState(context.Background(), forge.GetPullRequestStateParams{
+		RepoURL:  "https://ghe.example.com/org/repo",
+		PRNumber: 1,
+	})
+	require.ErrorIs(t, err, ErrUnknownHost)
+}
+
+func TestEnterpriseGraphQLDiscovery(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/graphql", r.URL.Path)
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+			"r0": map[string]any{
+				"f0":   map[string]any{"text": "module ghe.example.com/org/repo\n"},
+				"refs": map[string]any{"nodes": []map[string]string{{"name": "v1.0.0"}}},
+			},
+		}}))
+	}))
+	defer srv.Close()
+
+	results, err := newTestEnterpriseClient(t, srv).DiscoverRepositories(context.Background(),
+		forge.DiscoverRepositoriesParams{
+			RepoURLs: []string{"https://ghe.example.com/org/repo"},
+			Ref:      "main",
+			Paths:    []string{"go.mod"},
+		})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "module ghe.example.com/org/repo\n", string(results[0].Files["go.mod"]))
+}
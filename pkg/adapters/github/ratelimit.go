@@ -0,0 +1,157 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cryptellation/depsync/pkg/logging"
+	"go.uber.org/zap"
+)
+
+const (
+	headerRateRemaining = "X-RateLimit-Remaining"
+	headerRateReset     = "X-RateLimit-Reset"
+	headerRetryAfter    = "Retry-After"
+)
+
+// secondaryRateLimitBackoff is the initial backoff of the secondary rate limits without
+// Retry-After header, as recommended by GitHub.
+const secondaryRateLimitBackoff = time.Minute
+
+// rateLimitTransport is a transport waiting for the GitHub rate limits: it retries the
+// rate limited requests (403 and 429) after the delay GitHub asks for, and waits for the
+// reset when the quota is exhausted so that the next requests are not rejected.
+type rateLimitTransport struct {
+	next       http.RoundTripper
+	maxRetries int
+	maxWait    time.Duration
+	backoff    time.Duration
+	now        func() time.Time
+	sleep      func(ctx context.Context, d time.Duration) error
+}
+
+// newRateLimitTransport creates a rateLimitTransport retrying at most maxRetries times,
+// and never waiting more than maxWait at once.
+func newRateLimitTransport(next http.RoundTripper, maxRetries int, maxWait time.Duration) *rateLimitTransport {
+	return &rateLimitTransport{
+		next:       next,
+		maxRetries: maxRetries,
+		maxWait:    maxWait,
+		backoff:    secondaryRateLimitBackoff,
+		now:        time.Now,
+		sleep:      sleepContext,
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		wait, limited := t.retryDelay(resp, attempt)
+		if !limited {
+			// Wait for the reset when the quota is exhausted, before the next requests are sent
+			if d := t.untilReset(resp); resp.Header.Get(headerRateRemaining) == "0" && d > 0 && d <= t.maxWait {
+				t.logWait(ctx, req, resp.StatusCode, d)
+				if err := t.sleep(ctx, d); err != nil {
+					resp.Body.Close()
+					return nil, err
+				}
+			}
+			return resp, nil
+		}
+
+		replayable := req.Body == nil || req.GetBody != nil
+		if attempt >= t.maxRetries || wait > t.maxWait || !replayable {
+			return resp, nil
+		}
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		t.logWait(ctx, req, resp.StatusCode, wait)
+		if err := t.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+
+		if req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+	}
+}
+
+// retryDelay returns the delay before retrying a rate limited request, and whether it is rate limited.
+func (t *rateLimitTransport) retryDelay(resp *http.Response, attempt int) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	if v := resp.Header.Get(headerRetryAfter); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+	if resp.Header.Get(headerRateRemaining) == "0" {
+		return t.untilReset(resp), true
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || isSecondaryRateLimit(resp) {
+		return t.backoff << attempt, true
+	}
+
+	// Other 403 are permission errors
+	return 0, false
+}
+
+// untilReset returns the delay until the rate limit of the response is reset.
+func (t *rateLimitTransport) untilReset(resp *http.Response) time.Duration {
+	reset, err := strconv.ParseInt(resp.Header.Get(headerRateReset), 10, 64)
+	if err != nil {
+		return 0
+	}
+	// Add a second as the reset time is truncated to the second
+	return time.Unix(reset, 0).Add(time.Second).Sub(t.now())
+}
+
+// logWait logs the delay the request waits for.
+func (t *rateLimitTransport) logWait(ctx context.Context, req *http.Request, status int, d time.Duration) {
+	logging.C(ctx).Warn("GitHub rate limit reached, waiting",
+		zap.String("method", req.Method),
+		zap.String("path", req.URL.Path),
+		zap.Int("status", status),
+		zap.Duration("wait", d))
+}
+
+// isSecondaryRateLimit returns true if the response body is a secondary rate limit error.
+// The body is restored for the next readers.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return err == nil && strings.Contains(strings.ToLower(string(body)), "secondary rate limit")
+}
+
+// sleepContext sleeps for the given duration, or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,203 @@
+//go:build unit
+// +build unit
+
+package github
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRateLimitTransport creates a rateLimitTransport recording its waits instead of sleeping.
+func newTestRateLimitTransport(now time.Time, waits *[]time.Duration) *rateLimitTransport {
+	t := newRateLimitTransport(http.DefaultTransport, 3, time.Hour)
+	t.now = func() time.Time { return now }
+	t.sleep = func(_ context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return nil
+	}
+	return t
+}
+
+// sequenceServer returns a server replying with the given handlers, in order, and the number of requests.
+func sequenceServer(t *testing.T, handlers ...http.HandlerFunc) (*httptest.Server, *int) {
+	count := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Less(t, count, len(handlers), "unexpected request")
+		count++
+		handlers[count-1](w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &count
+}
+
+func ok(w http.ResponseWriter, _ *http.Request) {
+	_, _ = w.Write([]byte("ok"))
+}
+
+func TestRateLimitTransport_RetryAfter(t *testing.T) {
+	srv, count := sequenceServer(t,
+		func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusForbidden)
+		},
+		func(w http.ResponseWriter, r *http.Request) {
+			// The body is sent again
+			body, _ := io.ReadAll(r.Body)
+			require.Equal(t, "payload", string(body))
+			ok(w, r)
+		},
+	)
+
+	var waits []time.Duration
+	client := &http.Client{Transport: newTestRateLimitTransport(time.Now(), &waits)}
+	resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("payload"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, 2, *count)
+	require.Equal(t, []time.Duration{30 * time.Second}, waits)
+}
+
+func TestRateLimitTransport_PrimaryRateLimit(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	reset := strconv.FormatInt(now.Add(2*time.Minute).Unix(), 10)
+	srv, count := sequenceServer(t,
+		func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", reset)
+			w.WriteHeader(http.StatusForbidden)
+		},
+		ok,
+	)
+
+	var waits []time.Duration
+	client := &http.Client{Transport: newTestRateLimitTransport(now, &waits)}
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, 2, *count)
+	require.Equal(t, []time.Duration{2*time.Minute + time.Second}, waits)
+}
+
+func TestRateLimitTransport_SecondaryRateLimitBackoff(t *testing.T) {
+	secondary := func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message": "You have exceeded a secondary rate limit."}`))
+	}
+	srv, count := sequenceServer(t, secondary, secondary, ok)
+
+	var waits []time.Duration
+	client := &http.Client{Transport: newTestRateLimitTransport(time.Now(), &waits)}
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, 3, *count)
+	require.Equal(t, []time.Duration{time.Minute, 2 * time.Minute}, waits)
+}
+
+func TestRateLimitTransport_NotRateLimited(t *testing.T) {
+	srv, count := sequenceServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+	})
+
+	var waits []time.Duration
+	client := &http.Client{Transport: newTestRateLimitTransport(time.Now(), &waits)}
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// The body is still readable after the secondary rate limit detection
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), "Resource not accessible")
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	require.Equal(t, 1, *count)
+	require.Empty(t, waits)
+}
+
+func TestRateLimitTransport_MaxRetries(t *testing.T) {
+	tooMany := func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}
+	srv, count := sequenceServer(t, tooMany, tooMany, tooMany, tooMany)
+
+	var waits []time.Duration
+	client := &http.Client{Transport: newTestRateLimitTransport(time.Now(), &waits)}
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Equal(t, 4, *count)
+	require.Len(t, waits, 3)
+}
+
+func TestRateLimitTransport_WaitTooLong(t *testing.T) {
+	srv, count := sequenceServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "7200")
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	var waits []time.Duration
+	client := &http.Client{Transport: newTestRateLimitTransport(time.Now(), &waits)}
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	require.Equal(t, 1, *count)
+	require.Empty(t, waits)
+}
+
+func TestRateLimitTransport_ExhaustedQuota(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	srv, _ := sequenceServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(time.Minute).Unix(), 10))
+		ok(w, r)
+	})
+
+	// The last allowed request succeeds, and waits for the reset before returning
+	var waits []time.Duration
+	client := &http.Client{Transport: newTestRateLimitTransport(now, &waits)}
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, []time.Duration{time.Minute + time.Second}, waits)
+}
+
+func TestRateLimitTransport_Canceled(t *testing.T) {
+	srv, count := sequenceServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	// The context expires while waiting for the rate limit
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	transport := newRateLimitTransport(http.DefaultTransport, 3, time.Hour)
+	_, err = transport.RoundTrip(req)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 1, *count)
+}
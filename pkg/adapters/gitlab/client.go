@@ -0,0 +1,417 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+)
+
+const (
+	// pageSize is the number of tags and tree entries fetched.
+	pageSize = 100
+	// mergeStatusRetries is the number of checks of the merge status while GitLab computes it.
+	mergeStatusRetries = 5
+)
+
+// Options contains the options of the GitLab client.
+type Options struct {
+	// BaseURL is the API URL (e.g. https://gitlab.example.com/api/v4/).
+	BaseURL string
+	// Token is a personal, group or project access token.
+	Token string
+}
+
+// client implements the forge client using the GitLab REST API.
+type client struct {
+	api        *forge.RESTClient
+	retryDelay time.Duration
+}
+
+// New creates a GitLab client with the given options.
+func New(opts Options) (forge.Client, error) {
+	api, err := forge.NewRESTClient(opts.BaseURL, http.Header{"Private-Token": {opts.Token}})
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitLab API: %w", err)
+	}
+	return &client{api: api, retryDelay: 2 * time.Second}, nil
+}
+
+// project returns the ID of a project from its owner and name: its URL-encoded path.
+func project(owner, repo string) string {
+	return url.PathEscape(owner + "/" + repo)
+}
+
+// projectFromURL returns the ID of a project from its repository URL.
+func projectFromURL(repoURL string) (string, error) {
+	host, owner, repo := adapters.ParseRepository(repoURL)
+	if host == "" {
+		return "", fmt.Errorf("invalid repository URL format: %s", repoURL)
+	}
+	return project(owner, repo), nil
+}
+
+// mergeRequest is a GitLab merge request.
+type mergeRequest struct {
+	IID          int    `json:"iid"`
+	State        string `json:"state"`
+	HasConflicts bool   `json:"has_conflicts"`
+	MergeStatus  string `json:"merge_status"`
+	HeadPipeline *struct {
+		Status string `json:"status"`
+	} `json:"head_pipeline"`
+}
+
+// getMergeRequest retrieves a merge request.
+func (c *client) getMergeRequest(ctx context.Context, repoURL string, iid int) (*mergeRequest, error) {
+	id, err := projectFromURL(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var mr mergeRequest
+	path := fmt.Sprintf("projects/%s/merge_requests/%d", id, iid)
+	if err := c.api.Do(ctx, http.MethodGet, path, nil, nil, &mr); err != nil {
+		return nil, fmt.Errorf("failed to get merge request %d: %w", iid, err)
+	}
+	return &mr, nil
+}
+
+// GetFileContent retrieves the raw content of a file from a GitLab project.
+func (c *client) GetFileContent(ctx context.Context, params forge.GetFileContentParams) ([]byte, error) {
+	path := fmt.Sprintf("projects/%s/repository/files/%s/raw",
+		project(params.Owner, params.Repo), url.PathEscape(params.Path))
+	content, err := c.api.DoRaw(ctx, http.MethodGet, path, url.Values{"ref": {params.Ref}}, nil)
+	if err != nil {
+		if forge.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", forge.ErrFileNotFound, params.Path)
+		}
+		return nil, err
+	}
+	return content, nil
+}
+
+// ListDirectory lists the paths of the files in a directory of a GitLab project.
+// It returns an empty list if the directory does not exist.
+func (c *client) ListDirectory(ctx context.Context, params forge.ListDirectoryParams) ([]string, error) {
+	var entries []struct {
+		Type string `json:"type"`
+		Path string `json:"path"`
+	}
+	query := url.Values{"path": {params.Path}, "ref": {params.Ref}, "per_page": {fmt.Sprint(pageSize)}}
+	path := fmt.Sprintf("projects/%s/repository/tree", project(params.Owner, params.Repo))
+	if err := c.api.Do(ctx, http.MethodGet, path, query, nil, &entries); err != nil {
+		if forge.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type == "blob" {
+			paths = append(paths, entry.Path)
+		}
+	}
+	return paths, nil
+}
+
+// ListTags retrieves the most recently updated tags of a GitLab project.
+func (c *client) ListTags(ctx context.Context, params forge.ListTagsParams) ([]forge.Tag, error) {
+	var tags []struct {
+		Name   string `json:"name"`
+		Commit struct {
+			ID string `json:"id"`
+		} `json:"commit"`
+	}
+	query := url.Values{"per_page": {fmt.Sprint(pageSize)}}
+	path := fmt.Sprintf("projects/%s/repository/tags", project(params.Owner, params.Repo))
+	if err := c.api.Do(ctx, http.MethodGet, path, query, nil, &tags); err != nil {
+		return nil, err
+	}
+
+	result := make([]forge.Tag, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, forge.Tag{Name: tag.Name, CommitSHA: tag.Commit.ID})
+	}
+	return result, nil
+}
+
+// CreateMergeRequest creates a merge request in the specified project.
+func (c *client) CreateMergeRequest(ctx context.Context, params forge.CreateMergeRequestParams) (int, error) {
+	id, err := projectFromURL(params.RepoURL)
+	if err != nil {
+		return -1, err
+	}
+
+	// Generate MR title and description, unless provided
+	title := params.Title
+	if title == "" {
+		title = adapters.FormatCommitMessage(params.ModulePath, params.TargetVersion)
+	}
+	description := params.Description
+	if description == "" {
+		description = adapters.FormatMergeRequestDescription(params.ModulePath, params.TargetVersion)
+	}
+
+	var mr mergeRequest
+	err = c.api.Do(ctx, http.MethodPost, fmt.Sprintf("projects/%s/merge_requests", id), nil, map[string]any{
+		"source_branch":        params.SourceBranch,
+		"target_branch":        adapters.DefaultBranch,
+		"title":                title,
+		"description":          description,
+		"remove_source_branch": true,
+	}, &mr)
+	if err != nil {
+		return -1, err
+	}
+	return mr.IID, nil
+}
+
+// CheckPullRequestExists checks if an open merge request already exists for the given branch.
+// Returns the merge request IID if it exists, or -1 if it doesn't exist.
+func (c *client) CheckPullRequestExists(ctx context.Context, params forge.CheckPullRequestExistsParams) (int, error) {
+	id, err := projectFromURL(params.RepoURL)
+	if err != nil {
+		return -1, err
+	}
+
+	var mrs []mergeRequest
+	query := url.Values{"state": {"opened"}, "source_branch": {params.SourceBranch}}
+	path := fmt.Sprintf("projects/%s/merge_requests", id)
+	if err := c.api.Do(ctx, http.MethodGet, path, query, nil, &mrs); err != nil {
+		return -1, err
+	}
+	if len(mrs) > 0 {
+		return mrs[0].IID, nil
+	}
+	return -1, nil
+}
+
+// getBranch retrieves the SHA of the head of a branch.
+func (c *client) getBranch(ctx context.Context, repoURL, branch string) (string, error) {
+	id, err := projectFromURL(repoURL)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Commit struct {
+			ID string `json:"id"`
+		} `json:"commit"`
+	}
+	path := fmt.Sprintf("projects/%s/repository/branches/%s", id, url.PathEscape(branch))
+	if err := c.api.Do(ctx, http.MethodGet, path, nil, nil, &result); err != nil {
+		return "", err
+	}
+	return result.Commit.ID, nil
+}
+
+// BranchExists checks if a branch exists in a GitLab project.
+func (c *client) BranchExists(ctx context.Context, params forge.BranchExistsParams) (bool, error) {
+	if _, err := c.getBranch(ctx, params.RepoURL, params.BranchName); err != nil {
+		if forge.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get branch %s: %w", params.BranchName, err)
+	}
+	return true, nil
+}
+
+// GetBranchSHA returns the SHA of the commit at the head of a branch.
+func (c *client) GetBranchSHA(ctx context.Context, params forge.GetBranchSHAParams) (string, error) {
+	sha, err := c.getBranch(ctx, params.RepoURL, params.BranchName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get branch %s: %w", params.BranchName, err)
+	}
+	return sha, nil
+}
+
+// CreateCommit creates a commit writing the files on a new branch, started from the base
+// commit, with the commits API. It returns the SHA of the commit.
+func (c *client) CreateCommit(ctx context.Context, params forge.CreateCommitParams) (string, error) {
+	host, owner, repo := adapters.ParseRepository(params.RepoURL)
+	if host == "" {
+		return "", fmt.Errorf("invalid repository URL format: %s", params.RepoURL)
+	}
+
+	// The existing files are updated, and the others created
+	actions := make([]map[string]string, 0, len(params.Files))
+	for _, f := range params.Files {
+		action := "update"
+		_, err := c.GetFileContent(ctx, forge.GetFileContentParams{
+			Owner: owner, Repo: repo, Path: f.Path, Ref: params.BaseSHA,
+		})
+		if errors.Is(err, forge.ErrFileNotFound) {
+			action = "create"
+		} else if err != nil {
+			return "", fmt.Errorf("failed to get file %s: %w", f.Path, err)
+		}
+		actions = append(actions, map[string]string{
+			"action":    action,
+			"file_path": f.Path,
+			"content":   base64.StdEncoding.EncodeToString(f.Content),
+			"encoding":  "base64",
+		})
+	}
+
+	var commit struct {
+		ID string `json:"id"`
+	}
+	err := c.api.Do(ctx, http.MethodPost, fmt.Sprintf("projects/%s/repository/commits", project(owner, repo)), nil,
+		map[string]any{
+			"branch":         params.BranchName,
+			"start_sha":      params.BaseSHA,
+			"commit_message": params.Message,
+			"author_name":    params.AuthorName,
+			"author_email":   params.AuthorEmail,
+			"actions":        actions,
+		}, &commit)
+	if err != nil {
+		return "", fmt.Errorf("failed to create commit: %w", err)
+	}
+	return commit.ID, nil
+}
+
+// GetPullRequestState returns the state of a merge request: open, closed (without merge) or merged.
+func (c *client) GetPullRequestState(ctx context.Context, params forge.GetPullRequestStateParams) (string, error) {
+	mr, err := c.getMergeRequest(ctx, params.RepoURL, params.PRNumber)
+	if err != nil {
+		return "", err
+	}
+
+	switch mr.State {
+	case "merged":
+		return forge.PullRequestStateMerged, nil
+	case "closed":
+		return forge.PullRequestStateClosed, nil
+	default:
+		return forge.PullRequestStateOpen, nil
+	}
+}
+
+// GetPullRequestChecks gets the status of the head pipeline of a merge request.
+func (c *client) GetPullRequestChecks(ctx context.Context,
+	params forge.GetPullRequestChecksParams) (*forge.CheckStatus, error) {
+	mr, err := c.getMergeRequest(ctx, params.RepoURL, params.PRNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	// No pipeline yet, consider as running
+	if mr.HeadPipeline == nil {
+		return &forge.CheckStatus{Status: "running"}, nil
+	}
+	switch mr.HeadPipeline.Status {
+	case "success", "skipped":
+		return &forge.CheckStatus{Status: "passed"}, nil
+	case "failed", "canceled":
+		return &forge.CheckStatus{Status: "failed"}, nil
+	default:
+		return &forge.CheckStatus{Status: "running"}, nil
+	}
+}
+
+// MergeMergeRequest merges a merge request, squashing its commits.
+func (c *client) MergeMergeRequest(ctx context.Context, params forge.MergeMergeRequestParams) error {
+	id, err := projectFromURL(params.RepoURL)
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("projects/%s/merge_requests/%d/merge", id, params.PRNumber)
+	if err := c.api.Do(ctx, http.MethodPut, path, nil, map[string]any{"squash": true}, nil); err != nil {
+		return fmt.Errorf("failed to merge merge request: %w", err)
+	}
+	return nil
+}
+
+// DeleteBranch deletes a branch from a GitLab project.
+func (c *client) DeleteBranch(ctx context.Context, params forge.DeleteBranchParams) error {
+	id, err := projectFromURL(params.RepoURL)
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("projects/%s/repository/branches/%s", id, url.PathEscape(params.BranchName))
+	if err := c.api.Do(ctx, http.MethodDelete, path, nil, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete branch %s: %w", params.BranchName, err)
+	}
+	return nil
+}
+
+// DeletePullRequest closes a merge request in a GitLab project.
+func (c *client) DeletePullRequest(ctx context.Context, params forge.DeletePullRequestParams) error {
+	id, err := projectFromURL(params.RepoURL)
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("projects/%s/merge_requests/%d", id, params.PRNumber)
+	if err := c.api.Do(ctx, http.MethodPut, path, nil, map[string]any{"state_event": "close"}, nil); err != nil {
+		return fmt.Errorf("failed to close merge request %d: %w", params.PRNumber, err)
+	}
+	return nil
+}
+
+// CheckMergeConflicts checks if a merge request has conflicts. GitLab computes the merge
+// status asynchronously, so it is checked again while it is being computed.
+func (c *client) CheckMergeConflicts(ctx context.Context, params forge.CheckMergeConflictsParams) (bool, error) {
+	for attempt := 0; ; attempt++ {
+		mr, err := c.getMergeRequest(ctx, params.RepoURL, params.PRNumber)
+		if err != nil {
+			return false, err
+		}
+
+		computing := mr.MergeStatus == "unchecked" || mr.MergeStatus == "checking"
+		if !computing || attempt == mergeStatusRetries-1 {
+			return mr.HasConflicts, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(c.retryDelay):
+		}
+	}
+}
+
+// DiscoverRepositories fetches the files and tags of the projects with the REST API.
+// Missing files are absent from the results.
+func (c *client) DiscoverRepositories(ctx context.Context, params forge.DiscoverRepositoriesParams) (
+	[]forge.DiscoveredRepository, error) {
+	results := make([]forge.DiscoveredRepository, 0, len(params.RepoURLs))
+	for _, repoURL := range params.RepoURLs {
+		host, owner, repo := adapters.ParseRepository(repoURL)
+		if host == "" {
+			return nil, fmt.Errorf("invalid repository URL format: %s", repoURL)
+		}
+
+		result := forge.DiscoveredRepository{RepoURL: repoURL, Files: make(map[string][]byte)}
+		for _, path := range params.Paths {
+			content, err := c.GetFileContent(ctx, forge.GetFileContentParams{
+				Host: host, Owner: owner, Repo: repo, Path: path, Ref: params.Ref,
+			})
+			if errors.Is(err, forge.ErrFileNotFound) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			result.Files[path] = content
+		}
+
+		tags, err := c.ListTags(ctx, forge.ListTagsParams{Host: host, Owner: owner, Repo: repo})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags of %s: %w", repoURL, err)
+		}
+		result.Tags = tags
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,286 @@
+//go:build unit
+// +build unit
+
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/stretchr/testify/require"
+)
+
+const testRepoURL = "https://gitlab.example.com/group/project.git"
+
+// newTestClient creates a client of a GitLab stand-in serving the given handlers, by method and escaped path.
+func newTestClient(t *testing.T, handlers map[string]http.HandlerFunc) *client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "token", r.Header.Get("PRIVATE-TOKEN"))
+		handler, ok := handlers[r.Method+" "+r.URL.EscapedPath()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "404 Not Found"}`))
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := New(Options{BaseURL: srv.URL + "/api/v4", Token: "token"})
+	require.NoError(t, err)
+	c.(*client).retryDelay = time.Millisecond
+	return c.(*client)
+}
+
+// reply returns a handler replying the value as JSON.
+func reply(t *testing.T, v any) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		require.NoError(t, json.NewEncoder(w).Encode(v))
+	}
+}
+
+func TestGetFileContent(t *testing.T) {
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"GET /api/v4/projects/group%2Fproject/repository/files/go.mod/raw": func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "main", r.URL.Query().Get("ref"))
+			_, _ = w.Write([]byte("module gitlab.example.com/group/project\n"))
+		},
+	})
+
+	content, err := c.GetFileContent(context.Background(), forge.GetFileContentParams{
+		Owner: "group", Repo: "project", Path: "go.mod", Ref: "main",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "module gitlab.example.com/group/project\n", string(content))
+
+	_, err = c.GetFileContent(context.Background(), forge.GetFileContentParams{
+		Owner: "group", Repo: "project", Path: "tools/tools.go", Ref: "main",
+	})
+	require.ErrorIs(t, err, forge.ErrFileNotFound)
+}
+
+func TestListTagsAndDirectory(t *testing.T) {
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"GET /api/v4/projects/group%2Fproject/repository/tags": reply(t, []map[string]any{
+			{"name": "v1.1.0", "commit": map[string]string{"id": "abc"}},
+		}),
+		"GET /api/v4/projects/group%2Fproject/repository/tree": reply(t, []map[string]string{
+			{"type": "blob", "path": ".github/workflows/ci.yml"},
+			{"type": "tree", "path": ".github/workflows/sub"},
+		}),
+	})
+
+	tags, err := c.ListTags(context.Background(), forge.ListTagsParams{Owner: "group", Repo: "project"})
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+	require.Equal(t, "v1.1.0", tags[0].Name)
+	require.Equal(t, "abc", tags[0].CommitSHA)
+
+	paths, err := c.ListDirectory(context.Background(), forge.ListDirectoryParams{
+		Owner: "group", Repo: "project", Path: ".github/workflows", Ref: "main",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{".github/workflows/ci.yml"}, paths)
+}
+
+func TestMergeRequests(t *testing.T) {
+	var closed, merged bool
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"POST /api/v4/projects/group%2Fproject/merge_requests": func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "depsync/update", body["source_branch"])
+			require.Equal(t, "main", body["target_branch"])
+			require.Equal(t, "chores(depsync): update example.com/dep to v1.1.0", body["title"])
+			reply(t, map[string]any{"iid": 3})(w, r)
+		},
+		"GET /api/v4/projects/group%2Fproject/merge_requests": func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "opened", r.URL.Query().Get("state"))
+			require.Equal(t, "depsync/update", r.URL.Query().Get("source_branch"))
+			reply(t, []map[string]any{{"iid": 3}})(w, r)
+		},
+		"GET /api/v4/projects/group%2Fproject/merge_requests/3": reply(t, map[string]any{
+			"iid": 3, "state": "opened", "has_conflicts": true, "merge_status": "cannot_be_merged",
+			"head_pipeline": map[string]string{"status": "success"},
+		}),
+		"PUT /api/v4/projects/group%2Fproject/merge_requests/3/merge": func(w http.ResponseWriter, r *http.Request) {
+			merged = true
+			reply(t, map[string]any{"iid": 3})(w, r)
+		},
+		"PUT /api/v4/projects/group%2Fproject/merge_requests/3": func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "close", body["state_event"])
+			closed = true
+			reply(t, map[string]any{"iid": 3})(w, r)
+		},
+	})
+	ctx := context.Background()
+
+	number, err := c.CreateMergeRequest(ctx, forge.CreateMergeRequestParams{
+		RepoURL: testRepoURL, SourceBranch: "depsync/update", ModulePath: "example.com/dep", TargetVersion: "v1.1.0",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, number)
+
+	number, err = c.CheckPullRequestExists(ctx, forge.CheckPullRequestExistsParams{
+		RepoURL: testRepoURL, SourceBranch: "depsync/update",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, number)
+
+	state, err := c.GetPullRequestState(ctx, forge.GetPullRequestStateParams{RepoURL: testRepoURL, PRNumber: 3})
+	require.NoError(t, err)
+	require.Equal(t, forge.PullRequestStateOpen, state)
+
+	status, err := c.GetPullRequestChecks(ctx, forge.GetPullRequestChecksParams{RepoURL: testRepoURL, PRNumber: 3})
+	require.NoError(t, err)
+	require.Equal(t, "passed", status.Status)
+
+	conflicts, err := c.CheckMergeConflicts(ctx, forge.CheckMergeConflictsParams{RepoURL: testRepoURL, PRNumber: 3})
+	require.NoError(t, err)
+	require.True(t, conflicts)
+
+	require.NoError(t, c.MergeMergeRequest(ctx, forge.MergeMergeRequestParams{RepoURL: testRepoURL, PRNumber: 3}))
+	require.True(t, merged)
+	require.NoError(t, c.DeletePullRequest(ctx, forge.DeletePullRequestParams{RepoURL: testRepoURL, PRNumber: 3}))
+	require.True(t, closed)
+}
+
+func TestCheckMergeConflicts_Checking(t *testing.T) {
+	var calls int
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"GET /api/v4/projects/group%2Fproject/merge_requests/3": func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			status := "checking"
+			if calls == 2 {
+				status = "can_be_merged"
+			}
+			reply(t, map[string]any{"iid": 3, "merge_status": status})(w, r)
+		},
+	})
+
+	conflicts, err := c.CheckMergeConflicts(context.Background(), forge.CheckMergeConflictsParams{
+		RepoURL: testRepoURL, PRNumber: 3,
+	})
+	require.NoError(t, err)
+	require.False(t, conflicts)
+	require.Equal(t, 2, calls)
+}
+
+func TestBranches(t *testing.T) {
+	var deleted bool
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"GET /api/v4/projects/group%2Fproject/repository/branches/main": reply(t, map[string]any{
+			"commit": map[string]string{"id": "abc"},
+		}),
+		"DELETE /api/v4/projects/group%2Fproject/repository/branches/depsync%2Fupdate": func(
+			w http.ResponseWriter, _ *http.Request) {
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		},
+	})
+	ctx := context.Background()
+
+	sha, err := c.GetBranchSHA(ctx, forge.GetBranchSHAParams{RepoURL: testRepoURL, BranchName: "main"})
+	require.NoError(t, err)
+	require.Equal(t, "abc", sha)
+
+	exists, err := c.BranchExists(ctx, forge.BranchExistsParams{RepoURL: testRepoURL, BranchName: "missing"})
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	require.NoError(t, c.DeleteBranch(ctx, forge.DeleteBranchParams{RepoURL: testRepoURL, BranchName: "depsync/update"}))
+	require.True(t, deleted)
+}
+
+func TestSubgroupProject(t *testing.T) {
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"GET /api/v4/projects/group%2Fsubgroup%2Fproject/repository/branches/main": reply(t, map[string]any{
+			"commit": map[string]string{"id": "abc"},
+		}),
+		"GET /api/v4/projects/group%2Fsubgroup%2Fproject/repository/files/go.mod/raw": func(
+			w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("module gitlab.example.com/group/subgroup/project\n"))
+		},
+		"GET /api/v4/projects/group%2Fsubgroup%2Fproject/repository/tags": reply(t, []map[string]any{{"name": "v1.0.0"}}),
+	})
+	ctx := context.Background()
+	repoURL := "https://gitlab.example.com/group/subgroup/project.git"
+
+	sha, err := c.GetBranchSHA(ctx, forge.GetBranchSHAParams{RepoURL: repoURL, BranchName: "main"})
+	require.NoError(t, err)
+	require.Equal(t, "abc", sha)
+
+	results, err := c.DiscoverRepositories(ctx, forge.DiscoverRepositoriesParams{
+		RepoURLs: []string{repoURL},
+		Ref:      "main",
+		Paths:    []string{"go.mod"},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "module gitlab.example.com/group/subgroup/project\n", string(results[0].Files["go.mod"]))
+	require.Len(t, results[0].Tags, 1)
+}
+
+func TestDiscoverRepositories(t *testing.T) {
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"GET /api/v4/projects/group%2Fproject/repository/files/go.mod/raw": func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("module gitlab.example.com/group/project\n"))
+		},
+		"GET /api/v4/projects/group%2Fproject/repository/tags": reply(t, []map[string]any{{"name": "v1.0.0"}}),
+	})
+
+	results, err := c.DiscoverRepositories(context.Background(), forge.DiscoverRepositoriesParams{
+		RepoURLs: []string{testRepoURL},
+		Ref:      "main",
+		Paths:    []string{"go.mod", "tools.go"},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, map[string][]byte{"go.mod": []byte("module gitlab.example.com/group/project\n")}, results[0].Files)
+	require.Len(t, results[0].Tags, 1)
+}
+
+func TestCreateCommit(t *testing.T) {
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"GET /api/v4/projects/group%2Fproject/repository/files/go.mod/raw": func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "base", r.URL.Query().Get("ref"))
+			_, _ = w.Write([]byte("module gitlab.example.com/group/project\n"))
+		},
+		"POST /api/v4/projects/group%2Fproject/repository/commits": func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, map[string]any{
+				"branch":         "depsync/update",
+				"start_sha":      "base",
+				"commit_message": "chores(depsync): update",
+				"author_name":    "depsync",
+				"author_email":   "depsync@example.com",
+				"actions": []any{
+					map[string]any{"action": "update", "file_path": "go.mod", "content": "Z28gMS4yNAo=", "encoding": "base64"},
+					map[string]any{"action": "create", "file_path": "go.sum", "content": "", "encoding": "base64"},
+				},
+			}, body)
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]string{"id": "abc"}))
+		},
+	})
+
+	sha, err := c.CreateCommit(context.Background(), forge.CreateCommitParams{
+		RepoURL:     testRepoURL,
+		BaseBranch:  "main",
+		BaseSHA:     "base",
+		BranchName:  "depsync/update",
+		Message:     "chores(depsync): update",
+		Files:       []forge.CommitFile{{Path: "go.mod", Content: []byte("go 1.24\n")}, {Path: "go.sum"}},
+		AuthorName:  "depsync",
+		AuthorEmail: "depsync@example.com",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "abc", sha)
+}
@@ -0,0 +1,542 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+)
+
+const (
+	// PullRequestsFile is the file of the pull requests, in the root directory.
+	PullRequestsFile = "pull_requests.json"
+	// maxTags is the number of tags listed, the most recent first.
+	maxTags = 100
+	// committerName and committerEmail are the identity of the merge commits.
+	committerName  = "Local Forge"
+	committerEmail = "forge@localhost"
+)
+
+// Options contains the options of the local forge.
+type Options struct {
+	// Root is the directory of the bare repositories, as <root>/<owner>/<repo>.git.
+	Root string
+	// Checks is the status of the checks of the new pull requests: running, passed
+	// or failed (default: passed). It can be changed for each pull request in the
+	// pull requests file.
+	Checks string
+}
+
+// PullRequest is a pull request simulated by the local forge.
+type PullRequest struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	Head   string `json:"head"`
+	Base   string `json:"base"`
+	// State is the state of the pull request: open, closed or merged.
+	State string `json:"state"`
+	// Checks is the status of the checks of the pull request: running, passed or failed.
+	Checks string `json:"checks"`
+}
+
+// client implements the forge client with bare git repositories on the local
+// filesystem, and pull requests stored in a JSON file.
+type client struct {
+	root   string
+	checks string
+	// mu protects the pull requests file.
+	mu sync.Mutex
+}
+
+// New creates a local forge with the given options.
+func New(opts Options) (forge.Client, error) {
+	if opts.Root == "" {
+		return nil, errors.New("local forge without root directory")
+	}
+	root, err := filepath.Abs(opts.Root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root directory: %w", err)
+	}
+
+	checks := opts.Checks
+	if checks == "" {
+		checks = "passed"
+	}
+	return &client{root: root, checks: checks}, nil
+}
+
+// repoDir returns the directory of a bare repository from its owner and name.
+func (c *client) repoDir(owner, repo string) string {
+	return filepath.Join(c.root, owner, repo+".git")
+}
+
+// repoDirFromURL returns the directory of a bare repository from its URL.
+func (c *client) repoDirFromURL(repoURL string) (string, error) {
+	host, owner, repo := adapters.ParseRepository(repoURL)
+	if host != adapters.LocalHost {
+		return "", fmt.Errorf("invalid local repository URL: %s", repoURL)
+	}
+	return c.repoDir(owner, repo), nil
+}
+
+// git runs a git command in a bare repository and returns its standard output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	return gitEnv(ctx, dir, nil, args...)
+}
+
+// gitEnv runs a git command in a bare repository, with the additional environment
+// (e.g. the author), and returns its standard output.
+func gitEnv(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", append([]string{"--git-dir", dir}, args...)...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	cmd.Env = append(append(os.Environ(),
+		"GIT_AUTHOR_NAME="+committerName, "GIT_AUTHOR_EMAIL="+committerEmail,
+		"GIT_COMMITTER_NAME="+committerName, "GIT_COMMITTER_EMAIL="+committerEmail), env...)
+	if err := cmd.Run(); err != nil {
+		return stdout.String(), fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// revision returns the SHA of a revision, or an empty string if it does not exist.
+func revision(ctx context.Context, dir, rev string) (string, error) {
+	out, err := git(ctx, dir, "rev-parse", "--verify", "--quiet", rev)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// GetFileContent retrieves the content of a file from a local repository.
+func (c *client) GetFileContent(ctx context.Context, params forge.GetFileContentParams) ([]byte, error) {
+	dir := c.repoDir(params.Owner, params.Repo)
+	object := params.Ref + ":" + params.Path
+	sha, err := revision(ctx, dir, object)
+	if err != nil {
+		return nil, err
+	} else if sha == "" {
+		return nil, fmt.Errorf("%w: %s", forge.ErrFileNotFound, params.Path)
+	}
+
+	content, err := git(ctx, dir, "cat-file", "blob", sha)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", params.Path, err)
+	}
+	return []byte(content), nil
+}
+
+// ListDirectory lists the paths of the files in a directory of a local repository.
+// It returns an empty list if the directory does not exist.
+func (c *client) ListDirectory(ctx context.Context, params forge.ListDirectoryParams) ([]string, error) {
+	dir := c.repoDir(params.Owner, params.Repo)
+	if sha, err := revision(ctx, dir, params.Ref+":"+params.Path); err != nil || sha == "" {
+		return nil, err
+	}
+
+	out, err := git(ctx, dir, "ls-tree", params.Ref+":"+params.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", params.Path, err)
+	}
+
+	// Each line is "<mode> <type> <sha>\t<name>"
+	var paths []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		info, name, ok := strings.Cut(line, "\t")
+		if ok && strings.Fields(info)[1] == "blob" {
+			paths = append(paths, strings.TrimSuffix(params.Path, "/")+"/"+name)
+		}
+	}
+	return paths, nil
+}
+
+// ListTags retrieves the most recent tags of a local repository, with the SHA of their commit.
+func (c *client) ListTags(ctx context.Context, params forge.ListTagsParams) ([]forge.Tag, error) {
+	out, err := git(ctx, c.repoDir(params.Owner, params.Repo), "for-each-ref",
+		"--sort=-creatordate", fmt.Sprintf("--count=%d", maxTags),
+		"--format=%(refname:strip=2) %(objectname) %(*objectname)", "refs/tags")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	var tags []forge.Tag
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		// The annotated tags have the SHA of their commit in third position
+		sha := fields[len(fields)-1]
+		tags = append(tags, forge.Tag{Name: fields[0], CommitSHA: sha})
+	}
+	return tags, nil
+}
+
+// loadPullRequests reads the pull requests of all repositories, by repository directory
+// relative to the root. The caller must hold the lock.
+func (c *client) loadPullRequests() (map[string][]*PullRequest, error) {
+	prs := make(map[string][]*PullRequest)
+	data, err := os.ReadFile(filepath.Join(c.root, PullRequestsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return prs, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read pull requests: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &prs); err != nil {
+		return nil, fmt.Errorf("failed to decode pull requests: %w", err)
+	}
+	return prs, nil
+}
+
+// savePullRequests writes the pull requests of all repositories. The caller must hold the lock.
+func (c *client) savePullRequests(prs map[string][]*PullRequest) error {
+	data, err := json.MarshalIndent(prs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode pull requests: %w", err)
+	}
+
+	// Write to a temporary file and rename it, to never leave a partial file
+	path := filepath.Join(c.root, PullRequestsFile)
+	if err := os.WriteFile(path+".tmp", data, 0o600); err != nil {
+		return fmt.Errorf("failed to write pull requests: %w", err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// updatePullRequest applies fn to a pull request of a repository, and saves the
+// pull requests if fn succeeded and changed it.
+func (c *client) updatePullRequest(repoURL string, number int, fn func(dir string, pr *PullRequest) error) error {
+	dir, err := c.repoDirFromURL(repoURL)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prs, err := c.loadPullRequests()
+	if err != nil {
+		return err
+	}
+
+	key, _ := filepath.Rel(c.root, dir)
+	for _, pr := range prs[key] {
+		if pr.Number == number {
+			before := *pr
+			if err := fn(dir, pr); err != nil || *pr == before {
+				return err
+			}
+			return c.savePullRequests(prs)
+		}
+	}
+	return fmt.Errorf("pull request %d not found in %s", number, key)
+}
+
+// getPullRequest returns a copy of a pull request of a repository.
+func (c *client) getPullRequest(repoURL string, number int) (string, PullRequest, error) {
+	var result PullRequest
+	var repoDir string
+	err := c.updatePullRequest(repoURL, number, func(dir string, pr *PullRequest) error {
+		repoDir, result = dir, *pr
+		return nil
+	})
+	return repoDir, result, err
+}
+
+// CreateMergeRequest creates a pull request of a branch of a local repository.
+func (c *client) CreateMergeRequest(ctx context.Context, params forge.CreateMergeRequestParams) (int, error) {
+	dir, err := c.repoDirFromURL(params.RepoURL)
+	if err != nil {
+		return -1, err
+	}
+	if sha, err := revision(ctx, dir, "refs/heads/"+params.SourceBranch); err != nil {
+		return -1, err
+	} else if sha == "" {
+		return -1, fmt.Errorf("branch %s not found", params.SourceBranch)
+	}
+
+	// Generate PR title and description, unless provided
+	title := params.Title
+	if title == "" {
+		title = adapters.FormatCommitMessage(params.ModulePath, params.TargetVersion)
+	}
+	description := params.Description
+	if description == "" {
+		description = adapters.FormatMergeRequestDescription(params.ModulePath, params.TargetVersion)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prs, err := c.loadPullRequests()
+	if err != nil {
+		return -1, err
+	}
+
+	key, _ := filepath.Rel(c.root, dir)
+	pr := &PullRequest{
+		Number: len(prs[key]) + 1,
+		Title:  title,
+		Body:   description,
+		Head:   params.SourceBranch,
+		Base:   adapters.DefaultBranch,
+		State:  forge.PullRequestStateOpen,
+		Checks: c.checks,
+	}
+	prs[key] = append(prs[key], pr)
+	if err := c.savePullRequests(prs); err != nil {
+		return -1, err
+	}
+	return pr.Number, nil
+}
+
+// CheckPullRequestExists checks if an open pull request already exists for the given branch.
+// Returns the PR number if it exists, or -1 if it doesn't exist.
+func (c *client) CheckPullRequestExists(_ context.Context, params forge.CheckPullRequestExistsParams) (int, error) {
+	dir, err := c.repoDirFromURL(params.RepoURL)
+	if err != nil {
+		return -1, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prs, err := c.loadPullRequests()
+	if err != nil {
+		return -1, err
+	}
+
+	key, _ := filepath.Rel(c.root, dir)
+	for _, pr := range prs[key] {
+		if pr.Head == params.SourceBranch && pr.State == forge.PullRequestStateOpen {
+			return pr.Number, nil
+		}
+	}
+	return -1, nil
+}
+
+// BranchExists checks if a branch exists in a local repository.
+func (c *client) BranchExists(ctx context.Context, params forge.BranchExistsParams) (bool, error) {
+	sha, err := c.GetBranchSHA(ctx, forge.GetBranchSHAParams(params))
+	if err != nil {
+		return false, err
+	}
+	return sha != "", nil
+}
+
+// GetBranchSHA returns the SHA of the commit at the head of a branch, or an empty
+// string if the branch does not exist.
+func (c *client) GetBranchSHA(ctx context.Context, params forge.GetBranchSHAParams) (string, error) {
+	dir, err := c.repoDirFromURL(params.RepoURL)
+	if err != nil {
+		return "", err
+	}
+
+	sha, err := revision(ctx, dir, "refs/heads/"+params.BranchName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get branch %s: %w", params.BranchName, err)
+	}
+	return sha, nil
+}
+
+// CreateCommit creates a commit writing the files on a new branch, whose parent is the base
+// commit, without working copy. It returns the SHA of the commit.
+func (c *client) CreateCommit(ctx context.Context, params forge.CreateCommitParams) (string, error) {
+	dir, err := c.repoDirFromURL(params.RepoURL)
+	if err != nil {
+		return "", err
+	}
+	base, err := revision(ctx, dir, params.BaseSHA+"^{commit}")
+	if err != nil {
+		return "", err
+	} else if base == "" {
+		return "", fmt.Errorf("commit %s not found", params.BaseSHA)
+	}
+
+	// Build the tree in a temporary index, from the tree of the base
+	tmp, err := os.MkdirTemp("", "depsync-local-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create index: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(tmp, "index")}
+	if _, err := gitEnv(ctx, dir, env, "read-tree", base); err != nil {
+		return "", fmt.Errorf("failed to read tree: %w", err)
+	}
+	for i, f := range params.Files {
+		path := filepath.Join(tmp, fmt.Sprintf("file-%d", i))
+		if err := os.WriteFile(path, f.Content, 0o600); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", f.Path, err)
+		}
+		blob, err := git(ctx, dir, "hash-object", "-w", path)
+		if err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", f.Path, err)
+		}
+		cacheInfo := fmt.Sprintf("100644,%s,%s", strings.TrimSpace(blob), f.Path)
+		if _, err := gitEnv(ctx, dir, env, "update-index", "--add", "--cacheinfo", cacheInfo); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", f.Path, err)
+		}
+	}
+	tree, err := gitEnv(ctx, dir, env, "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("failed to write tree: %w", err)
+	}
+
+	env = append(env, "GIT_AUTHOR_NAME="+params.AuthorName, "GIT_AUTHOR_EMAIL="+params.AuthorEmail)
+	commit, err := gitEnv(ctx, dir, env, "commit-tree", strings.TrimSpace(tree), "-p", base, "-m", params.Message)
+	if err != nil {
+		return "", fmt.Errorf("failed to create commit: %w", err)
+	}
+	commit = strings.TrimSpace(commit)
+	// The empty old value checks that the branch does not exist
+	if _, err := git(ctx, dir, "update-ref", "refs/heads/"+params.BranchName, commit, ""); err != nil {
+		return "", fmt.Errorf("failed to create branch %s: %w", params.BranchName, err)
+	}
+	return commit, nil
+}
+
+// GetPullRequestState returns the state of a pull request: open, closed (without merge) or merged.
+func (c *client) GetPullRequestState(_ context.Context, params forge.GetPullRequestStateParams) (string, error) {
+	_, pr, err := c.getPullRequest(params.RepoURL, params.PRNumber)
+	if err != nil {
+		return "", err
+	}
+	return pr.State, nil
+}
+
+// GetPullRequestChecks returns the simulated status of the checks of a pull request.
+func (c *client) GetPullRequestChecks(_ context.Context,
+	params forge.GetPullRequestChecksParams) (*forge.CheckStatus, error) {
+	_, pr, err := c.getPullRequest(params.RepoURL, params.PRNumber)
+	if err != nil {
+		return nil, err
+	}
+	return &forge.CheckStatus{Status: pr.Checks}, nil
+}
+
+// mergeTree merges the head of a pull request into its base, and returns the SHA
+// of the merged tree, or an empty string if there are conflicts.
+func mergeTree(ctx context.Context, dir string, pr PullRequest) (string, error) {
+	out, err := git(ctx, dir, "merge-tree", "--write-tree",
+		"refs/heads/"+pr.Base, "refs/heads/"+pr.Head)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to merge %s into %s: %w", pr.Head, pr.Base, err)
+	}
+	return strings.TrimSpace(strings.SplitN(out, "\n", 2)[0]), nil
+}
+
+// MergeMergeRequest merges a pull request, squashing its commits in a commit on its
+// base branch, with the title of the pull request as message.
+func (c *client) MergeMergeRequest(ctx context.Context, params forge.MergeMergeRequestParams) error {
+	return c.updatePullRequest(params.RepoURL, params.PRNumber, func(dir string, pr *PullRequest) error {
+		if pr.State != forge.PullRequestStateOpen {
+			return fmt.Errorf("pull request %d is %s", pr.Number, pr.State)
+		}
+
+		tree, err := mergeTree(ctx, dir, *pr)
+		if err != nil {
+			return err
+		} else if tree == "" {
+			return fmt.Errorf("pull request %d has conflicts", pr.Number)
+		}
+
+		base, err := revision(ctx, dir, "refs/heads/"+pr.Base)
+		if err != nil {
+			return err
+		}
+		commit, err := git(ctx, dir, "commit-tree", tree, "-p", base, "-m", pr.Title)
+		if err != nil {
+			return fmt.Errorf("failed to merge pull request: %w", err)
+		}
+		// The base is checked to not overwrite a concurrent push
+		if _, err := git(ctx, dir, "update-ref", "refs/heads/"+pr.Base, strings.TrimSpace(commit), base); err != nil {
+			return fmt.Errorf("failed to merge pull request: %w", err)
+		}
+
+		pr.State = forge.PullRequestStateMerged
+		return nil
+	})
+}
+
+// DeleteBranch deletes a branch from a local repository.
+func (c *client) DeleteBranch(ctx context.Context, params forge.DeleteBranchParams) error {
+	dir, err := c.repoDirFromURL(params.RepoURL)
+	if err != nil {
+		return err
+	}
+
+	if _, err := git(ctx, dir, "update-ref", "-d", "refs/heads/"+params.BranchName); err != nil {
+		return fmt.Errorf("failed to delete branch %s: %w", params.BranchName, err)
+	}
+	return nil
+}
+
+// DeletePullRequest closes a pull request of a local repository.
+func (c *client) DeletePullRequest(_ context.Context, params forge.DeletePullRequestParams) error {
+	return c.updatePullRequest(params.RepoURL, params.PRNumber, func(_ string, pr *PullRequest) error {
+		pr.State = forge.PullRequestStateClosed
+		return nil
+	})
+}
+
+// CheckMergeConflicts checks if the head of a pull request conflicts with its base.
+func (c *client) CheckMergeConflicts(ctx context.Context, params forge.CheckMergeConflictsParams) (bool, error) {
+	dir, pr, err := c.getPullRequest(params.RepoURL, params.PRNumber)
+	if err != nil {
+		return false, err
+	}
+
+	tree, err := mergeTree(ctx, dir, pr)
+	if err != nil {
+		return false, err
+	}
+	return tree == "", nil
+}
+
+// DiscoverRepositories reads the files and tags of the local repositories.
+// Missing files are absent from the results.
+func (c *client) DiscoverRepositories(ctx context.Context, params forge.DiscoverRepositoriesParams) (
+	[]forge.DiscoveredRepository, error) {
+	results := make([]forge.DiscoveredRepository, 0, len(params.RepoURLs))
+	for _, repoURL := range params.RepoURLs {
+		host, owner, repo := adapters.ParseRepository(repoURL)
+		if host != adapters.LocalHost {
+			return nil, fmt.Errorf("invalid local repository URL: %s", repoURL)
+		}
+
+		result := forge.DiscoveredRepository{RepoURL: repoURL, Files: make(map[string][]byte)}
+		for _, path := range params.Paths {
+			content, err := c.GetFileContent(ctx, forge.GetFileContentParams{
+				Host: host, Owner: owner, Repo: repo, Path: path, Ref: params.Ref,
+			})
+			if errors.Is(err, forge.ErrFileNotFound) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			result.Files[path] = content
+		}
+
+		tags, err := c.ListTags(ctx, forge.ListTagsParams{Host: host, Owner: owner, Repo: repo})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags of %s: %w", repoURL, err)
+		}
+		result.Tags = tags
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,236 @@
+//go:build unit
+// +build unit
+
+package local
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/stretchr/testify/require"
+)
+
+// run runs a git command in a directory.
+func run(t *testing.T, dir string, args ...string) {
+	args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+// newTestRepository creates the bare repository <root>/org/repo.git with the files
+// on its main branch, and returns its URL and a clone of it.
+func newTestRepository(t *testing.T, root string, files map[string]string) (string, string) {
+	bare := filepath.Join(root, "org", "repo.git")
+	require.NoError(t, os.MkdirAll(bare, 0o755))
+	run(t, bare, "init", "--bare", "--initial-branch=main")
+
+	work := t.TempDir()
+	run(t, work, "clone", bare, ".")
+	run(t, work, "checkout", "-b", "main")
+	for path, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(work, path)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(work, path), []byte(content), 0o600))
+	}
+	run(t, work, "add", ".")
+	run(t, work, "commit", "-m", "initial")
+	run(t, work, "push", "origin", "main")
+	return "file://" + bare, work
+}
+
+// commit writes a file on a new branch of the clone and pushes it.
+func commit(t *testing.T, work, branch, path, content string) {
+	run(t, work, "checkout", "-B", branch, "origin/main")
+	require.NoError(t, os.WriteFile(filepath.Join(work, path), []byte(content), 0o600))
+	run(t, work, "commit", "-am", "update "+path)
+	run(t, work, "push", "origin", branch)
+}
+
+func TestFilesAndTags(t *testing.T) {
+	root := t.TempDir()
+	_, work := newTestRepository(t, root, map[string]string{
+		"go.mod":                   "module example.com/org/repo\n",
+		".github/workflows/ci.yml": "on: push\n",
+	})
+	run(t, work, "tag", "v1.0.0")
+	run(t, work, "tag", "-a", "v1.1.0", "-m", "release")
+	run(t, work, "push", "origin", "--tags")
+
+	c, err := New(Options{Root: root})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	content, err := c.GetFileContent(ctx, forge.GetFileContentParams{
+		Host: "local", Owner: "org", Repo: "repo", Path: "go.mod", Ref: "main",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "module example.com/org/repo\n", string(content))
+
+	_, err = c.GetFileContent(ctx, forge.GetFileContentParams{Owner: "org", Repo: "repo", Path: "tools.go", Ref: "main"})
+	require.ErrorIs(t, err, forge.ErrFileNotFound)
+
+	paths, err := c.ListDirectory(ctx, forge.ListDirectoryParams{
+		Owner: "org", Repo: "repo", Path: ".github/workflows", Ref: "main",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{".github/workflows/ci.yml"}, paths)
+
+	paths, err = c.ListDirectory(ctx, forge.ListDirectoryParams{Owner: "org", Repo: "repo", Path: "docs", Ref: "main"})
+	require.NoError(t, err)
+	require.Empty(t, paths)
+
+	// Both tags point to the commit, even the annotated one
+	tags, err := c.ListTags(ctx, forge.ListTagsParams{Owner: "org", Repo: "repo"})
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+	require.Equal(t, tags[0].CommitSHA, tags[1].CommitSHA)
+	names := []string{tags[0].Name, tags[1].Name}
+	require.ElementsMatch(t, []string{"v1.0.0", "v1.1.0"}, names)
+}
+
+func TestPullRequests(t *testing.T) {
+	root := t.TempDir()
+	repoURL, work := newTestRepository(t, root, map[string]string{"go.mod": "module example.com/org/repo\n"})
+	commit(t, work, "depsync/update", "go.mod", "module example.com/org/repo\n\ngo 1.23\n")
+
+	c, err := New(Options{Root: root})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	number, err := c.CreateMergeRequest(ctx, forge.CreateMergeRequestParams{
+		RepoURL: repoURL, SourceBranch: "depsync/update", ModulePath: "example.com/dep", TargetVersion: "v1.1.0",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, number)
+	require.FileExists(t, filepath.Join(root, PullRequestsFile))
+
+	number, err = c.CheckPullRequestExists(ctx, forge.CheckPullRequestExistsParams{
+		RepoURL: repoURL, SourceBranch: "depsync/update",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, number)
+
+	status, err := c.GetPullRequestChecks(ctx, forge.GetPullRequestChecksParams{RepoURL: repoURL, PRNumber: 1})
+	require.NoError(t, err)
+	require.Equal(t, "passed", status.Status)
+
+	conflicts, err := c.CheckMergeConflicts(ctx, forge.CheckMergeConflictsParams{RepoURL: repoURL, PRNumber: 1})
+	require.NoError(t, err)
+	require.False(t, conflicts)
+
+	// The merge squashes the branch on main
+	require.NoError(t, c.MergeMergeRequest(ctx, forge.MergeMergeRequestParams{RepoURL: repoURL, PRNumber: 1}))
+	state, err := c.GetPullRequestState(ctx, forge.GetPullRequestStateParams{RepoURL: repoURL, PRNumber: 1})
+	require.NoError(t, err)
+	require.Equal(t, forge.PullRequestStateMerged, state)
+
+	content, err := c.GetFileContent(ctx, forge.GetFileContentParams{
+		Owner: "org", Repo: "repo", Path: "go.mod", Ref: "main",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "module example.com/org/repo\n\ngo 1.23\n", string(content))
+
+	number, err = c.CheckPullRequestExists(ctx, forge.CheckPullRequestExistsParams{
+		RepoURL: repoURL, SourceBranch: "depsync/update",
+	})
+	require.NoError(t, err)
+	require.Equal(t, -1, number)
+}
+
+func TestConflictsAndBranches(t *testing.T) {
+	root := t.TempDir()
+	repoURL, work := newTestRepository(t, root, map[string]string{"go.mod": "module example.com/org/repo\n"})
+	commit(t, work, "depsync/update", "go.mod", "module example.com/org/repo\n\ngo 1.23\n")
+	commit(t, work, "main", "go.mod", "module example.com/org/repo\n\ngo 1.24\n")
+
+	c, err := New(Options{Root: root, Checks: "running"})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	number, err := c.CreateMergeRequest(ctx, forge.CreateMergeRequestParams{
+		RepoURL: repoURL, SourceBranch: "depsync/update", Title: "chores(depsync): update go",
+	})
+	require.NoError(t, err)
+
+	status, err := c.GetPullRequestChecks(ctx, forge.GetPullRequestChecksParams{RepoURL: repoURL, PRNumber: number})
+	require.NoError(t, err)
+	require.Equal(t, "running", status.Status)
+
+	conflicts, err := c.CheckMergeConflicts(ctx, forge.CheckMergeConflictsParams{RepoURL: repoURL, PRNumber: number})
+	require.NoError(t, err)
+	require.True(t, conflicts)
+	require.Error(t, c.MergeMergeRequest(ctx, forge.MergeMergeRequestParams{RepoURL: repoURL, PRNumber: number}))
+
+	// Close the pull request and delete its branch
+	require.NoError(t, c.DeletePullRequest(ctx, forge.DeletePullRequestParams{RepoURL: repoURL, PRNumber: number}))
+	state, err := c.GetPullRequestState(ctx, forge.GetPullRequestStateParams{RepoURL: repoURL, PRNumber: number})
+	require.NoError(t, err)
+	require.Equal(t, forge.PullRequestStateClosed, state)
+
+	exists, err := c.BranchExists(ctx, forge.BranchExistsParams{RepoURL: repoURL, BranchName: "depsync/update"})
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.NoError(t, c.DeleteBranch(ctx, forge.DeleteBranchParams{RepoURL: repoURL, BranchName: "depsync/update"}))
+	exists, err = c.BranchExists(ctx, forge.BranchExistsParams{RepoURL: repoURL, BranchName: "depsync/update"})
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestCreateCommit(t *testing.T) {
+	root := t.TempDir()
+	repoURL, _ := newTestRepository(t, root, map[string]string{
+		"go.mod": "module example.com/org/repo\n", "main.go": "package main\n",
+	})
+	c, err := New(Options{Root: root})
+	require.NoError(t, err)
+	ctx := context.Background()
+	base, err := c.GetBranchSHA(ctx, forge.GetBranchSHAParams{RepoURL: repoURL, BranchName: "main"})
+	require.NoError(t, err)
+
+	params := forge.CreateCommitParams{
+		RepoURL:    repoURL,
+		BaseBranch: "main",
+		BaseSHA:    base,
+		BranchName: "depsync/update",
+		Message:    "chores(depsync): update",
+		Files: []forge.CommitFile{
+			{Path: "go.mod", Content: []byte("module example.com/org/repo\n\ngo 1.24\n")},
+			{Path: "go.sum", Content: []byte("example.com/dep v1.0.0 h1:abc=\n")},
+		},
+		AuthorName:  "depsync",
+		AuthorEmail: "depsync@example.com",
+	}
+	sha, err := c.CreateCommit(ctx, params)
+	require.NoError(t, err)
+	head, err := c.GetBranchSHA(ctx, forge.GetBranchSHAParams{RepoURL: repoURL, BranchName: "depsync/update"})
+	require.NoError(t, err)
+	require.Equal(t, sha, head)
+
+	// The files are replaced or added, and the others kept
+	for path, content := range map[string]string{
+		"go.mod":  "module example.com/org/repo\n\ngo 1.24\n",
+		"go.sum":  "example.com/dep v1.0.0 h1:abc=\n",
+		"main.go": "package main\n",
+	} {
+		actual, err := c.GetFileContent(ctx, forge.GetFileContentParams{
+			Owner: "org", Repo: "repo", Path: path, Ref: "depsync/update",
+		})
+		require.NoError(t, err)
+		require.Equal(t, content, string(actual))
+	}
+	author, err := git(ctx, filepath.Join(root, "org", "repo.git"), "log", "-1", "--format=%an <%ae> %s", sha)
+	require.NoError(t, err)
+	require.Equal(t, "depsync <depsync@example.com> chores(depsync): update\n", author)
+	parent, err := git(ctx, filepath.Join(root, "org", "repo.git"), "log", "-1", "--format=%P", sha)
+	require.NoError(t, err)
+	require.Equal(t, base+"\n", parent)
+
+	// The existing branches are not overwritten
+	_, err = c.CreateCommit(ctx, params)
+	require.Error(t, err)
+}
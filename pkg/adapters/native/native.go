@@ -0,0 +1,430 @@
+package native
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/adapters/git"
+	"github.com/cryptellation/depsync/pkg/gomod"
+	"github.com/cryptellation/depsync/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// Options contains the options of the native runner.
+type Options struct {
+	// HostTokens are the tokens of the git hosts other than github.com, by host.
+	// The GitHub token is used for the hosts without token.
+	HostTokens map[string]string
+	// TokenSource provides the GitHub tokens instead of the static token, such as
+	// the installation tokens of a GitHub App.
+	TokenSource adapters.TokenSource
+	// WorkDir is the directory of the working copies (default: the temporary directory).
+	WorkDir string
+}
+
+// workspace is a working copy of a repository, in a temporary directory of the host.
+type workspace struct {
+	repoURL string
+	dir     string
+	// branch is the branch the repository was cloned at.
+	branch string
+}
+
+// RepoURL implements the dagger.Workspace interface.
+func (w *workspace) RepoURL() string {
+	return w.repoURL
+}
+
+// runner implements the Dagger interface by running go directly on the host, and git
+// in-process, without Dagger engine nor container runtime.
+type runner struct {
+	// Registry resolves the image digests.
+	*Registry
+	creds   git.Credentials
+	workDir string
+
+	mu sync.Mutex
+	// dirs are the directories of the workspaces not removed yet.
+	dirs map[string]struct{}
+}
+
+// New returns a runner implementing the Dagger interface with the go command of the host.
+func New(githubToken string, opts Options) (dagger.Dagger, error) {
+	if _, err := exec.LookPath("go"); err != nil {
+		return nil, fmt.Errorf("go is required by the native runner: %w", err)
+	}
+
+	return &runner{
+		Registry: NewRegistry(),
+		creds:    git.NewCredentials(githubToken, opts.HostTokens, opts.TokenSource),
+		workDir:  opts.WorkDir,
+		dirs:     make(map[string]struct{}),
+	}, nil
+}
+
+// run runs a command in a directory and returns its standard output.
+func run(ctx context.Context, dir string, name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	// The modules fetched with git must not prompt for credentials
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s %s: %w: %s", name, args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// verify runs the verification commands in a directory, with "sh -c". It returns a
+// VerificationError with the output of the first failing command.
+func verify(ctx context.Context, dir string, commands []string) error {
+	for _, command := range commands {
+		logging.C(ctx).Info("Verifying update", zap.String("command", command))
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		output, err := cmd.CombinedOutput()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return &dagger.VerificationError{Command: command, Output: string(output)}
+		} else if err != nil {
+			return fmt.Errorf("failed to verify update: %w", err)
+		}
+	}
+	return nil
+}
+
+// workspaceDir returns the directory of a workspace.
+func workspaceDir(ws dagger.Workspace) (string, error) {
+	w, ok := ws.(*workspace)
+	if !ok || w == nil {
+		return "", fmt.Errorf("unsupported workspace: %T", ws)
+	}
+	return w.dir, nil
+}
+
+// remove removes the directory of a workspace.
+func (r *runner) remove(dir string) error {
+	r.mu.Lock()
+	delete(r.dirs, dir)
+	r.mu.Unlock()
+	return os.RemoveAll(dir)
+}
+
+// Close removes the workspaces not removed yet.
+func (r *runner) Close() error {
+	r.mu.Lock()
+	dirs := make([]string, 0, len(r.dirs))
+	for dir := range r.dirs {
+		dirs = append(dirs, dir)
+	}
+	r.mu.Unlock()
+
+	var errs []error
+	for _, dir := range dirs {
+		errs = append(errs, r.remove(dir))
+	}
+	return errors.Join(errs...)
+}
+
+// CloneRepo clones the given repo URL at the given branch in a new temporary directory.
+// The directory is removed by RemoveRepo, or on Close.
+func (r *runner) CloneRepo(ctx context.Context, repoURL, branch string) (dagger.Workspace, error) {
+	logger := logging.C(ctx)
+	logger.Info("Cloning repository", zap.String("repo_url", repoURL), zap.String("branch", branch))
+
+	remote, err := r.creds.Remote(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp(r.workDir, "depsync-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	r.mu.Lock()
+	r.dirs[dir] = struct{}{}
+	r.mu.Unlock()
+
+	if err := git.Clone(ctx, dir, remote, branch); err != nil {
+		_ = r.remove(dir)
+		logger.Error("Failed to clone repository", zap.Error(err))
+		return nil, fmt.Errorf("failed to clone repository: %w", err)
+	}
+
+	logger.Info("Repository cloned", zap.String("dir", dir))
+	return &workspace{repoURL: repoURL, dir: dir, branch: branch}, nil
+}
+
+// ResetRepo resets the workspace to the branch it was cloned at, discarding the changes of
+// the previous update. The modules downloaded by the previous updates are kept in the
+// module cache of the host.
+func (r *runner) ResetRepo(ctx context.Context, ws dagger.Workspace) (dagger.Workspace, error) {
+	w, ok := ws.(*workspace)
+	if !ok || w == nil {
+		return nil, fmt.Errorf("unsupported workspace: %T", ws)
+	}
+
+	logging.C(ctx).Info("Resetting repository", zap.String("dir", w.dir), zap.String("branch", w.branch))
+	if err := git.Reset(w.dir, w.branch); err != nil {
+		return nil, fmt.Errorf("failed to reset repository: %w", err)
+	}
+	return ws, nil
+}
+
+// RemoveRepo removes the directory of the workspace.
+func (r *runner) RemoveRepo(_ context.Context, ws dagger.Workspace) error {
+	dir, err := workspaceDir(ws)
+	if err != nil {
+		return err
+	}
+	return r.remove(dir)
+}
+
+// goGet runs "go get" on the modules in a workspace, then the additional commands.
+func goGet(ctx context.Context, ws dagger.Workspace, modules []dagger.Module, commands ...[]string) (
+	dagger.Workspace, error) {
+	dir, err := workspaceDir(ws)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"get"}
+	for _, module := range modules {
+		args = append(args, fmt.Sprintf("%s@%s", module.Path, module.Version))
+	}
+	if _, err := run(ctx, dir, "go", args...); err != nil {
+		return nil, err
+	}
+	for _, command := range commands {
+		if _, err := run(ctx, dir, command[0], command[1:]...); err != nil {
+			return nil, err
+		}
+	}
+	return ws, nil
+}
+
+// vendored checks if the repository of the workspace commits its vendor directory, which must
+// then be updated with "go mod tidy" and "go mod vendor" after "go get", as it leaves
+// vendor/modules.txt inconsistent.
+func vendored(ctx context.Context, ws dagger.Workspace) bool {
+	dir, err := workspaceDir(ws)
+	if err != nil {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(dir, gomod.VendorModulesFile)); err != nil {
+		return false
+	}
+	logging.C(ctx).Info("Vendor directory detected, updating it with go mod vendor")
+	return true
+}
+
+// vendorCommands returns the commands updating the vendor directory of the workspace after
+// "go get", if any.
+func vendorCommands(ctx context.Context, ws dagger.Workspace) [][]string {
+	if !vendored(ctx, ws) {
+		return nil
+	}
+	return [][]string{{"go", "mod", "tidy"}, {"go", "mod", "vendor"}}
+}
+
+// UpdateGoDependency updates a Go dependency in the workspace to the specified version.
+// The Go toolchain is the one of the host, or the one required by the go.mod file.
+func (r *runner) UpdateGoDependency(ctx context.Context, params dagger.UpdateGoDependencyParams) (
+	dagger.Workspace, error) {
+	logger := logging.C(ctx)
+	logger.Info("Updating Go dependency",
+		zap.String("module_path", params.ModulePath),
+		zap.String("target_version", params.TargetVersion))
+
+	ws, err := goGet(ctx, params.Dir, []dagger.Module{{Path: params.ModulePath, Version: params.TargetVersion}},
+		vendorCommands(ctx, params.Dir)...)
+	if err != nil {
+		logger.Error("Failed to update dependency", zap.Error(err))
+		return nil, fmt.Errorf("failed to update dependency: %w", err)
+	}
+
+	// Verify go.mod still exists
+	dir, _ := workspaceDir(ws)
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err != nil {
+		logger.Error("go.mod file not found after dependency update")
+		return nil, fmt.Errorf("go.mod file not found after dependency update")
+	}
+	if err := verify(ctx, dir, params.Verify); err != nil {
+		logger.Error("Failed to verify dependency update", zap.Error(err))
+		return nil, err
+	}
+
+	logger.Info("Dependency updated successfully",
+		zap.String("module_path", params.ModulePath),
+		zap.String("target_version", params.TargetVersion))
+	return ws, nil
+}
+
+// UpdateGoDependencies updates several Go dependencies in the workspace with a single "go get".
+func (r *runner) UpdateGoDependencies(ctx context.Context, params dagger.UpdateGoDependenciesParams) (
+	dagger.Workspace, error) {
+	logger := logging.C(ctx)
+	for _, module := range params.Modules {
+		logger.Info("Updating Go dependency",
+			zap.String("module_path", module.Path),
+			zap.String("target_version", module.Version))
+	}
+
+	ws, err := goGet(ctx, params.Dir, params.Modules, vendorCommands(ctx, params.Dir)...)
+	if err != nil {
+		logger.Error("Failed to update dependencies", zap.Error(err))
+		return nil, fmt.Errorf("failed to update dependencies: %w", err)
+	}
+	dir, _ := workspaceDir(ws)
+	if err := verify(ctx, dir, params.Verify); err != nil {
+		logger.Error("Failed to verify dependencies update", zap.Error(err))
+		return nil, err
+	}
+
+	logger.Info("Dependencies updated successfully", zap.Int("dependency_count", len(params.Modules)))
+	return ws, nil
+}
+
+// UpdateGoTools updates the tool modules in the workspace to the specified versions
+// and optionally regenerates the code with them.
+func (r *runner) UpdateGoTools(ctx context.Context, params dagger.UpdateGoToolsParams) (dagger.Workspace, error) {
+	logger := logging.C(ctx)
+	for _, tool := range params.Tools {
+		logger.Info("Updating Go tool",
+			zap.String("module_path", tool.Path),
+			zap.String("target_version", tool.Version))
+	}
+
+	commands := [][]string{{"go", "mod", "tidy"}}
+	if vendored(ctx, params.Dir) {
+		commands = append(commands, []string{"go", "mod", "vendor"})
+	}
+	if params.Generate {
+		commands = append(commands, []string{"go", "generate", "./..."})
+	}
+	ws, err := goGet(ctx, params.Dir, params.Tools, commands...)
+	if err != nil {
+		logger.Error("Failed to update tools", zap.Error(err))
+		return nil, fmt.Errorf("failed to update tools: %w", err)
+	}
+	dir, _ := workspaceDir(ws)
+	if err := verify(ctx, dir, params.Verify); err != nil {
+		logger.Error("Failed to verify tools update", zap.Error(err))
+		return nil, err
+	}
+
+	logger.Info("Tools updated successfully", zap.Int("tool_count", len(params.Tools)))
+	return ws, nil
+}
+
+// WriteFiles writes the given files in the workspace, replacing existing ones.
+func (r *runner) WriteFiles(ctx context.Context, params dagger.WriteFilesParams) (dagger.Workspace, error) {
+	logger := logging.C(ctx)
+
+	dir, err := workspaceDir(params.Dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range params.Files {
+		logger.Info("Writing file", zap.String("path", f.Path), zap.Int("size", len(f.Content)))
+
+		permissions := os.FileMode(0o644)
+		if f.Executable {
+			permissions = 0o755
+		}
+		path := filepath.Join(dir, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to write files: %w", err)
+		}
+		if err := os.WriteFile(path, f.Content, permissions); err != nil {
+			return nil, fmt.Errorf("failed to write files: %w", err)
+		}
+		// The permissions of existing files are not changed by WriteFile
+		if err := os.Chmod(path, permissions); err != nil {
+			return nil, fmt.Errorf("failed to write files: %w", err)
+		}
+	}
+	return params.Dir, nil
+}
+
+// CheckBranchExists checks if a branch already exists in the remote repository.
+func (r *runner) CheckBranchExists(ctx context.Context, params dagger.CheckBranchExistsParams) (bool, error) {
+	logger := logging.C(ctx)
+	logger.Info("Checking if branch exists",
+		zap.String("branch_name", params.BranchName),
+		zap.String("repo_url", params.RepoURL))
+
+	remote, err := r.creds.Remote(params.RepoURL)
+	if err != nil {
+		return false, err
+	}
+
+	exists, err := git.BranchExists(ctx, remote, params.BranchName)
+	if err != nil {
+		logger.Error("Failed to check branch existence", zap.Error(err))
+		return false, fmt.Errorf("failed to check branch existence: %w", err)
+	}
+
+	if !exists {
+		logger.Info("Branch does not exist, proceeding with dependency update",
+			zap.String("branch_name", params.BranchName))
+		return false, nil
+	}
+
+	logger.Warn("Branch already exists, skipping dependency update",
+		zap.String("branch_name", params.BranchName),
+		zap.String("repo_url", params.RepoURL))
+	return true, nil
+}
+
+// CommitAndPush commits the changes and pushes to a new branch.
+func (r *runner) CommitAndPush(ctx context.Context, params dagger.CommitAndPushParams) (string, error) {
+	logger := logging.C(ctx)
+	logger.Info("Committing and pushing changes",
+		zap.String("module_path", params.ModulePath),
+		zap.String("branch_name", params.BranchName))
+
+	// Format the commit message, unless provided
+	commitMessage := params.CommitMessage
+	if commitMessage == "" {
+		commitMessage = adapters.FormatCommitMessage(params.ModulePath, params.TargetVersion)
+	}
+
+	dir, err := workspaceDir(params.Dir)
+	if err != nil {
+		return "", err
+	}
+	remote, err := r.creds.Remote(params.RepoURL)
+	if err != nil {
+		return "", err
+	}
+
+	err = git.CommitAndPush(ctx, git.CommitAndPushParams{
+		Dir:         dir,
+		Remote:      remote,
+		BranchName:  params.BranchName,
+		Message:     commitMessage,
+		AuthorName:  params.AuthorName,
+		AuthorEmail: params.AuthorEmail,
+	})
+	if err != nil {
+		logger.Error("Failed to push branch", zap.Error(err))
+		return "", fmt.Errorf("failed to push branch: %w", err)
+	}
+
+	logger.Info("Successfully committed and pushed changes",
+		zap.String("branch_name", params.BranchName),
+		zap.String("commit_message", commitMessage))
+	return params.BranchName, nil
+}
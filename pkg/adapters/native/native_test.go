@@ -0,0 +1,158 @@
+//go:build unit
+// +build unit
+
+package native
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/stretchr/testify/require"
+)
+
+// runGit runs a git command in a directory and returns its output.
+func runGit(t *testing.T, dir string, args ...string) string {
+	args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return string(out)
+}
+
+// newTestRemote creates a bare repository with a go.mod file on its main branch and
+// returns its URL and path.
+func newTestRemote(t *testing.T) (string, string) {
+	bare := filepath.Join(t.TempDir(), "org", "repo.git")
+	require.NoError(t, os.MkdirAll(bare, 0o755))
+	runGit(t, bare, "init", "--bare", "--initial-branch=main")
+
+	work := t.TempDir()
+	runGit(t, work, "clone", bare, ".")
+	runGit(t, work, "checkout", "-b", "main")
+	require.NoError(t, os.WriteFile(filepath.Join(work, "go.mod"), []byte("module example.com/org/repo\n"), 0o600))
+	runGit(t, work, "add", ".")
+	runGit(t, work, "commit", "-m", "initial")
+	runGit(t, work, "push", "origin", "main")
+	return "file://" + bare, bare
+}
+
+func TestCloneWriteAndPush(t *testing.T) {
+	repoURL, bare := newTestRemote(t)
+	workDir := t.TempDir()
+	d, err := New("", Options{WorkDir: workDir})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	ws, err := d.CloneRepo(ctx, repoURL, "main")
+	require.NoError(t, err)
+
+	exists, err := d.CheckBranchExists(ctx, dagger.CheckBranchExistsParams{
+		Dir: ws, BranchName: "depsync/sync", RepoURL: repoURL,
+	})
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	ws, err = d.WriteFiles(ctx, dagger.WriteFilesParams{Dir: ws, Files: []dagger.File{
+		{Path: ".github/workflows/ci.yml", Content: []byte("on: push\n")},
+		{Path: "scripts/check.sh", Content: []byte("#!/bin/sh\n"), Executable: true},
+	}})
+	require.NoError(t, err)
+
+	branch, err := d.CommitAndPush(ctx, dagger.CommitAndPushParams{
+		Dir: ws, BranchName: "depsync/sync", RepoURL: repoURL,
+		AuthorName: "depsync", AuthorEmail: "depsync@example.com", CommitMessage: "chores(depsync): sync files",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "depsync/sync", branch)
+
+	// The branch is pushed with the files
+	files := runGit(t, bare, "ls-tree", "-r", "--name-only", "depsync/sync")
+	require.Equal(t, []string{".github/workflows/ci.yml", "go.mod", "scripts/check.sh"}, strings.Fields(files))
+	require.Equal(t, "100755", strings.Fields(runGit(t, bare, "ls-tree", "depsync/sync", "scripts/check.sh"))[0])
+	require.Equal(t, "chores(depsync): sync files\n", runGit(t, bare, "log", "-1", "--format=%s", "depsync/sync"))
+
+	exists, err = d.CheckBranchExists(ctx, dagger.CheckBranchExistsParams{
+		Dir: ws, BranchName: "depsync/sync", RepoURL: repoURL,
+	})
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	// The workspace is reused for the next branch, from main
+	ws, err = d.ResetRepo(ctx, ws)
+	require.NoError(t, err)
+	ws, err = d.WriteFiles(ctx, dagger.WriteFilesParams{Dir: ws, Files: []dagger.File{
+		{Path: "README.md", Content: []byte("# repo\n")},
+	}})
+	require.NoError(t, err)
+	_, err = d.CommitAndPush(ctx, dagger.CommitAndPushParams{
+		Dir: ws, BranchName: "depsync/readme", RepoURL: repoURL,
+		AuthorName: "depsync", AuthorEmail: "depsync@example.com", CommitMessage: "chores(depsync): sync readme",
+	})
+	require.NoError(t, err)
+	files = runGit(t, bare, "ls-tree", "-r", "--name-only", "depsync/readme")
+	require.Equal(t, []string{"README.md", "go.mod"}, strings.Fields(files))
+
+	// The workspaces are removed with RemoveRepo, and the others on Close
+	require.NoError(t, d.RemoveRepo(ctx, ws))
+	entries, err := os.ReadDir(workDir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	_, err = d.CloneRepo(ctx, repoURL, "main")
+	require.NoError(t, err)
+	require.NoError(t, d.Close())
+	entries, err = os.ReadDir(workDir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestCloneRepo_UnknownBranch(t *testing.T) {
+	repoURL, _ := newTestRemote(t)
+	workDir := t.TempDir()
+	d, err := New("", Options{WorkDir: workDir})
+	require.NoError(t, err)
+
+	_, err = d.CloneRepo(context.Background(), repoURL, "missing")
+	require.Error(t, err)
+	entries, err := os.ReadDir(workDir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	// The commands run in the workspace, and their changes are kept
+	require.NoError(t, verify(ctx, dir, []string{"echo ok > verified.txt", "test -f verified.txt"}))
+	require.FileExists(t, filepath.Join(dir, "verified.txt"))
+
+	// The first failing command stops the verification, with its output
+	err := verify(ctx, dir, []string{"echo building; echo broken >&2; exit 3", "touch unreachable.txt"})
+	var verifyErr *dagger.VerificationError
+	require.ErrorAs(t, err, &verifyErr)
+	require.Equal(t, "echo building; echo broken >&2; exit 3", verifyErr.Command)
+	require.Equal(t, "building\nbroken\n", verifyErr.Output)
+	require.NoFileExists(t, filepath.Join(dir, "unreachable.txt"))
+}
+
+func TestVendorCommands(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	ws := &workspace{dir: dir, branch: "main"}
+
+	// Without vendor directory, "go get" is enough
+	require.Nil(t, vendorCommands(ctx, ws))
+
+	// The committed vendor directory is updated after "go get"
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "vendor"), 0o755))
+	modules := []byte("# example.com/dep v1.0.0\n")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "vendor", "modules.txt"), modules, 0o600))
+	require.Equal(t, [][]string{{"go", "mod", "tidy"}, {"go", "mod", "vendor"}}, vendorCommands(ctx, ws))
+}
@@ -0,0 +1,172 @@
+package native
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cryptellation/depsync/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// manifestMediaTypes are the media types of the manifests accepted from the registries,
+// the index first to resolve the digest of multi-platform images.
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// imageReference is a parsed image reference.
+type imageReference struct {
+	Registry   string
+	Repository string
+	// Reference is the tag or the digest of the image.
+	Reference string
+}
+
+// parseImageReference parses an image reference (e.g. golang:1.24-alpine,
+// ghcr.io/owner/image:tag), with the Docker Hub defaults.
+func parseImageReference(image string) imageReference {
+	name, reference := image, "latest"
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, reference = name[:i], name[i+1:]
+	} else if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, reference = name[:i], name[i+1:]
+	}
+
+	// The first component is a registry if it looks like a host
+	registry := "registry-1.docker.io"
+	if first, rest, ok := strings.Cut(name, "/"); ok &&
+		(strings.ContainsAny(first, ".:") || first == "localhost") {
+		registry, name = first, rest
+	}
+	if registry == "docker.io" || registry == "index.docker.io" {
+		registry = "registry-1.docker.io"
+	}
+	if registry == "registry-1.docker.io" && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	return imageReference{Registry: registry, Repository: name, Reference: reference}
+}
+
+// Registry resolves the image digests with the registry API. It requires neither go nor git,
+// e.g. to preview the Dockerfile updates on a host without Go toolchain.
+type Registry struct {
+	httpClient *http.Client
+}
+
+// NewRegistry returns a Registry with the default HTTP client.
+func NewRegistry() *Registry {
+	return &Registry{httpClient: http.DefaultClient}
+}
+
+// ResolveImageDigest resolves the digest of an image reference (e.g. golang:1.24-alpine)
+// with the registry API, and returns it (e.g. sha256:...). Only public images, or
+// images accessible anonymously, are supported.
+func (r *Registry) ResolveImageDigest(ctx context.Context, image string) (string, error) {
+	logger := logging.C(ctx)
+	logger.Info("Resolving image digest", zap.String("image", image))
+
+	ref := parseImageReference(image)
+	if strings.HasPrefix(ref.Reference, "sha256:") {
+		return ref.Reference, nil
+	}
+
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.Registry, ref.Repository, ref.Reference)
+	resp, err := r.headManifest(ctx, manifestURL, "")
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		// Get an anonymous token from the authentication server of the registry
+		var token string
+		token, err = r.registryToken(ctx, resp.Header.Get("WWW-Authenticate"))
+		if err == nil {
+			resp, err = r.headManifest(ctx, manifestURL, token)
+		}
+	}
+	if err != nil {
+		logger.Error("Failed to resolve image digest", zap.String("image", image), zap.Error(err))
+		return "", fmt.Errorf("failed to resolve image digest for %s: %w", image, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to resolve image digest for %s: %s", image, resp.Status)
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("no digest for image %s", image)
+	}
+	return digest, nil
+}
+
+// headManifest requests the headers of a manifest, with the token if set.
+func (r *Registry) headManifest(ctx context.Context, manifestURL, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	return resp, nil
+}
+
+// registryToken gets an anonymous token from the authentication server of a registry,
+// described by the WWW-Authenticate header of its challenge
+// (e.g. Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="...").
+func (r *Registry) registryToken(ctx context.Context, challenge string) (string, error) {
+	params, ok := strings.CutPrefix(challenge, "Bearer ")
+	if !ok {
+		return "", fmt.Errorf("unsupported registry authentication: %q", challenge)
+	}
+
+	query := url.Values{}
+	var realm string
+	for _, param := range strings.Split(params, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		value = strings.Trim(value, `"`)
+		if key == "realm" {
+			realm = value
+		} else if key == "service" || key == "scope" {
+			query.Set(key, value)
+		}
+	}
+	if realm == "" {
+		return "", fmt.Errorf("no realm in registry authentication: %q", challenge)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get registry token: %s", resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode registry token: %w", err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
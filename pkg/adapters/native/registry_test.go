@@ -0,0 +1,68 @@
+//go:build unit
+// +build unit
+
+package native
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImageReference(t *testing.T) {
+	cases := []struct {
+		image string
+		ref   imageReference
+	}{
+		{"golang:1.24-alpine", imageReference{"registry-1.docker.io", "library/golang", "1.24-alpine"}},
+		{"alpine", imageReference{"registry-1.docker.io", "library/alpine", "latest"}},
+		{"docker.io/owner/image:v1", imageReference{"registry-1.docker.io", "owner/image", "v1"}},
+		{"ghcr.io/owner/image:v1", imageReference{"ghcr.io", "owner/image", "v1"}},
+		{"localhost:5000/image", imageReference{"localhost:5000", "image", "latest"}},
+		{"golang@sha256:abc", imageReference{"registry-1.docker.io", "library/golang", "sha256:abc"}},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.ref, parseImageReference(c.image), c.image)
+	}
+}
+
+func TestResolveImageDigest(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			require.Equal(t, "repository:owner/image:pull", r.URL.Query().Get("scope"))
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]string{"token": "anonymous"}))
+		case "/v2/owner/image/manifests/v1":
+			if r.Header.Get("Authorization") != "Bearer anonymous" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+					`Bearer realm="%s/token",service="registry",scope="repository:owner/image:pull"`, srv.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			require.Equal(t, http.MethodHead, r.Method)
+			require.True(t, strings.HasPrefix(r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json"))
+			w.Header().Set("Docker-Content-Digest", "sha256:0123")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	r := &Registry{httpClient: srv.Client()}
+	host := strings.TrimPrefix(srv.URL, "https://")
+	ctx := context.Background()
+
+	digest, err := r.ResolveImageDigest(ctx, host+"/owner/image:v1")
+	require.NoError(t, err)
+	require.Equal(t, "sha256:0123", digest)
+
+	_, err = r.ResolveImageDigest(ctx, host+"/owner/missing:v1")
+	require.Error(t, err)
+}
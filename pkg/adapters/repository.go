@@ -0,0 +1,59 @@
+package adapters
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	// DefaultHost is the host of the repositories without explicit host.
+	DefaultHost = "github.com"
+	// LocalHost is the host of the repositories on the local filesystem (file:// URLs).
+	LocalHost = "local"
+	// DefaultBranch is the branch the repositories are read from, and the updates are merged into.
+	DefaultBranch = "main"
+)
+
+// ParseRepository extracts the host, owner and name of a repository from its URL
+// (e.g. https://github.com/owner/repo.git, git@ghe.example.com:owner/repo.git) or
+// from a module path (e.g. ghe.example.com/owner/repo/v2). The owner of a URL is its
+// whole namespace (e.g. group/subgroup for https://gitlab.example.com/group/subgroup/repo),
+// while the owner and name of a module path are its first two elements. The repositories
+// on the local filesystem (e.g. file:///srv/repos/owner/repo.git) have the LocalHost host,
+// and the owner and name of their last two directories. It returns empty strings
+// if it can't.
+func ParseRepository(url string) (host, owner, repo string) {
+	if path, ok := strings.CutPrefix(url, "file://"); ok {
+		parts := strings.Split(strings.TrimSuffix(path, "/"), "/")
+		if len(parts) < 2 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+			return "", "", ""
+		}
+		return LocalHost, parts[len(parts)-2], strings.TrimSuffix(parts[len(parts)-1], ".git")
+	}
+
+	rest := url
+	isURL := false
+	for _, scheme := range []string{"https://", "http://", "ssh://"} {
+		if after, ok := strings.CutPrefix(rest, scheme); ok {
+			rest, isURL = after, true
+		}
+	}
+	if after, ok := strings.CutPrefix(rest, "git@"); ok {
+		rest, isURL = strings.Replace(after, ":", "/", 1), true
+	}
+	// Remove the credentials, if any
+	if i := strings.Index(rest, "@"); i >= 0 && i < strings.Index(rest, "/") {
+		rest = rest[i+1:]
+	}
+
+	parts := strings.Split(strings.TrimSuffix(strings.TrimSuffix(rest, "/"), ".git"), "/")
+	if len(parts) < 3 || slices.Contains(parts, "") {
+		return "", "", ""
+	}
+	if isURL {
+		// The namespace of the repository may have several levels (e.g. GitLab subgroups)
+		last := len(parts) - 1
+		return strings.ToLower(parts[0]), strings.Join(parts[1:last], "/"), parts[last]
+	}
+	return strings.ToLower(parts[0]), parts[1], strings.TrimSuffix(parts[2], ".git")
+}
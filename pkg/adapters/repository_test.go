@@ -0,0 +1,35 @@
+//go:build unit
+// +build unit
+
+package adapters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRepository(t *testing.T) {
+	cases := []struct {
+		url               string
+		host, owner, repo string
+	}{
+		{"https://github.com/owner/repo", "github.com", "owner", "repo"},
+		{"https://github.com/owner/repo.git", "github.com", "owner", "repo"},
+		{"https://GHE.example.com/owner/repo/", "ghe.example.com", "owner", "repo"},
+		{"https://token@ghe.example.com/owner/repo.git", "ghe.example.com", "owner", "repo"},
+		{"git@ghe.example.com:owner/repo.git", "ghe.example.com", "owner", "repo"},
+		{"github.com/owner/repo/v2", "github.com", "owner", "repo"},
+		{"https://gitlab.example.com/group/subgroup/repo.git", "gitlab.example.com", "group/subgroup", "repo"},
+		{"git@gitlab.example.com:group/subgroup/repo.git", "gitlab.example.com", "group/subgroup", "repo"},
+		{"https://github.com/owner//repo", "", "", ""},
+		{"file:///srv/repos/owner/repo.git", "local", "owner", "repo"},
+		{"file:///repo.git", "", "", ""},
+		{"https://github.com/owner", "", "", ""},
+		{"", "", "", ""},
+	}
+	for _, c := range cases {
+		host, owner, repo := ParseRepository(c.url)
+		require.Equal(t, []string{c.host, c.owner, c.repo}, []string{host, owner, repo}, c.url)
+	}
+}
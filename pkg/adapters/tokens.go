@@ -0,0 +1,10 @@
+package adapters
+
+// TokenSource provides the tokens of the repositories, which may differ by repository owner,
+// such as the installation tokens of a GitHub App installed on several accounts.
+type TokenSource interface {
+	// Token returns a valid token of the repository of the owner, and the identity this token
+	// authenticates as (e.g. the installation of a GitHub App), which does not change when the
+	// token rotates.
+	Token(owner, repo string) (token, identity string, err error)
+}
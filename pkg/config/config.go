@@ -1,7 +1,14 @@
 package config
 
 import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
 type GitAuthor struct {
@@ -13,12 +20,270 @@ type GitConfig struct {
 	Author GitAuthor `mapstructure:"author"`
 }
 
+// FileSource is the source of truth of a synchronized file.
+type FileSource struct {
+	Repository string `mapstructure:"repository"`
+	Path       string `mapstructure:"path"`
+	// Ref is the branch or tag to read the source from (default: "main").
+	Ref string `mapstructure:"ref"`
+	// Delims overrides the Go template delimiters (e.g. ["[[", "]]"]) for files
+	// that already contain "{{" sequences, such as GitHub workflows.
+	Delims []string `mapstructure:"delims"`
+}
+
+// FileTarget is a repository path that should be kept identical to the source.
+type FileTarget struct {
+	Repository string `mapstructure:"repository"`
+	Path       string `mapstructure:"path"`
+	// Variables are passed to the source when rendered as a Go template.
+	// When empty, the source is copied verbatim. The keys keep their case (e.g. ModulePath).
+	Variables map[string]string `mapstructure:"variables"`
+}
+
+// FileSync declares a file to keep synchronized across repositories.
+type FileSync struct {
+	Source     FileSource   `mapstructure:"source"`
+	Targets    []FileTarget `mapstructure:"targets"`
+	Executable bool         `mapstructure:"executable"`
+}
+
+// ActionsConfig configures the synchronization of GitHub Actions and reusable workflows
+// referenced by the workflows of the repositories.
+type ActionsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Repositories are additional repositories hosting actions or reusable workflows,
+	// tracked in addition to the Go repositories.
+	Repositories []string `mapstructure:"repositories"`
+}
+
+// DockerImage is a base image and the tag it should use.
+type DockerImage struct {
+	Name string `mapstructure:"name"`
+	Tag  string `mapstructure:"tag"`
+}
+
+// DockerfilesConfig configures the alignment of the Dockerfiles base images.
+type DockerfilesConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Paths are the Dockerfiles paths in the repositories (default: ["Dockerfile"]).
+	Paths []string `mapstructure:"paths"`
+	// GoVersion is the target version of the golang images. When empty, it is
+	// derived from the toolchain (or go) directive of each repository go.mod.
+	GoVersion string `mapstructure:"go_version"`
+	// Images are other base images to align, such as distroless ones.
+	Images     []DockerImage `mapstructure:"images"`
+	PinDigests bool          `mapstructure:"pin_digests"`
+}
+
+// ToolVersion is the version a tool module should use.
+type ToolVersion struct {
+	Module  string `mapstructure:"module"`
+	Version string `mapstructure:"version"`
+}
+
+// ToolsConfig configures the alignment of the tool dependencies (go.mod tool
+// directives and tools files) across repositories.
+type ToolsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Files are the tools files importing tools in the repositories (default: ["tools.go"]).
+	Files []string `mapstructure:"files"`
+	// Versions are the versions to enforce. Other tools are aligned to the highest version used.
+	Versions []ToolVersion `mapstructure:"versions"`
+	// Generate runs "go generate ./..." after the update (default: true).
+	Generate bool `mapstructure:"generate"`
+}
+
+// DependencyGroup is a rule batching the dependency updates of a service in a single merge request.
+type DependencyGroup struct {
+	Name string `mapstructure:"name"`
+	// Patterns match the dependencies module paths, as path.Match patterns or with a
+	// "/..." suffix for module path prefixes. When empty, every dependency matches.
+	Patterns []string `mapstructure:"patterns"`
+	// BumpTypes restricts the group to the given bump types (major, minor, patch).
+	// When empty, every bump type matches.
+	BumpTypes []string `mapstructure:"bump_types"`
+}
+
+// GroupingConfig configures the batching of the dependency updates of each service.
+type GroupingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Groups are matched in order, the first matching group is used. Dependencies matching
+	// no group are updated individually. When empty, all the updates of a service are batched.
+	Groups []DependencyGroup `mapstructure:"groups"`
+}
+
+// LightweightConfig configures the lightweight updates, applied by editing the go.mod and
+// go.sum files and committing them through the forge API, without cloning the repository.
+// The updates requiring "go mod tidy" are still applied by the runner.
+type LightweightConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Proxy is the URL of the Go module proxy (default: "https://proxy.golang.org").
+	Proxy string `mapstructure:"proxy"`
+	// SumDB is the checksum database, as the GOSUMDB variable: a known database name, a
+	// verifier key with an optional URL, or "off" (default: "sum.golang.org").
+	SumDB string `mapstructure:"sumdb"`
+	// Private are the module path patterns of the private modules, as the GOPRIVATE
+	// variable. Their updates are applied by the runner.
+	Private []string `mapstructure:"private"`
+}
+
+// VerificationConfig configures the verification of the updates, between the update and the
+// push of their branch.
+type VerificationConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Commands are run in order in the updated repository, with "sh -c". The branch is not
+	// pushed when one of them fails (default: "go mod tidy" and "go build ./...").
+	Commands []string `mapstructure:"commands"`
+}
+
+// StateConfig configures the store persisting the lifecycle of the updates between runs.
+type StateConfig struct {
+	// Backend is the store backend: "file" or "memory", which keeps no history between runs
+	// (default: "file").
+	Backend string `mapstructure:"backend"`
+	// Path is the path of the state file, for the "file" backend (default: ".depsync/state.json").
+	Path string `mapstructure:"path"`
+}
+
+// WebhookConfig configures the GitHub webhook receiver of the daemon mode.
+// The webhook secret is read from the GITHUB_WEBHOOK_SECRET environment variable.
+type WebhookConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Path is the path of the webhook endpoint (default: "/webhook").
+	Path string `mapstructure:"path"`
+}
+
+// ServeConfig configures the long-running daemon mode ("depsync serve").
+type ServeConfig struct {
+	// Address is the listen address of the health endpoint (default: ":8080").
+	Address string `mapstructure:"address"`
+	// Interval is the interval between full runs (default: 1h). Ignored when Cron is set.
+	Interval time.Duration `mapstructure:"interval"`
+	// Cron is a standard cron expression (e.g. "0 */2 * * *") scheduling the full runs.
+	Cron string `mapstructure:"cron"`
+	// Jitter is the maximum random delay added to each scheduled full run.
+	Jitter time.Duration `mapstructure:"jitter"`
+	// PRCheckInterval is the interval between checks of the open pull requests,
+	// between full runs (default: 10m). Zero disables the checks.
+	PRCheckInterval time.Duration `mapstructure:"pr_check_interval"`
+	Webhook         WebhookConfig `mapstructure:"webhook"`
+}
+
+// Discovery methods of the repositories go.mod, tools files and tags.
+const (
+	// DiscoveryREST uses a few REST API calls per repository.
+	DiscoveryREST = "rest"
+	// DiscoveryGraphQL uses GraphQL API queries batching several repositories.
+	DiscoveryGraphQL = "graphql"
+)
+
+// GitHubHost is a GitHub Enterprise Server host, whose repositories are designated by
+// their URLs on this host (e.g. https://ghe.example.com/org/repo).
+type GitHubHost struct {
+	// Host is the host of the repositories URLs (e.g. "ghe.example.com").
+	Host string `mapstructure:"host"`
+	// BaseURL is the API URL (default: "https://<host>/api/v3/").
+	BaseURL string `mapstructure:"base_url"`
+	// UploadURL is the uploads API URL (default: "https://<host>/api/uploads/").
+	UploadURL string `mapstructure:"upload_url"`
+	// TokenEnv is the environment variable containing the token of the host
+	// (default: the GitHub token).
+	TokenEnv string `mapstructure:"token_env"`
+}
+
+// GitHubAppConfig configures the authentication as a GitHub App, with installation tokens
+// minted and refreshed automatically, instead of the GITHUB_TOKEN token.
+type GitHubAppConfig struct {
+	// ID is the ID of the GitHub App. The authentication as a GitHub App is disabled when zero.
+	ID int64 `mapstructure:"id"`
+	// PrivateKeyPath is the path of the PEM private key of the GitHub App.
+	PrivateKeyPath string `mapstructure:"private_key_path"`
+	// InstallationID is the ID of the installation of the GitHub App used for all the
+	// repositories. When zero, the installation on the owner of each repository is used.
+	InstallationID int64 `mapstructure:"installation_id"`
+}
+
+// GitHubConfig configures the GitHub API client.
+type GitHubConfig struct {
+	// CacheDir is the directory of the conditional requests cache. The cache is disabled when empty.
+	CacheDir string `mapstructure:"cache_dir"`
+	// MaxRetries is the maximum number of retries of the rate limited requests (default: 3).
+	MaxRetries int `mapstructure:"max_retries"`
+	// MaxRetryWait is the maximum delay waited for a rate limit at once (default: 15m).
+	MaxRetryWait time.Duration `mapstructure:"max_retry_wait"`
+	// Discovery is the discovery method of the repositories: rest or graphql (default: rest).
+	Discovery string `mapstructure:"discovery"`
+	// Hosts are the GitHub Enterprise Server hosts, in addition to github.com.
+	Hosts []GitHubHost `mapstructure:"hosts"`
+	// App authenticates to github.com as a GitHub App (optional).
+	App GitHubAppConfig `mapstructure:"app"`
+}
+
+// Forge types.
+const (
+	// ForgeGitLab is a GitLab instance.
+	ForgeGitLab = "gitlab"
+	// ForgeGitea is a Gitea or Forgejo instance.
+	ForgeGitea = "gitea"
+	// ForgeLocal is a directory of bare repositories on the local filesystem, with
+	// simulated pull requests.
+	ForgeLocal = "local"
+)
+
+// LocalForgeHost is the host of the repositories of the local forge (file:// URLs).
+const LocalForgeHost = "local"
+
+// ForgeConfig is a forge other than GitHub, hosting the repositories of its host.
+type ForgeConfig struct {
+	// Type is the type of the forge: gitlab, gitea (also for Forgejo) or local.
+	Type string `mapstructure:"type"`
+	// Host is the host of the repositories URLs (e.g. "gitlab.example.com", default: "local" for local).
+	Host string `mapstructure:"host"`
+	// BaseURL is the API URL (default: "https://<host>/api/v4/" for gitlab, "https://<host>/api/v1/" for gitea).
+	BaseURL string `mapstructure:"base_url"`
+	// TokenEnv is the environment variable containing the token of the forge (not used by local).
+	TokenEnv string `mapstructure:"token_env"`
+	// Path is the directory of the bare repositories of a local forge, as <path>/<owner>/<repo>.git.
+	Path string `mapstructure:"path"`
+	// Checks is the simulated status of the checks of the new pull requests of a local
+	// forge: running, passed or failed (default: passed).
+	Checks string `mapstructure:"checks"`
+}
+
 type Config struct {
-	Repositories        []string  `mapstructure:"repositories"`
-	Git                 GitConfig `mapstructure:"git"`
-	DeleteConflictedPRs bool      `mapstructure:"delete_conflicted_prs"`
+	Repositories        []string           `mapstructure:"repositories"`
+	Git                 GitConfig          `mapstructure:"git"`
+	GitHub              GitHubConfig       `mapstructure:"github"`
+	Forges              []ForgeConfig      `mapstructure:"forges"`
+	DeleteConflictedPRs bool               `mapstructure:"delete_conflicted_prs"`
+	Files               []FileSync         `mapstructure:"files"`
+	Actions             ActionsConfig      `mapstructure:"actions"`
+	Dockerfiles         DockerfilesConfig  `mapstructure:"dockerfiles"`
+	Tools               ToolsConfig        `mapstructure:"tools"`
+	Grouping            GroupingConfig     `mapstructure:"grouping"`
+	Lightweight         LightweightConfig  `mapstructure:"lightweight"`
+	Verification        VerificationConfig `mapstructure:"verification"`
+	State               StateConfig        `mapstructure:"state"`
+	Serve               ServeConfig        `mapstructure:"serve"`
+	// Concurrency is the maximum number of repositories processed concurrently.
+	Concurrency int `mapstructure:"concurrency"`
+	// DryRun only computes the actions depsync would take, without side effects.
+	DryRun bool `mapstructure:"dry_run"`
+	// Runner runs the git and go operations: dagger or native (default: dagger).
+	Runner string `mapstructure:"runner"`
 }
 
+// Runners of the git and go operations.
+const (
+	// RunnerDagger runs the operations in containers of a Dagger engine.
+	RunnerDagger = "dagger"
+	// RunnerNative runs the git and go commands of the host, in temporary directories.
+	RunnerNative = "native"
+)
+
+// DefaultConcurrency is the default maximum number of repositories processed concurrently.
+const DefaultConcurrency = 4
+
 func Load(configPath string) (*Config, error) {
 	viper.SetConfigFile(configPath)
 	viper.SetConfigType("yaml")
@@ -32,11 +297,171 @@ func Load(configPath string) (*Config, error) {
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, err
 	}
+	if err := loadFileVariables(configPath, &config); err != nil {
+		return nil, err
+	}
 
 	// Set default value for DeleteConflictedPRs if not specified
 	if !viper.IsSet("delete_conflicted_prs") {
 		config.DeleteConflictedPRs = true
 	}
 
+	// Set default value for the files source ref if not specified
+	for i := range config.Files {
+		if config.Files[i].Source.Ref == "" {
+			config.Files[i].Source.Ref = adapters.DefaultBranch
+		}
+	}
+
+	// Set default value for the Dockerfiles paths if not specified
+	if len(config.Dockerfiles.Paths) == 0 {
+		config.Dockerfiles.Paths = []string{"Dockerfile"}
+	}
+
+	// Set default values for the tools if not specified
+	if len(config.Tools.Files) == 0 {
+		config.Tools.Files = []string{"tools.go"}
+	}
+	if !viper.IsSet("tools.generate") {
+		config.Tools.Generate = true
+	}
+
+	// Set default value for the discovery method if not specified
+	if config.GitHub.Discovery == "" {
+		config.GitHub.Discovery = DiscoveryREST
+	}
+
+	// Set default values for the GitHub Enterprise Server hosts if not specified
+	for i, host := range config.GitHub.Hosts {
+		if host.BaseURL == "" {
+			config.GitHub.Hosts[i].BaseURL = "https://" + host.Host + "/api/v3/"
+		}
+		if host.UploadURL == "" {
+			config.GitHub.Hosts[i].UploadURL = "https://" + host.Host + "/api/uploads/"
+		}
+	}
+
+	// Set default values for the forges API URLs if not specified
+	for i, forge := range config.Forges {
+		if forge.Type == ForgeLocal && forge.Host == "" {
+			config.Forges[i].Host = LocalForgeHost
+		}
+		if forge.BaseURL != "" {
+			continue
+		}
+		switch forge.Type {
+		case ForgeGitLab:
+			config.Forges[i].BaseURL = "https://" + forge.Host + "/api/v4/"
+		case ForgeGitea:
+			config.Forges[i].BaseURL = "https://" + forge.Host + "/api/v1/"
+		}
+	}
+
+	// Set default values for the lightweight updates if not specified
+	if config.Lightweight.Proxy == "" {
+		config.Lightweight.Proxy = "https://proxy.golang.org"
+	}
+	if config.Lightweight.SumDB == "" {
+		config.Lightweight.SumDB = "sum.golang.org"
+	}
+
+	// Set default value for the verification commands if not specified
+	if len(config.Verification.Commands) == 0 {
+		config.Verification.Commands = []string{"go mod tidy", "go build ./..."}
+	}
+
+	// Set default value for the runner if not specified
+	if config.Runner == "" {
+		config.Runner = RunnerDagger
+	}
+
+	// Set default value for the concurrency if not specified
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultConcurrency
+	}
+
+	// Set default values for the daemon mode if not specified
+	if config.Serve.Address == "" {
+		config.Serve.Address = ":8080"
+	}
+	if config.Serve.Interval == 0 {
+		config.Serve.Interval = time.Hour
+	}
+	if !viper.IsSet("serve.pr_check_interval") {
+		config.Serve.PRCheckInterval = 10 * time.Minute
+	}
+	if config.Serve.Webhook.Path == "" {
+		config.Serve.Webhook.Path = "/webhook"
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
 	return &config, nil
 }
+
+// Bump types of the dependency groups.
+const (
+	BumpMajor = "major"
+	BumpMinor = "minor"
+	BumpPatch = "patch"
+)
+
+// Validate checks the configuration values that cannot be defaulted.
+func (c *Config) Validate() error {
+	return c.Grouping.validate()
+}
+
+// validate checks that the groups have distinct names and known bump types, as a typo would
+// silently produce an empty or merged group.
+func (g GroupingConfig) validate() error {
+	names := make(map[string]bool, len(g.Groups))
+	for i, group := range g.Groups {
+		if group.Name == "" {
+			return fmt.Errorf("grouping group %d has no name", i+1)
+		}
+		name := strings.ToLower(group.Name)
+		if names[name] {
+			return fmt.Errorf("duplicate grouping group name %q", group.Name)
+		}
+		names[name] = true
+
+		for _, bump := range group.BumpTypes {
+			switch strings.ToLower(bump) {
+			case BumpMajor, BumpMinor, BumpPatch:
+			default:
+				return fmt.Errorf("unknown bump type %q in grouping group %q (expected %s, %s or %s)",
+					bump, group.Name, BumpMajor, BumpMinor, BumpPatch)
+			}
+		}
+	}
+	return nil
+}
+
+// loadFileVariables reads the variables of the synchronized files targets from the raw
+// configuration file, as viper lowercases the map keys, which are template field names.
+func loadFileVariables(configPath string, config *Config) error {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+	var raw struct {
+		Files []struct {
+			Targets []struct {
+				Variables map[string]string `yaml:"variables"`
+			} `yaml:"targets"`
+		} `yaml:"files"`
+	}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return fmt.Errorf("failed to parse files variables: %w", err)
+	}
+
+	for i, file := range raw.Files {
+		for j, target := range file.Targets {
+			if i < len(config.Files) && j < len(config.Files[i].Targets) && target.Variables != nil {
+				config.Files[i].Targets[j].Variables = target.Variables
+			}
+		}
+	}
+	return nil
+}
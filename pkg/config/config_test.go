@@ -5,6 +5,7 @@ package config
 
 import (
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -32,4 +33,277 @@ func TestLoad(t *testing.T) {
 	if cfg.Repositories[0] != "https://github.com/example/testrepo1.git" || cfg.Repositories[1] != "https://github.com/example/testrepo2.git" {
 		t.Errorf("unexpected repository URLs: %+v", cfg.Repositories)
 	}
+	if cfg.Concurrency != DefaultConcurrency {
+		t.Errorf("expected default concurrency to be %d, got %d", DefaultConcurrency, cfg.Concurrency)
+	}
+	if cfg.Runner != RunnerDagger {
+		t.Errorf("expected default runner to be %q, got %q", RunnerDagger, cfg.Runner)
+	}
+	if cfg.Lightweight.Enabled || cfg.Lightweight.Proxy != "https://proxy.golang.org" ||
+		cfg.Lightweight.SumDB != "sum.golang.org" {
+		t.Errorf("unexpected default lightweight updates: %+v", cfg.Lightweight)
+	}
+	if cfg.Verification.Enabled || len(cfg.Verification.Commands) != 2 {
+		t.Errorf("unexpected default verification: %+v", cfg.Verification)
+	}
+}
+
+const testFilesYAML = `
+files:
+  - source:
+      repository: https://github.com/example/templates.git
+      path: golangci/.golangci.yml
+    targets:
+      - repository: https://github.com/example/testrepo1.git
+        path: .golangci.yml
+        variables:
+          ModulePath: github.com/example/testrepo1
+`
+
+func TestLoad_Files(t *testing.T) {
+	dir := t.TempDir()
+	file := dir + "/depsync.yaml"
+	if err := os.WriteFile(file, []byte(testFilesYAML), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(file)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if len(cfg.Files) != 1 || len(cfg.Files[0].Targets) != 1 {
+		t.Fatalf("unexpected files configuration: %+v", cfg.Files)
+	}
+	if cfg.Files[0].Source.Ref != "main" {
+		t.Errorf("expected default source ref to be main, got %q", cfg.Files[0].Source.Ref)
+	}
+	if v := cfg.Files[0].Targets[0].Variables["ModulePath"]; v != "github.com/example/testrepo1" {
+		t.Errorf("unexpected target variables: %+v", cfg.Files[0].Targets[0].Variables)
+	}
+}
+
+const testFileVariablesYAML = `
+files:
+  - source:
+      repository: https://github.com/example/templates.git
+      path: golang/ci.yaml
+    targets:
+      - repository: https://github.com/example/repo1.git
+        path: ci.yaml
+        variables:
+          ModulePath: github.com/example/repo1
+          go_version: "1.24"
+`
+
+func TestLoad_FileVariables(t *testing.T) {
+	dir := t.TempDir()
+	file := dir + "/depsync.yaml"
+	if err := os.WriteFile(file, []byte(testFileVariablesYAML), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(file)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// The mixed-case keys are kept, as templates reference them as written
+	expected := map[string]string{"ModulePath": "github.com/example/repo1", "go_version": "1.24"}
+	if variables := cfg.Files[0].Targets[0].Variables; !reflect.DeepEqual(variables, expected) {
+		t.Errorf("expected variables %+v, got %+v", expected, variables)
+	}
+}
+
+const testToolsYAML = `
+tools:
+  enabled: true
+  versions:
+    - module: go.uber.org/mock
+      version: v0.5.2
+`
+
+func TestLoad_Tools(t *testing.T) {
+	dir := t.TempDir()
+	file := dir + "/depsync.yaml"
+	if err := os.WriteFile(file, []byte(testToolsYAML), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(file)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if !cfg.Tools.Enabled || !cfg.Tools.Generate {
+		t.Errorf("expected tools to be enabled with generation, got %+v", cfg.Tools)
+	}
+	if len(cfg.Tools.Files) != 1 || cfg.Tools.Files[0] != "tools.go" {
+		t.Errorf("unexpected default tools files: %+v", cfg.Tools.Files)
+	}
+	if len(cfg.Tools.Versions) != 1 || cfg.Tools.Versions[0].Version != "v0.5.2" {
+		t.Errorf("unexpected tools versions: %+v", cfg.Tools.Versions)
+	}
+}
+
+const testGitHubHostsYAML = `
+github:
+  hosts:
+    - host: ghe.example.com
+      token_env: GHE_TOKEN
+`
+
+func TestLoad_GitHubHosts(t *testing.T) {
+	dir := t.TempDir()
+	file := dir + "/depsync.yaml"
+	if err := os.WriteFile(file, []byte(testGitHubHostsYAML), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(file)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if len(cfg.GitHub.Hosts) != 1 {
+		t.Fatalf("unexpected GitHub hosts: %+v", cfg.GitHub.Hosts)
+	}
+	host := cfg.GitHub.Hosts[0]
+	if host.BaseURL != "https://ghe.example.com/api/v3/" || host.UploadURL != "https://ghe.example.com/api/uploads/" {
+		t.Errorf("unexpected default API URLs: %+v", host)
+	}
+	if host.TokenEnv != "GHE_TOKEN" {
+		t.Errorf("expected token env to be GHE_TOKEN, got %q", host.TokenEnv)
+	}
+}
+
+const testForgesYAML = `
+forges:
+  - type: gitea
+    host: forgejo.example.com
+    token_env: FORGEJO_TOKEN
+  - type: local
+    path: /srv/repos
+`
+
+func TestLoad_Forges(t *testing.T) {
+	dir := t.TempDir()
+	file := dir + "/depsync.yaml"
+	if err := os.WriteFile(file, []byte(testForgesYAML), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(file)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if len(cfg.Forges) != 2 {
+		t.Fatalf("unexpected forges: %+v", cfg.Forges)
+	}
+	if cfg.Forges[0].BaseURL != "https://forgejo.example.com/api/v1/" {
+		t.Errorf("unexpected default API URL: %q", cfg.Forges[0].BaseURL)
+	}
+	if cfg.Forges[1].Host != LocalForgeHost || cfg.Forges[1].Path != "/srv/repos" {
+		t.Errorf("unexpected local forge: %+v", cfg.Forges[1])
+	}
+}
+
+const testLightweightYAML = `
+lightweight:
+  enabled: true
+  sumdb: "off"
+  private:
+    - github.com/example/*
+`
+
+func TestLoad_Lightweight(t *testing.T) {
+	dir := t.TempDir()
+	file := dir + "/depsync.yaml"
+	if err := os.WriteFile(file, []byte(testLightweightYAML), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(file)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if !cfg.Lightweight.Enabled || cfg.Lightweight.SumDB != "off" {
+		t.Errorf("unexpected lightweight updates: %+v", cfg.Lightweight)
+	}
+	if cfg.Lightweight.Proxy != "https://proxy.golang.org" {
+		t.Errorf("unexpected default proxy: %q", cfg.Lightweight.Proxy)
+	}
+	if len(cfg.Lightweight.Private) != 1 || cfg.Lightweight.Private[0] != "github.com/example/*" {
+		t.Errorf("unexpected private modules: %+v", cfg.Lightweight.Private)
+	}
+}
+
+const testVerificationYAML = `
+verification:
+  enabled: true
+  commands:
+    - go vet ./...
+    - go test ./...
+`
+
+func TestLoad_Verification(t *testing.T) {
+	dir := t.TempDir()
+	file := dir + "/depsync.yaml"
+	if err := os.WriteFile(file, []byte(testVerificationYAML), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(file)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if !cfg.Verification.Enabled {
+		t.Errorf("expected verification to be enabled")
+	}
+	if len(cfg.Verification.Commands) != 2 || cfg.Verification.Commands[1] != "go test ./..." {
+		t.Errorf("unexpected verification commands: %+v", cfg.Verification.Commands)
+	}
+}
+
+func TestLoad_GroupingValidation(t *testing.T) {
+	cases := map[string]string{
+		"missing name": `
+grouping:
+  groups:
+    - patterns: [github.com/example/...]
+`,
+		"duplicate name": `
+grouping:
+  groups:
+    - name: internal
+    - name: Internal
+`,
+		"unknown bump type": `
+grouping:
+  groups:
+    - name: internal
+      bump_types: [minor, pach]
+`,
+	}
+	for name, content := range cases {
+		file := t.TempDir() + "/depsync.yaml"
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test config: %v", err)
+		}
+		if _, err := Load(file); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+
+	file := t.TempDir() + "/depsync.yaml"
+	content := "grouping:\n  groups:\n    - name: internal\n      bump_types: [Minor, patch]\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+	if _, err := Load(file); err != nil {
+		t.Errorf("unexpected validation error: %v", err)
+	}
 }
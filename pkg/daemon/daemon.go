@@ -0,0 +1,261 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// shutdownTimeout is the maximum duration of the health server shutdown.
+const shutdownTimeout = 5 * time.Second
+
+// triggersQueueSize is the maximum number of triggered jobs waiting for execution.
+const triggersQueueSize = 100
+
+// Runner is the workflow run by the daemon.
+type Runner interface {
+	// Run runs the full workflow.
+	Run(ctx context.Context) error
+	// CheckPullRequests follows up on the open pull requests, without rescanning the repositories.
+	CheckPullRequests(ctx context.Context) error
+}
+
+// RunStatus is the status of a run.
+type RunStatus struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Health is the status exposed by the health endpoint.
+type Health struct {
+	// Status is "ok", or "degraded" when the last full run or pull requests check failed.
+	Status      string     `json:"status"`
+	StartedAt   time.Time  `json:"started_at"`
+	NextRun     time.Time  `json:"next_run"`
+	LastRun     *RunStatus `json:"last_run,omitempty"`
+	LastPRCheck *RunStatus `json:"last_pr_check,omitempty"`
+	// LastTrigger is the status of the last triggered job (e.g. by a webhook).
+	LastTrigger *RunStatus `json:"last_trigger,omitempty"`
+}
+
+// trigger is a job triggered outside of the schedule.
+type trigger struct {
+	name string
+	job  func(context.Context) error
+}
+
+// Daemon runs the workflow on a schedule, until its context is canceled.
+type Daemon struct {
+	runner     Runner
+	schedule   Schedule
+	jitter     time.Duration
+	prInterval time.Duration
+	address    string
+	mux        *http.ServeMux
+	triggers   chan trigger
+
+	mu     sync.Mutex
+	health Health
+	random func(n int64) int64
+}
+
+// New creates a new Daemon.
+func New(runner Runner, cfg config.ServeConfig) (*Daemon, error) {
+	schedule, err := NewSchedule(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Daemon{
+		runner:     runner,
+		schedule:   schedule,
+		jitter:     cfg.Jitter,
+		prInterval: cfg.PRCheckInterval,
+		address:    cfg.Address,
+		mux:        http.NewServeMux(),
+		triggers:   make(chan trigger, triggersQueueSize),
+		health:     Health{Status: "ok"},
+		random:     rand.Int63n,
+	}
+	d.mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(d.Health())
+	})
+	return d, nil
+}
+
+// Handle registers an additional handler on the daemon HTTP server (e.g. a webhook receiver).
+func (d *Daemon) Handle(pattern string, handler http.Handler) {
+	d.mux.Handle(pattern, handler)
+}
+
+// Trigger queues a job, executed between the scheduled ones. It returns false if the queue is full.
+func (d *Daemon) Trigger(name string, job func(ctx context.Context) error) bool {
+	select {
+	case d.triggers <- trigger{name: name, job: job}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run starts the health endpoint and runs the workflow on schedule, starting immediately.
+// It returns when the context is canceled. Run errors are logged and exposed by the health
+// endpoint, but do not stop the daemon.
+func (d *Daemon) Run(ctx context.Context) error {
+	d.mu.Lock()
+	d.health.StartedAt = time.Now().UTC()
+	d.mu.Unlock()
+
+	errCh := make(chan error, 1)
+	server := &http.Server{
+		Addr:              d.address,
+		Handler:           d.Handler(),
+		ReadHeaderTimeout: shutdownTimeout,
+	}
+	if d.address != "" {
+		go func() {
+			logging.C(ctx).Info("Starting health endpoint", zap.String("address", d.address))
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errCh <- fmt.Errorf("health endpoint failed: %w", err)
+			}
+		}()
+	}
+
+	err := d.loop(ctx, errCh)
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+	defer cancel()
+	if shutdownErr := server.Shutdown(shutdownCtx); shutdownErr != nil {
+		logging.C(ctx).Error("Failed to shut down health endpoint", zap.Error(shutdownErr))
+	}
+	return err
+}
+
+// loop runs the full runs and the pull requests checks until the context is canceled.
+func (d *Daemon) loop(ctx context.Context, errCh <-chan error) error {
+	nextRun := time.Now()
+	nextCheck := time.Time{}
+	for {
+		d.mu.Lock()
+		d.health.NextRun = nextRun
+		d.mu.Unlock()
+
+		wakeUp := nextRun
+		if !nextCheck.IsZero() && nextCheck.Before(wakeUp) {
+			wakeUp = nextCheck
+		}
+
+		timer := time.NewTimer(time.Until(wakeUp))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case err := <-errCh:
+			timer.Stop()
+			return err
+		case t := <-d.triggers:
+			timer.Stop()
+			d.execute(ctx, t.name, t.job, &d.health.LastTrigger)
+			continue
+		case <-timer.C:
+		}
+
+		if !time.Now().Before(nextRun) {
+			d.execute(ctx, "full run", d.runner.Run, &d.health.LastRun)
+			nextRun = d.next(time.Now())
+		} else {
+			d.execute(ctx, "pull requests check", d.runner.CheckPullRequests, &d.health.LastPRCheck)
+		}
+
+		nextCheck = time.Time{}
+		if d.prInterval > 0 {
+			nextCheck = time.Now().Add(d.prInterval)
+		}
+	}
+}
+
+// next returns the time of the next full run, with a random jitter.
+func (d *Daemon) next(now time.Time) time.Time {
+	next := d.schedule.Next(now)
+	if d.jitter > 0 {
+		next = next.Add(time.Duration(d.random(int64(d.jitter))))
+	}
+	return next
+}
+
+// execute runs a job and records its status. Errors and panics are logged and recorded,
+// so that the daemon survives them.
+func (d *Daemon) execute(ctx context.Context, name string, job func(context.Context) error, status **RunStatus) {
+	logger := logging.C(ctx).WithOptions(zap.Fields(zap.String("job", name)))
+	logger.Info("Starting scheduled job")
+
+	started := time.Now().UTC()
+	err := runSafely(ctx, job)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	*status = &RunStatus{StartedAt: started, FinishedAt: time.Now().UTC()}
+	if err != nil {
+		logger.Error("Scheduled job failed", zap.Error(err))
+		(*status).Error = err.Error()
+	} else {
+		logger.Info("Scheduled job completed successfully")
+	}
+	d.health.Status = "ok"
+	if failed(d.health.LastRun) || failed(d.health.LastPRCheck) {
+		d.health.Status = "degraded"
+	}
+}
+
+// runSafely runs the job, converting panics into errors.
+func runSafely(ctx context.Context, job func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job(ctx)
+}
+
+// failed checks if the run failed.
+func failed(status *RunStatus) bool {
+	return status != nil && status.Error != ""
+}
+
+// Health returns the current health of the daemon.
+func (d *Daemon) Health() Health {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	h := d.health
+	if h.LastRun != nil {
+		lastRun := *h.LastRun
+		h.LastRun = &lastRun
+	}
+	if h.LastPRCheck != nil {
+		lastPRCheck := *h.LastPRCheck
+		h.LastPRCheck = &lastPRCheck
+	}
+	if h.LastTrigger != nil {
+		lastTrigger := *h.LastTrigger
+		h.LastTrigger = &lastTrigger
+	}
+	return h
+}
+
+// Handler returns the HTTP handler of the daemon, serving the health endpoint on /healthz.
+// The endpoint answers 200 as long as the daemon is running, with its health as JSON body.
+func (d *Daemon) Handler() http.Handler {
+	return d.mux
+}
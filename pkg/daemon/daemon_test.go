@@ -0,0 +1,129 @@
+//go:build unit
+// +build unit
+
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner counts the calls and fails the full runs.
+type fakeRunner struct {
+	runs   atomic.Int32
+	checks atomic.Int32
+}
+
+func (r *fakeRunner) Run(_ context.Context) error {
+	if r.runs.Add(1) == 1 {
+		panic("boom")
+	}
+	return errors.New("run failed")
+}
+
+func (r *fakeRunner) CheckPullRequests(_ context.Context) error {
+	r.checks.Add(1)
+	return nil
+}
+
+func TestNewSchedule(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 30, 0, 0, time.UTC)
+
+	s, err := NewSchedule(config.ServeConfig{Interval: time.Hour})
+	require.NoError(t, err)
+	require.Equal(t, start.Add(time.Hour), s.Next(start))
+
+	s, err = NewSchedule(config.ServeConfig{Interval: time.Hour, Cron: "0 */2 * * *"})
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), s.Next(start))
+
+	_, err = NewSchedule(config.ServeConfig{Cron: "every day"})
+	require.Error(t, err)
+
+	_, err = NewSchedule(config.ServeConfig{})
+	require.Error(t, err)
+}
+
+func TestDaemon_Next_Jitter(t *testing.T) {
+	d, err := New(&fakeRunner{}, config.ServeConfig{Interval: time.Hour, Jitter: time.Minute})
+	require.NoError(t, err)
+	d.random = func(n int64) int64 {
+		require.Equal(t, int64(time.Minute), n)
+		return int64(30 * time.Second)
+	}
+
+	start := time.Date(2025, 1, 1, 10, 30, 0, 0, time.UTC)
+	require.Equal(t, start.Add(time.Hour+30*time.Second), d.next(start))
+}
+
+func TestDaemon_Run_SurvivesErrors(t *testing.T) {
+	runner := &fakeRunner{}
+	d, err := New(runner, config.ServeConfig{
+		Interval:        50 * time.Millisecond,
+		PRCheckInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	require.NoError(t, d.Run(ctx))
+
+	require.GreaterOrEqual(t, runner.runs.Load(), int32(2))
+	require.Greater(t, runner.checks.Load(), runner.runs.Load())
+
+	h := d.Health()
+	require.Equal(t, "degraded", h.Status)
+	require.NotNil(t, h.LastRun)
+	require.Equal(t, "run failed", h.LastRun.Error)
+	require.NotNil(t, h.LastPRCheck)
+	require.Empty(t, h.LastPRCheck.Error)
+}
+
+func TestDaemon_Handler(t *testing.T) {
+	d, err := New(&fakeRunner{}, config.ServeConfig{Interval: time.Hour})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	d.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var h Health
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &h))
+	require.Equal(t, "ok", h.Status)
+	require.Nil(t, h.LastRun)
+}
+
+func TestDaemon_Trigger(t *testing.T) {
+	d, err := New(&fakeRunner{}, config.ServeConfig{Interval: time.Hour})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	require.True(t, d.Trigger("test", func(context.Context) error {
+		close(done)
+		return errors.New("trigger failed")
+	}))
+
+	go func() {
+		<-done
+		// Let the daemon record the status before stopping it
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	require.NoError(t, d.Run(ctx))
+
+	h := d.Health()
+	require.NotNil(t, h.LastTrigger)
+	require.Equal(t, "trigger failed", h.LastTrigger.Error)
+}
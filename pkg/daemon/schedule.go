@@ -0,0 +1,42 @@
+package daemon
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule returns the time of the next full run.
+type Schedule interface {
+	// Next returns the next activation time, later than the given time.
+	Next(t time.Time) time.Time
+}
+
+// intervalSchedule schedules runs at a fixed interval.
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+// Next implements the Schedule interface.
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+// NewSchedule creates the schedule of the full runs from the configuration:
+// the cron expression if set, the interval otherwise.
+func NewSchedule(cfg config.ServeConfig) (Schedule, error) {
+	if cfg.Cron != "" {
+		schedule, err := cron.ParseStandard(cfg.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", cfg.Cron, err)
+		}
+		return schedule, nil
+	}
+
+	if cfg.Interval <= 0 {
+		return nil, fmt.Errorf("invalid interval %s", cfg.Interval)
+	}
+	return intervalSchedule{interval: cfg.Interval}, nil
+}
@@ -3,6 +3,7 @@ package depgraph
 
 import (
 	"fmt"
+	"strings"
 
 	"golang.org/x/mod/modfile"
 )
@@ -11,6 +12,8 @@ import (
 type RepoModule struct {
 	RepoURL      string
 	GoModContent []byte
+	// ToolsFiles are the files importing the tools of the module (e.g. tools.go), by path.
+	ToolsFiles map[string][]byte
 }
 
 // GraphBuilder defines the interface for building dependency graphs.
@@ -18,19 +21,35 @@ type GraphBuilder interface {
 	BuildGraph(modules map[string]RepoModule) (map[string]*Service, error)
 }
 
-type graphBuilder struct{}
+// Options contains the options of the graph builder.
+type Options struct {
+	// AlignTools parses the tools of the modules, which are then aligned separately from
+	// the dependencies and excluded from them.
+	AlignTools bool
+}
+
+type graphBuilder struct {
+	opts Options
+}
 
 func NewGraphBuilder() GraphBuilder {
-	return &graphBuilder{}
+	return NewGraphBuilderWithOptions(Options{})
+}
+
+// NewGraphBuilderWithOptions creates a graph builder with the given options.
+func NewGraphBuilderWithOptions(opts Options) GraphBuilder {
+	return &graphBuilder{opts: opts}
 }
 
 func (g *graphBuilder) BuildGraph(modules map[string]RepoModule) (map[string]*Service, error) {
 	// First pass: create all Service nodes (no dependencies yet)
 	services := make(map[string]*Service)
-	for modulePath := range modules {
+	for modulePath, repo := range modules {
 		services[modulePath] = &Service{
 			ModulePath:    modulePath,
+			RepoURL:       repo.RepoURL,
 			Dependencies:  make(map[string]Dependency),
+			Tools:         make(map[string]Tool),
 			LatestVersion: "",
 		}
 	}
@@ -41,8 +60,24 @@ func (g *graphBuilder) BuildGraph(modules map[string]RepoModule) (map[string]*Se
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse go.mod for %s: %w", modulePath, err)
 		}
+		services[modulePath].GoVersion = goVersion(mf)
+
+		// Without tools alignment, the tool modules are updated as the other dependencies
+		tools := map[string]Tool{}
+		if g.opts.AlignTools {
+			tools, err = parseTools(mf, repo.ToolsFiles)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse tools for %s: %w", modulePath, err)
+			}
+		}
+		services[modulePath].Tools = tools
+
 		for _, req := range mf.Require {
 			depPath := req.Mod.Path
+			if _, ok := tools[depPath]; ok {
+				// Tool modules are aligned separately from the dependencies
+				continue
+			}
 			if depService, ok := services[depPath]; ok {
 				services[modulePath].Dependencies[depPath] = Dependency{
 					Service:        depService,
@@ -54,3 +89,15 @@ func (g *graphBuilder) BuildGraph(modules map[string]RepoModule) (map[string]*Se
 	}
 	return services, nil
 }
+
+// goVersion returns the Go version required by the go.mod: the toolchain directive
+// if any, the go directive otherwise.
+func goVersion(mf *modfile.File) string {
+	if mf.Toolchain != nil {
+		return strings.TrimPrefix(mf.Toolchain.Name, "go")
+	}
+	if mf.Go != nil {
+		return mf.Go.Version
+	}
+	return ""
+}
@@ -85,3 +85,105 @@ require github.com/external/X v1.2.3
 	require.Equal(t, "v1.0.0", dep.CurrentVersion)
 	require.NotContains(t, a.Dependencies, "github.com/external/X")
 }
+
+func TestBuildGraph_GoVersion(t *testing.T) {
+	modA := []byte(`module github.com/example/A
+go 1.24.0
+toolchain go1.24.5
+`)
+	modB := []byte(`module github.com/example/B
+go 1.23
+`)
+	modules := map[string]RepoModule{
+		"github.com/example/A": {RepoURL: "https://github.com/example/A.git", GoModContent: modA},
+		"github.com/example/B": {RepoURL: "https://github.com/example/B.git", GoModContent: modB},
+	}
+	graph, err := NewGraphBuilder().BuildGraph(modules)
+	require.NoError(t, err)
+	require.Equal(t, "1.24.5", graph["github.com/example/A"].GoVersion)
+	require.Equal(t, "1.23", graph["github.com/example/B"].GoVersion)
+	require.Equal(t, "https://github.com/example/A.git", graph["github.com/example/A"].RepoURL)
+}
+
+func TestBuildGraph_Tools(t *testing.T) {
+	modA := []byte(`module github.com/example/A
+go 1.24
+tool go.uber.org/mock/mockgen
+require (
+	github.com/example/B v1.0.0
+	github.com/example/gen v0.2.0
+	go.uber.org/mock v0.5.0
+	github.com/golangci/golangci-lint v1.64.0
+)
+`)
+	toolsA := []byte(`//go:build tools
+
+package tools
+
+import (
+	_ "github.com/example/gen/cmd/gen"
+	_ "github.com/golangci/golangci-lint/cmd/golangci-lint"
+)
+`)
+	modB := []byte(`module github.com/example/B
+`)
+	modGen := []byte(`module github.com/example/gen
+`)
+	modules := map[string]RepoModule{
+		"github.com/example/A": {
+			RepoURL:      "https://github.com/example/A.git",
+			GoModContent: modA,
+			ToolsFiles:   map[string][]byte{"tools.go": toolsA},
+		},
+		"github.com/example/B":   {RepoURL: "https://github.com/example/B.git", GoModContent: modB},
+		"github.com/example/gen": {RepoURL: "https://github.com/example/gen.git", GoModContent: modGen},
+	}
+	graph, err := NewGraphBuilderWithOptions(Options{AlignTools: true}).BuildGraph(modules)
+	require.NoError(t, err)
+
+	a := graph["github.com/example/A"]
+	require.Equal(t, map[string]Tool{
+		"go.uber.org/mock": {
+			Module:   "go.uber.org/mock",
+			Version:  "v0.5.0",
+			Packages: []string{"go.uber.org/mock/mockgen"},
+			Source:   ToolSourceDirective,
+		},
+		"github.com/example/gen": {
+			Module:   "github.com/example/gen",
+			Version:  "v0.2.0",
+			Packages: []string{"github.com/example/gen/cmd/gen"},
+			Source:   ToolSourceFile,
+		},
+		"github.com/golangci/golangci-lint": {
+			Module:   "github.com/golangci/golangci-lint",
+			Version:  "v1.64.0",
+			Packages: []string{"github.com/golangci/golangci-lint/cmd/golangci-lint"},
+			Source:   ToolSourceFile,
+		},
+	}, a.Tools)
+	require.Contains(t, a.Dependencies, "github.com/example/B")
+	require.NotContains(t, a.Dependencies, "github.com/example/gen")
+}
+
+func TestBuildGraph_ToolsAlignmentDisabled(t *testing.T) {
+	modA := []byte(`module github.com/example/A
+go 1.24
+tool github.com/example/gen/cmd/gen
+require github.com/example/gen v0.2.0
+`)
+	modGen := []byte(`module github.com/example/gen
+`)
+	modules := map[string]RepoModule{
+		"github.com/example/A":   {RepoURL: "https://github.com/example/A.git", GoModContent: modA},
+		"github.com/example/gen": {RepoURL: "https://github.com/example/gen.git", GoModContent: modGen},
+	}
+	graph, err := NewGraphBuilder().BuildGraph(modules)
+	require.NoError(t, err)
+
+	// The tracked module used as a tool is still a dependency to update
+	a := graph["github.com/example/A"]
+	require.Empty(t, a.Tools)
+	require.Contains(t, a.Dependencies, "github.com/example/gen")
+	require.Equal(t, "v0.2.0", a.Dependencies["github.com/example/gen"].CurrentVersion)
+}
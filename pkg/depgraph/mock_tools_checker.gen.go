@@ -0,0 +1,55 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/cryptellation/depsync/pkg/depgraph (interfaces: ToolsChecker)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_tools_checker.gen.go -package=depgraph . ToolsChecker
+//
+
+// Package depgraph is a generated GoMock package.
+package depgraph
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockToolsChecker is a mock of ToolsChecker interface.
+type MockToolsChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockToolsCheckerMockRecorder
+	isgomock struct{}
+}
+
+// MockToolsCheckerMockRecorder is the mock recorder for MockToolsChecker.
+type MockToolsCheckerMockRecorder struct {
+	mock *MockToolsChecker
+}
+
+// NewMockToolsChecker creates a new mock instance.
+func NewMockToolsChecker(ctrl *gomock.Controller) *MockToolsChecker {
+	mock := &MockToolsChecker{ctrl: ctrl}
+	mock.recorder = &MockToolsCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockToolsChecker) EXPECT() *MockToolsCheckerMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockToolsChecker) Check(graph map[string]*Service, versions map[string]string) (map[string]map[string]Mismatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", graph, versions)
+	ret0, _ := ret[0].(map[string]map[string]Mismatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockToolsCheckerMockRecorder) Check(graph, versions any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockToolsChecker)(nil).Check), graph, versions)
+}
@@ -6,11 +6,32 @@ type Dependency struct {
 	CurrentVersion string
 }
 
+// ToolSource is where a tool dependency is declared.
+type ToolSource string
+
+const (
+	// ToolSourceDirective is a tool declared with the go.mod tool directive (Go 1.24+).
+	ToolSourceDirective ToolSource = "directive"
+	// ToolSourceFile is a tool imported by a tools file (e.g. tools.go).
+	ToolSourceFile ToolSource = "file"
+)
+
+// Tool represents a module required by a service for its tools (e.g. code generators).
+type Tool struct {
+	Module   string
+	Version  string
+	Packages []string // Tool packages provided by the module (e.g. go.uber.org/mock/mockgen)
+	Source   ToolSource
+}
+
 // Service represents a Go module/service in the dependency graph.
 type Service struct {
 	ModulePath    string
+	RepoURL       string
+	GoVersion     string // Go toolchain version required by the go.mod (e.g. 1.24.5)
 	Dependencies  map[string]Dependency
-	LatestVersion string // Latest detected semantic version tag
+	Tools         map[string]Tool // Tool modules, by module path (not part of Dependencies)
+	LatestVersion string          // Latest detected semantic version tag
 }
 
 // Mismatch represents a version inconsistency between the actual and latest version of a dependency.
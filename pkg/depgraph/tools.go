@@ -0,0 +1,77 @@
+package depgraph
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// parseTools returns the tool modules of a go.mod, declared either with tool directives
+// or imported by the given tools files (e.g. tools.go), by module path.
+func parseTools(mf *modfile.File, toolsFiles map[string][]byte) (map[string]Tool, error) {
+	tools := make(map[string]Tool)
+	for _, t := range mf.Tool {
+		addTool(tools, mf, t.Path, ToolSourceDirective)
+	}
+
+	paths := make([]string, 0, len(toolsFiles))
+	for path := range toolsFiles {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		f, err := parser.ParseFile(token.NewFileSet(), path, toolsFiles[path], parser.ImportsOnly)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		for _, imp := range f.Imports {
+			pkg, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse import %s of %s: %w", imp.Path.Value, path, err)
+			}
+			addTool(tools, mf, pkg, ToolSourceFile)
+		}
+	}
+	return tools, nil
+}
+
+// addTool adds the tool package to the module requiring it. Packages that are not
+// provided by a required module (e.g. standard library) are ignored.
+func addTool(tools map[string]Tool, mf *modfile.File, pkg string, source ToolSource) {
+	req := requireFor(mf, pkg)
+	if req == nil {
+		return
+	}
+
+	tool, ok := tools[req.Mod.Path]
+	if !ok {
+		tool = Tool{
+			Module:  req.Mod.Path,
+			Version: req.Mod.Version,
+			Source:  source,
+		}
+	}
+	tool.Packages = append(tool.Packages, pkg)
+	tools[req.Mod.Path] = tool
+}
+
+// requireFor returns the requirement of the module providing the package, using the
+// longest matching module path.
+func requireFor(mf *modfile.File, pkg string) *modfile.Require {
+	var res *modfile.Require
+	for _, req := range mf.Require {
+		path := req.Mod.Path
+		if pkg != path && !strings.HasPrefix(pkg, path+"/") {
+			continue
+		}
+		if res == nil || len(path) > len(res.Mod.Path) {
+			res = req
+		}
+	}
+	return res
+}
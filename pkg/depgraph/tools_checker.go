@@ -0,0 +1,103 @@
+package depgraph
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ToolsChecker checks for tool versions that are not aligned across the services of a dependency graph.
+//
+//go:generate go run go.uber.org/mock/mockgen@v0.5.2 -destination=mock_tools_checker.gen.go -package=depgraph . ToolsChecker
+type ToolsChecker interface {
+	// Check returns a map of service module path to tool module path to Mismatch.
+	// Tools are aligned to the given versions (by module path) when set, to the highest version
+	// used across the services (or latest tag for tools of the graph) otherwise.
+	// Only mismatches are included in the result.
+	Check(graph map[string]*Service, versions map[string]string) (map[string]map[string]Mismatch, error)
+}
+
+// toolsChecker is the default implementation of ToolsChecker.
+type toolsChecker struct{}
+
+// NewToolsChecker creates a new ToolsChecker.
+func NewToolsChecker() ToolsChecker {
+	return &toolsChecker{}
+}
+
+// Check implements the ToolsChecker interface.
+func (c *toolsChecker) Check(graph map[string]*Service, versions map[string]string) (
+	map[string]map[string]Mismatch, error) {
+	targets, err := toolTargets(graph, versions)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]map[string]Mismatch)
+	for svcPath, svc := range graph {
+		if svc == nil {
+			continue
+		}
+		for toolPath, tool := range svc.Tools {
+			target := targets[toolPath]
+			if target == nil || tool.Version == target.Original() {
+				continue
+			}
+			actual, err := semver.NewVersion(tool.Version)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse version '%s' for tool '%s' in service '%s': %w",
+					tool.Version, toolPath, svcPath, err)
+			}
+			// Configured versions are enforced, highest versions only upgrade
+			if _, pinned := versions[toolPath]; !pinned && !actual.LessThan(target) {
+				continue
+			}
+
+			if result[svcPath] == nil {
+				result[svcPath] = make(map[string]Mismatch)
+			}
+			result[svcPath][toolPath] = Mismatch{
+				Actual: tool.Version,
+				Latest: target.Original(),
+			}
+		}
+	}
+	return result, nil
+}
+
+// toolTargets returns the target version of each tool module.
+func toolTargets(graph map[string]*Service, versions map[string]string) (map[string]*semver.Version, error) {
+	targets := make(map[string]*semver.Version)
+	for toolPath, version := range versions {
+		v, err := semver.NewVersion(version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse configured version '%s' for tool '%s': %w", version, toolPath, err)
+		}
+		targets[toolPath] = v
+	}
+
+	for _, svc := range graph {
+		if svc == nil {
+			continue
+		}
+		for toolPath, tool := range svc.Tools {
+			if _, pinned := versions[toolPath]; pinned {
+				continue
+			}
+			candidates := []string{tool.Version}
+			if toolSvc, ok := graph[toolPath]; ok && toolSvc.LatestVersion != "" {
+				candidates = append(candidates, toolSvc.LatestVersion)
+			}
+			for _, candidate := range candidates {
+				v, err := semver.NewVersion(candidate)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse version '%s' for tool '%s': %w", candidate, toolPath, err)
+				}
+				if targets[toolPath] == nil || targets[toolPath].LessThan(v) {
+					targets[toolPath] = v
+				}
+			}
+		}
+	}
+	return targets, nil
+}
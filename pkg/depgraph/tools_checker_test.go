@@ -0,0 +1,61 @@
+//go:build unit
+// +build unit
+
+package depgraph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolsChecker_HighestVersion(t *testing.T) {
+	gen := &Service{ModulePath: "github.com/example/gen", LatestVersion: "v0.3.0"}
+	a := &Service{ModulePath: "github.com/example/A", Tools: map[string]Tool{
+		"go.uber.org/mock":       {Module: "go.uber.org/mock", Version: "v0.5.2"},
+		"github.com/example/gen": {Module: "github.com/example/gen", Version: "v0.2.0"},
+	}}
+	b := &Service{ModulePath: "github.com/example/B", Tools: map[string]Tool{
+		"go.uber.org/mock": {Module: "go.uber.org/mock", Version: "v0.4.0"},
+	}}
+	graph := map[string]*Service{
+		"github.com/example/A":   a,
+		"github.com/example/B":   b,
+		"github.com/example/gen": gen,
+	}
+
+	mismatches, err := NewToolsChecker().Check(graph, nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]map[string]Mismatch{
+		"github.com/example/A": {
+			"github.com/example/gen": {Actual: "v0.2.0", Latest: "v0.3.0"},
+		},
+		"github.com/example/B": {
+			"go.uber.org/mock": {Actual: "v0.4.0", Latest: "v0.5.2"},
+		},
+	}, mismatches)
+}
+
+func TestToolsChecker_ConfiguredVersion(t *testing.T) {
+	graph := map[string]*Service{
+		"github.com/example/A": {ModulePath: "github.com/example/A", Tools: map[string]Tool{
+			"go.uber.org/mock": {Module: "go.uber.org/mock", Version: "v0.5.2"},
+		}},
+		"github.com/example/B": {ModulePath: "github.com/example/B", Tools: map[string]Tool{
+			"go.uber.org/mock": {Module: "go.uber.org/mock", Version: "v0.5.0"},
+		}},
+	}
+
+	mismatches, err := NewToolsChecker().Check(graph, map[string]string{"go.uber.org/mock": "v0.5.0"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]map[string]Mismatch{
+		"github.com/example/A": {
+			"go.uber.org/mock": {Actual: "v0.5.2", Latest: "v0.5.0"},
+		},
+	}, mismatches)
+}
+
+func TestToolsChecker_InvalidConfiguredVersion(t *testing.T) {
+	_, err := NewToolsChecker().Check(map[string]*Service{}, map[string]string{"go.uber.org/mock": "latest"})
+	require.Error(t, err)
+}
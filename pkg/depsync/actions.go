@@ -0,0 +1,95 @@
+package depsync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cryptellation/depsync/pkg/actions"
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// syncActions opens merge requests for workflows referencing outdated actions or
+// reusable workflows from tracked repositories.
+func (c *DepSync) syncActions(ctx context.Context) error {
+	if !c.config.Actions.Enabled {
+		return nil
+	}
+
+	logger := logging.C(ctx)
+	repositories := append(append([]string{}, c.config.Repositories...), c.config.Actions.Repositories...)
+	updates, err := c.actionsScanner.Scan(ctx, repositories, repositories)
+	if err != nil {
+		return fmt.Errorf("failed to scan workflows: %w", err)
+	}
+	logger.Info("Starting actions synchronization", zap.Int("update_count", len(updates)))
+
+	for _, update := range updates {
+		for _, ref := range update.References {
+			logger.Warn("Outdated action reference",
+				zap.String("repo_url", update.RepoURL),
+				zap.String("file", ref.File),
+				zap.Int("line", ref.Line),
+				zap.String("repository", ref.Repository),
+				zap.String("actual", ref.Version),
+				zap.String("latest", update.Release.Version),
+				zap.Bool("pinned", ref.Pinned))
+		}
+
+		if err := c.applyFileChange(ctx, newActionsChange(update)); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("Actions synchronization completed successfully")
+	return nil
+}
+
+// newActionsChange creates the change that updates the references of a repository workflows.
+func newActionsChange(update actions.Update) fileChange {
+	paths := make([]string, 0, len(update.Files))
+	for path := range update.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	files := make([]dagger.File, 0, len(paths))
+	for _, path := range paths {
+		files = append(files, dagger.File{Path: path, Content: update.Files[path]})
+	}
+
+	return fileChange{
+		RepoURL: update.RepoURL,
+		Subject: update.Repository,
+		BranchName: fmt.Sprintf("depsync/update-actions-%s-%s",
+			sanitizeBranchName(update.Repository), update.Release.Version),
+		Files:       files,
+		Title:       adapters.FormatActionsCommitMessage(update.Repository, update.Release.Version),
+		Description: generateActionsMRDescription(update),
+	}
+}
+
+// generateActionsMRDescription generates the description of an actions update merge request.
+func generateActionsMRDescription(update actions.Update) string {
+	var refs strings.Builder
+	for _, ref := range update.References {
+		name := ref.Repository
+		if ref.Path != "" {
+			name += "/" + ref.Path
+		}
+		fmt.Fprintf(&refs, "- `%s` (line %d): `%s` %s → %s\n",
+			ref.File, ref.Line, name, ref.Version, update.Release.Version)
+	}
+
+	return fmt.Sprintf(`## Actions Update
+
+This merge request updates the actions and reusable workflows from **%s** to version **%s**.
+
+### Changes
+%s
+This update was automatically generated by DepSync.`, update.Repository, update.Release.Version, refs.String())
+}
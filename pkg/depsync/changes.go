@@ -0,0 +1,164 @@
+package depsync
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/logging"
+	"github.com/cryptellation/depsync/pkg/state"
+	"go.uber.org/zap"
+)
+
+// fileChange describes files that depsync pushes on a branch of a repository,
+// outside of the go.mod dependency updates.
+type fileChange struct {
+	RepoURL string
+	// Subject identifies the change in logs (e.g. the synchronized file path).
+	Subject    string
+	BranchName string
+	Files      []dagger.File
+	// Update, when set, produces the change from the cloned repository instead of writing Files.
+	Update func(ctx context.Context, dir dagger.Workspace) (dagger.Workspace, error)
+	// Modules, when set, are the dependency updates applied by Update, applied without cloning
+	// the repository when they are plain version bumps.
+	Modules []dagger.Module
+	// Title is used as commit message and merge request title.
+	Title       string
+	Description string
+	// Vendor, when set, adds the changes of the vendored modules to the merge request
+	// description, for the changes updating Go modules.
+	Vendor bool
+}
+
+// key returns the state key of the change. The branch name identifies the target content.
+func (change fileChange) key() state.Key {
+	return updateKey(change.RepoURL, change.Subject, change.BranchName)
+}
+
+// applyFileChange pushes the change on its branch and manages its merge request.
+func (c *DepSync) applyFileChange(ctx context.Context, change fileChange) error {
+	skip, err := c.skipUpdate(ctx, change.key(), change.RepoURL)
+	if err != nil {
+		return err
+	} else if skip {
+		return nil
+	}
+
+	if err := c.pushFileChange(ctx, change); errors.Is(err, errVerificationFailed) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return c.manageFileChangeMergeRequest(ctx, change)
+}
+
+// pushFileChange pushes the files of the change on a new branch, unless the branch already exists.
+func (c *DepSync) pushFileChange(ctx context.Context, change fileChange) error {
+	logger := logging.C(ctx).WithOptions(zap.Fields(
+		zap.String("repo_url", change.RepoURL),
+		zap.String("subject", change.Subject),
+		zap.String("branch_name", change.BranchName)))
+
+	if len(change.Modules) > 0 {
+		done, err := c.lightweightUpdate(ctx, lightweightUpdateParams{
+			RepoURL:    change.RepoURL,
+			BranchName: change.BranchName,
+			Message:    change.Title,
+			Modules:    change.Modules,
+			Key:        change.key(),
+		})
+		if err != nil || done {
+			return err
+		}
+	}
+
+	dir, release, err := c.workspace(ctx, change.RepoURL)
+	if err != nil {
+		logger.Error("Failed to clone repo", zap.Error(err))
+		return err
+	}
+	defer release()
+
+	branchExists, err := c.dagger.CheckBranchExists(ctx, dagger.CheckBranchExistsParams{
+		Dir:        dir,
+		BranchName: change.BranchName,
+		RepoURL:    change.RepoURL,
+	})
+	if err != nil {
+		logger.Error("Failed to check branch existence", zap.Error(err))
+		return err
+	}
+	if branchExists {
+		logger.Warn("Branch already exists, skipping update")
+		return nil
+	}
+
+	var updatedDir dagger.Workspace
+	if change.Update != nil {
+		updatedDir, err = change.Update(ctx, dir)
+	} else {
+		updatedDir, err = c.dagger.WriteFiles(ctx, dagger.WriteFilesParams{
+			Dir:   dir,
+			Files: change.Files,
+		})
+	}
+	if err != nil {
+		logger.Error("Failed to update files", zap.Error(err))
+		return c.checkVerification(ctx, change.key(), change.RepoURL, change.BranchName, err)
+	}
+
+	_, err = c.dagger.CommitAndPush(ctx, dagger.CommitAndPushParams{
+		Dir:           updatedDir,
+		BranchName:    change.BranchName,
+		AuthorName:    c.config.Git.Author.Name,
+		AuthorEmail:   c.config.Git.Author.Email,
+		RepoURL:       change.RepoURL,
+		CommitMessage: change.Title,
+	})
+	if err != nil {
+		logger.Error("Failed to commit and push changes", zap.Error(err))
+		return err
+	}
+
+	logger.Info("Successfully committed and pushed changes")
+	c.recordEvent(ctx, change.key(), state.Event{Type: state.EventBranchPushed, BranchName: change.BranchName})
+	return nil
+}
+
+// manageFileChangeMergeRequest creates the merge request of the change, or follows up on the existing one.
+func (c *DepSync) manageFileChangeMergeRequest(ctx context.Context, change fileChange) error {
+	logger := logging.C(ctx).WithOptions(zap.Fields(
+		zap.String("repo_url", change.RepoURL),
+		zap.String("subject", change.Subject),
+		zap.String("branch_name", change.BranchName)))
+
+	prNumber, err := c.checkExistingPullRequest(ctx, change.RepoURL, change.Subject, change.RepoURL, change.BranchName)
+	if err != nil {
+		return err
+	}
+
+	if prNumber == -1 {
+		description := change.Description
+		if change.Vendor {
+			description = withVendorSummary(description, c.vendorSummary(ctx, change.RepoURL, change.BranchName))
+		}
+		prNumber, err = c.client.CreateMergeRequest(ctx, forge.CreateMergeRequestParams{
+			RepoURL:      change.RepoURL,
+			SourceBranch: change.BranchName,
+			Title:        change.Title,
+			Description:  description,
+		})
+		if err != nil {
+			logger.Error("Failed to create merge request", zap.Error(err))
+			return err
+		}
+		logger.Info("Successfully created merge request", zap.Int("pr_number", prNumber))
+		c.recordPullRequest(ctx, change.key(), change.RepoURL, change.BranchName, prNumber)
+		return nil
+	}
+	c.recordPullRequest(ctx, change.key(), change.RepoURL, change.BranchName, prNumber)
+
+	return c.followUpPullRequest(ctx, change.key(), "", change.RepoURL, prNumber, change.BranchName)
+}
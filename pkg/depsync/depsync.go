@@ -2,15 +2,29 @@ package depsync
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
+	"sync"
 
+	"github.com/cryptellation/depsync/pkg/actions"
+	"github.com/cryptellation/depsync/pkg/adapters"
 	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
 	"github.com/cryptellation/depsync/pkg/adapters/github"
+	"github.com/cryptellation/depsync/pkg/adapters/native"
 	"github.com/cryptellation/depsync/pkg/config"
 	"github.com/cryptellation/depsync/pkg/depgraph"
+	"github.com/cryptellation/depsync/pkg/docker"
+	"github.com/cryptellation/depsync/pkg/filesync"
+	"github.com/cryptellation/depsync/pkg/gomod"
 	"github.com/cryptellation/depsync/pkg/logging"
+	"github.com/cryptellation/depsync/pkg/plan"
+	"github.com/cryptellation/depsync/pkg/pool"
 	"github.com/cryptellation/depsync/pkg/repo"
+	"github.com/cryptellation/depsync/pkg/state"
 	"go.uber.org/zap"
 	"golang.org/x/mod/modfile"
 )
@@ -19,68 +33,358 @@ import (
 // repository file fetching and processing.
 type DepSync struct {
 	config          *config.Config
-	client          github.Client
+	client          forge.Client
 	fetcher         repo.FilesFetcher
 	graphBuilder    depgraph.GraphBuilder
 	versionDetector repo.VersionDetector
 	checker         depgraph.InconsistencyChecker
+	toolsChecker    depgraph.ToolsChecker
+	driftDetector   filesync.DriftDetector
+	actionsScanner  actions.Scanner
+	dockerScanner   docker.Scanner
+	store           state.Store
 	dagger          dagger.Dagger
+	// updater applies the lightweight updates, nil when disabled.
+	updater gomod.Updater
+	// workspaces are the working copies of the repositories updated during the runs, by URL.
+	workspacesMu sync.Mutex
+	workspaces   map[string]*repoWorkspace
+	// report lists the updates of the last run that were not pushed.
+	report Report
+	// plan records the actions taken in dry-run mode, nil otherwise.
+	plan *plan.Plan
 }
 
-// New creates a new DepSync instance with the given configuration and GitHub token.
+// New creates a new DepSync instance with the given configuration and GitHub token. The token
+// is ignored when a GitHub App is configured.
+// In dry-run mode, the actions are recorded in a plan instead of being executed.
 func New(cfg *config.Config, token string) (*DepSync, error) {
-	client := github.New(token)
+	hosts, hostTokens, err := githubHosts(cfg.GitHub.Hosts, cfg.GitHub.App)
+	if err != nil {
+		return nil, err
+	}
 
-	// Create dagger adapter with context
-	ctx := context.Background()
-	daggerAdapter, err := dagger.NewDagger(ctx, token)
+	tokenSource, err := githubAppTokenSource(cfg.GitHub.App)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create dagger adapter: %w", err)
+		return nil, err
+	}
+
+	forges, forgeTokens, err := forgeClients(cfg.Forges)
+	if err != nil {
+		return nil, err
+	}
+	for host, forgeToken := range forgeTokens {
+		hostTokens[host] = forgeToken
+	}
+
+	githubClient, err := github.NewWithOptions(token, github.Options{
+		CacheDir:     cfg.GitHub.CacheDir,
+		MaxRetries:   cfg.GitHub.MaxRetries,
+		MaxRetryWait: cfg.GitHub.MaxRetryWait,
+		Hosts:        hosts,
+		TokenSource:  tokenSource,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+
+	// Route the calls to the forge of each repository, GitHub by default
+	client := githubClient
+	if len(forges) > 0 {
+		client = forge.NewRouter(githubClient, forges)
+	}
+
+	updater, err := newUpdater(cfg.Lightweight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create go.mod updater: %w", err)
+	}
+
+	store, err := state.Open(cfg.State)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state store: %w", err)
+	}
+	if cfg.State.Backend == state.BackendMemory {
+		logging.L().Warn("The memory state backend keeps no history between runs: " +
+			"closed pull requests are proposed again by the next process")
+	}
+
+	var daggerAdapter dagger.Dagger
+	var p *plan.Plan
+	if cfg.DryRun {
+		// The image digests are resolved with the registry API, without Dagger nor Go toolchain
+		var resolver plan.DigestResolver
+		if cfg.Dockerfiles.PinDigests {
+			resolver = native.NewRegistry()
+		}
+		p = plan.New()
+		daggerAdapter = plan.NewDagger(client, p, resolver)
+		client = plan.NewGitHubClient(client, p)
+		store = state.NewReadOnlyStore(store)
+	} else {
+		daggerAdapter, err = newRunner(cfg.Runner, token, hostTokens, tokenSource)
+		if err != nil {
+			_ = store.Close()
+			return nil, err
+		}
 	}
 
+	fetcher := repo.NewFilesFetcher(client)
 	return &DepSync{
 		config:          cfg,
 		client:          client,
-		fetcher:         repo.NewFilesFetcher(client),
-		graphBuilder:    depgraph.NewGraphBuilder(),
-		versionDetector: repo.NewVersionDetector(),
+		fetcher:         fetcher,
+		graphBuilder:    depgraph.NewGraphBuilderWithOptions(depgraph.Options{AlignTools: cfg.Tools.Enabled}),
+		versionDetector: repo.NewVersionDetector(cfg.Concurrency),
 		checker:         depgraph.NewInconsistencyChecker(),
+		toolsChecker:    depgraph.NewToolsChecker(),
+		driftDetector:   filesync.NewDriftDetector(fetcher),
+		actionsScanner:  actions.NewScanner(client, fetcher),
+		dockerScanner:   docker.NewScanner(fetcher, daggerAdapter),
+		store:           store,
 		dagger:          daggerAdapter,
+		updater:         updater,
+		plan:            p,
 	}, nil
 }
 
+// githubHosts returns the GitHub Enterprise Server hosts of the configuration, with their
+// tokens read from the environment, and these tokens by host. The tokens are required with
+// a GitHub App, whose installation tokens are only valid on github.com.
+func githubHosts(configured []config.GitHubHost, app config.GitHubAppConfig) ([]github.Host, map[string]string, error) {
+	hosts := make([]github.Host, 0, len(configured))
+	tokens := make(map[string]string)
+	for _, h := range configured {
+		if h.Host == "" {
+			return nil, nil, fmt.Errorf("GitHub host without name")
+		}
+
+		var token string
+		if h.TokenEnv == "" && app.ID != 0 {
+			return nil, nil, fmt.Errorf("GitHub host %s requires a token_env with a GitHub App", h.Host)
+		} else if h.TokenEnv != "" {
+			token = os.Getenv(h.TokenEnv)
+			if token == "" {
+				return nil, nil, fmt.Errorf("%s environment variable is not set for GitHub host %s", h.TokenEnv, h.Host)
+			}
+			tokens[h.Host] = token
+		}
+
+		hosts = append(hosts, github.Host{
+			Name:      h.Host,
+			BaseURL:   h.BaseURL,
+			UploadURL: h.UploadURL,
+			Token:     token,
+		})
+	}
+	return hosts, tokens, nil
+}
+
+// newRunner creates the runner of the git and go operations, with the tokens of the hosts.
+func newRunner(runner, token string, hostTokens map[string]string,
+	tokenSource adapters.TokenSource) (dagger.Dagger, error) {
+	switch runner {
+	case config.RunnerNative:
+		r, err := native.New(token, native.Options{HostTokens: hostTokens, TokenSource: tokenSource})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create native runner: %w", err)
+		}
+		return r, nil
+	case config.RunnerDagger, "":
+		r, err := dagger.NewDaggerWithOptions(context.Background(), token, dagger.Options{
+			HostTokens:  hostTokens,
+			TokenSource: tokenSource,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create dagger adapter: %w", err)
+		}
+		return r, nil
+	default:
+		return nil, fmt.Errorf("unknown runner: %s", runner)
+	}
+}
+
+// githubAppTokenSource returns the installation tokens source of the configured GitHub App,
+// or nil when no GitHub App is configured.
+func githubAppTokenSource(app config.GitHubAppConfig) (adapters.TokenSource, error) {
+	if app.ID == 0 {
+		return nil, nil
+	}
+
+	key, err := os.ReadFile(app.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+	}
+	return github.NewAppTokenSource(github.AppParams{
+		AppID:          app.ID,
+		PrivateKey:     key,
+		InstallationID: app.InstallationID,
+	})
+}
+
+// Report returns the report of the last run, and of the tags propagated since.
+func (c *DepSync) Report() *Report {
+	return &c.report
+}
+
+// Plan returns the actions recorded in dry-run mode, or nil when not in dry-run mode.
+func (c *DepSync) Plan() *plan.Plan {
+	return c.plan
+}
+
 // Close closes the DepSync and its resources.
 func (c *DepSync) Close() error {
+	var errs []error
+	if c.store != nil {
+		errs = append(errs, c.store.Close())
+	}
 	if c.dagger != nil {
-		return c.dagger.Close()
+		errs = append(errs, c.dagger.Close())
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 // Run executes the main depsync workflow, fetching files from configured repositories.
 func (c *DepSync) Run(ctx context.Context) error {
-	if len(c.config.Repositories) == 0 {
+	if len(c.config.Repositories) == 0 && len(c.config.Files) == 0 && len(c.config.Actions.Repositories) == 0 {
 		return fmt.Errorf("no repositories configured")
 	}
+	defer c.removeWorkspaces(ctx)
+	c.report.reset()
+
+	if len(c.config.Repositories) > 0 {
+		graph, err := c.buildGraph(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := c.syncDependencies(ctx, graph); err != nil {
+			return err
+		}
+
+		if err := c.syncTools(ctx, graph); err != nil {
+			return fmt.Errorf("failed to sync tools: %w", err)
+		}
+
+		if err := c.syncDockerfiles(ctx, graph); err != nil {
+			return fmt.Errorf("failed to sync dockerfiles: %w", err)
+		}
+	}
+
+	if err := c.syncFiles(ctx); err != nil {
+		return fmt.Errorf("failed to sync files: %w", err)
+	}
+
+	if err := c.syncActions(ctx); err != nil {
+		return fmt.Errorf("failed to sync actions: %w", err)
+	}
 
-	modules, err := c.fetchModules(ctx)
+	return nil
+}
+
+// buildGraph builds the dependency graph of the configured repositories and detects their latest versions.
+func (c *DepSync) buildGraph(ctx context.Context) (map[string]*depgraph.Service, error) {
+	switch c.config.GitHub.Discovery {
+	case config.DiscoveryGraphQL:
+		return c.buildGraphWithDiscovery(ctx)
+	case "", config.DiscoveryREST:
+	default:
+		return nil, fmt.Errorf("unknown discovery method: %s", c.config.GitHub.Discovery)
+	}
+
+	modules, err := c.fetchModules(ctx, c.config.Repositories)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	graph, err := c.graphBuilder.BuildGraph(modules)
 	if err != nil {
-		return fmt.Errorf("failed to build dependency graph: %w", err)
+		return nil, fmt.Errorf("failed to build dependency graph: %w", err)
 	}
 
 	err = c.versionDetector.DetectAndSetCurrentVersions(ctx, c.client, graph)
 	if err != nil {
-		return fmt.Errorf("failed to detect versions: %w", err)
+		return nil, fmt.Errorf("failed to detect versions: %w", err)
 	}
 
 	c.printDependencyGraph(ctx, graph)
 	c.printCurrentVersions(ctx, graph)
 
+	return graph, nil
+}
+
+// PropagateTag propagates a new tag of a configured repository: it only updates the
+// dependencies on the module of this repository, e.g. when notified by a webhook.
+func (c *DepSync) PropagateTag(ctx context.Context, repoURL, tag string) error {
+	logger := logging.C(ctx)
+	var tagged string
+	for _, r := range c.config.Repositories {
+		if sameRepository(r, repoURL) {
+			tagged = r
+		}
+	}
+	if tagged == "" {
+		logger.Debug("Tag of an unknown repository, ignoring", zap.String("repo_url", repoURL))
+		return nil
+	}
+	if repo.LatestSemverTag([]forge.Tag{{Name: tag}}) == "" {
+		logger.Debug("Tag is not a release version, ignoring", zap.String("repo_url", repoURL), zap.String("tag", tag))
+		return nil
+	}
+	logger.Info("Propagating new tag", zap.String("repo_url", repoURL), zap.String("tag", tag))
+	defer c.removeWorkspaces(ctx)
+
+	graph, err := c.buildTagGraph(ctx, tagged, tag)
+	if err != nil {
+		return err
+	}
+
+	return c.syncDependencies(ctx, graph)
+}
+
+// buildTagGraph builds the dependency graph of the configured repositories for a new tag:
+// the module of the tagged repository is resolved from its go.mod at the tag, and is the
+// only one with a latest version, so that only its dependents are updated.
+func (c *DepSync) buildTagGraph(ctx context.Context, taggedURL, tag string) (map[string]*depgraph.Service, error) {
+	results, err := c.fetcher.Fetch(ctx, taggedURL, tag, "go.mod")
+	if err != nil {
+		return nil, fmt.Errorf("error fetching go.mod for %s at %s: %w", taggedURL, tag, err)
+	}
+	content, ok := results["go.mod"]
+	if !ok {
+		return nil, fmt.Errorf("go.mod not found in repository %s at %s", taggedURL, tag)
+	}
+	modulePath, err := parseModulePath(taggedURL, content)
+	if err != nil {
+		return nil, err
+	}
+
+	// The other repositories are the possible dependents of the module
+	dependents := slices.DeleteFunc(slices.Clone(c.config.Repositories), func(r string) bool {
+		return r == taggedURL
+	})
+	modules, err := c.fetchModules(ctx, dependents)
+	if err != nil {
+		return nil, err
+	}
+	modules[modulePath] = depgraph.RepoModule{RepoURL: taggedURL, GoModContent: content}
+
+	graph, err := c.graphBuilder.BuildGraph(modules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build dependency graph: %w", err)
+	}
+	svc, ok := graph[modulePath]
+	if !ok {
+		return nil, fmt.Errorf("module %s not found in dependency graph", modulePath)
+	}
+	svc.LatestVersion = tag
+
+	c.printDependencyGraph(ctx, graph)
+
+	return graph, nil
+}
+
+// syncDependencies detects dependency version inconsistencies and fixes them.
+func (c *DepSync) syncDependencies(ctx context.Context, graph map[string]*depgraph.Service) error {
 	mismatches, err := c.checker.Check(graph)
 	if err != nil {
 		return fmt.Errorf("failed to check for inconsistencies: %w", err)
@@ -100,7 +404,7 @@ func (c *DepSync) Run(ctx context.Context) error {
 		}
 	}
 	// Call the fixModules method to handle dependency updates
-	if err := c.fixModules(ctx, mismatches); err != nil {
+	if err := c.fixModules(ctx, graph, mismatches); err != nil {
 		return fmt.Errorf("failed to fix modules: %w", err)
 	}
 
@@ -108,46 +412,93 @@ func (c *DepSync) Run(ctx context.Context) error {
 }
 
 // fixModules handles the dependency update workflow using the Dagger adapter.
-func (c *DepSync) fixModules(ctx context.Context, mismatches map[string]map[string]depgraph.Mismatch) error {
+func (c *DepSync) fixModules(ctx context.Context, graph map[string]*depgraph.Service,
+	mismatches map[string]map[string]depgraph.Mismatch) error {
 	logger := logging.C(ctx)
 	logger.Info("Starting fixModules workflow", zap.Int("service_count", len(mismatches)))
 
-	// Iterate mismatches and clone each repo for each dependency update
-	for service, deps := range mismatches {
-		logger.Info("Processing service", zap.String("service", service))
+	// Process the services concurrently, and the dependencies of each service sequentially
+	services := sortedKeys(mismatches)
+	err := pool.ForEach(ctx, c.concurrency(), len(services), func(ctx context.Context, i int) error {
+		return c.fixModule(ctx, graph, services[i], mismatches[services[i]])
+	})
+	if err != nil {
+		return err
+	}
 
-		// Convert Go module path to GitHub URL
-		// Format: github.com/x/y -> https://github.com/x/y
-		repoURL := "https://" + service
+	logger.Info("fixModules workflow completed successfully")
+	return nil
+}
 
-		// Update each dependency for this service
-		for dep, mismatch := range deps {
-			branchName, err := c.updateDependency(ctx, service, dep, mismatch, repoURL)
-			if err != nil {
-				return err
-			}
+// fixModule updates the mismatched dependencies of a service.
+func (c *DepSync) fixModule(ctx context.Context, graph map[string]*depgraph.Service, service string,
+	deps map[string]depgraph.Mismatch) error {
+	logger := logging.C(ctx)
+	logger.Info("Processing service", zap.String("service", service))
+
+	// Use the repository URL of the service, as the module path may differ from it: major
+	// version suffix (/v2), module in a subdirectory, or vanity path. Without it, convert the
+	// module path to a URL. Format: github.com/x/y -> https://github.com/x/y
+	repoURL := "https://" + service
+
+	var goVersion string
+	if svc, ok := graph[service]; ok {
+		goVersion = svc.GoVersion
+		if svc.RepoURL != "" {
+			repoURL = svc.RepoURL
+		}
+	}
 
-			// Always attempt MR creation, even if branch already existed
-			// In the future, we will detect if the MR already exists
-			if err := c.manageMergeRequest(ctx, service, dep, mismatch, repoURL, branchName); err != nil {
-				return err
-			}
+	// Batch the updates in groups, if enabled
+	deps, err := c.updateDependencyGroups(ctx, repoURL, goVersion, deps)
+	if err != nil {
+		return err
+	}
+
+	// Update each dependency for this service
+	for _, dep := range sortedKeys(deps) {
+		mismatch := deps[dep]
+		skip, err := c.skipUpdate(ctx, updateKey(service, dep, mismatch.Latest), repoURL)
+		if err != nil {
+			return err
+		} else if skip {
+			continue
 		}
 
-		logger.Info("All dependencies processed for service",
-			zap.String("service", service),
-			zap.String("repo_url", repoURL))
+		branchName, err := c.updateDependency(ctx, service, dep, mismatch, repoURL, goVersion)
+		if errors.Is(err, errVerificationFailed) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		// Always attempt MR creation, even if branch already existed
+		// In the future, we will detect if the MR already exists
+		if err := c.manageMergeRequest(ctx, service, dep, mismatch, repoURL, branchName); err != nil {
+			return err
+		}
 	}
 
-	logger.Info("fixModules workflow completed successfully")
+	logger.Info("All dependencies processed for service",
+		zap.String("service", service),
+		zap.String("repo_url", repoURL))
 	return nil
 }
 
+// concurrency returns the maximum number of repositories to process concurrently.
+// The dry-run mode processes them sequentially, for the plan to be ordered.
+func (c *DepSync) concurrency() int {
+	if c.plan != nil {
+		return 1
+	}
+	return c.config.Concurrency
+}
+
 // updateDependency updates a single dependency for a service.
 //
 //nolint:funlen // This function orchestrates a complex workflow that's difficult to break down further
 func (c *DepSync) updateDependency(ctx context.Context, service, dep string, mismatch depgraph.Mismatch,
-	repoURL string) (string, error) {
+	repoURL, goVersion string) (string, error) {
 	logger := logging.C(ctx)
 	logger.Info("Updating dependency",
 		zap.String("service", service),
@@ -155,15 +506,30 @@ func (c *DepSync) updateDependency(ctx context.Context, service, dep string, mis
 		zap.String("from", mismatch.Actual),
 		zap.String("to", mismatch.Latest))
 
-	// Clone the repo fresh for each dependency update
-	dir, err := c.dagger.CloneRepo(ctx, repoURL, "main")
+	// Generate branch name
+	branchName := generateBranchName(dep, mismatch.Latest)
+
+	// Apply the update without cloning the repo, when possible
+	done, err := c.lightweightUpdate(ctx, lightweightUpdateParams{
+		RepoURL:    repoURL,
+		BranchName: branchName,
+		Message:    adapters.FormatCommitMessage(dep, mismatch.Latest),
+		Modules:    []dagger.Module{{Path: dep, Version: mismatch.Latest}},
+		Key:        updateKey(service, dep, mismatch.Latest),
+	})
 	if err != nil {
-		logger.Error("Failed to clone repo for service", zap.String("service", service), zap.Error(err))
 		return "", err
+	} else if done {
+		return branchName, nil
 	}
 
-	// Generate branch name
-	branchName := generateBranchName(dep, mismatch.Latest)
+	// Reuse the working copy of the service, reset to main
+	dir, release, err := c.workspace(ctx, repoURL)
+	if err != nil {
+		logger.Error("Failed to clone repo for service", zap.String("service", service), zap.Error(err))
+		return "", err
+	}
+	defer release()
 
 	// Check if the branch already exists
 	branchExists, err := c.dagger.CheckBranchExists(ctx, dagger.CheckBranchExistsParams{
@@ -194,13 +560,15 @@ func (c *DepSync) updateDependency(ctx context.Context, service, dep string, mis
 		Dir:           dir,
 		ModulePath:    dep,
 		TargetVersion: mismatch.Latest,
+		GoVersion:     goVersion,
+		Verify:        c.verifyCommands(),
 	})
 	if err != nil {
 		logger.Error("Failed to update dependency",
 			zap.String("service", service),
 			zap.String("dependency", dep),
 			zap.Error(err))
-		return "", err
+		return "", c.checkVerification(ctx, updateKey(service, dep, mismatch.Latest), repoURL, branchName, err)
 	}
 
 	logger.Info("Dependency updated successfully",
@@ -231,6 +599,10 @@ func (c *DepSync) updateDependency(ctx context.Context, service, dep string, mis
 		zap.String("dependency", dep),
 		zap.String("branch_name", branchName),
 		zap.String("repo_url", repoURL))
+	c.recordEvent(ctx, updateKey(service, dep, mismatch.Latest), state.Event{
+		Type:       state.EventBranchPushed,
+		BranchName: branchName,
+	})
 
 	return branchName, nil
 }
@@ -252,32 +624,25 @@ func (c *DepSync) manageMergeRequest(ctx context.Context, service, dep string, m
 	}
 
 	// If no PR exists, create it and return
+	key := updateKey(service, dep, mismatch.Latest)
 	if prNumber == -1 {
-		_, err = c.createMergeRequest(ctx, service, dep, mismatch, repoURL, branchName)
-		return err
-	}
-
-	// Conflict check and deletion for existing PR
-	deleted, err := c.handlePRConflicts(ctx, service, dep, mismatch, repoURL, prNumber, branchName)
-	if err != nil {
-		logger.Error("Failed to handle PR conflicts", zap.Error(err))
-		return err
-	} else if deleted {
-		// Skip checkAndMergeMR if deletion was performed
+		prNumber, err = c.createMergeRequest(ctx, service, dep, mismatch, repoURL, branchName)
+		if err != nil {
+			return err
+		}
+		c.recordPullRequest(ctx, key, repoURL, branchName, prNumber)
 		return nil
 	}
+	c.recordPullRequest(ctx, key, repoURL, branchName, prNumber)
 
-	// Check and merge MR if checks pass for newly created PR
-	c.checkAndMergeMR(ctx, service, dep, mismatch, repoURL, prNumber, branchName)
-
-	return nil
+	// Conflict check, deletion or merge for the existing PR
+	return c.followUpPullRequest(ctx, key, mismatch.Latest, repoURL, prNumber, branchName)
 }
 
 // handlePRConflicts checks for conflicts in an existing PR and deletes it if needed.
 func (c *DepSync) handlePRConflicts(
 	ctx context.Context,
 	service, dep string,
-	_ depgraph.Mismatch,
 	repoURL string,
 	prNumber int,
 	branchName string,
@@ -296,7 +661,7 @@ func (c *DepSync) handlePRConflicts(
 		zap.Int("pr_number", prNumber))
 
 	// Check for merge conflicts
-	hasConflicts, err := c.client.CheckMergeConflicts(ctx, github.CheckMergeConflictsParams{
+	hasConflicts, err := c.client.CheckMergeConflicts(ctx, forge.CheckMergeConflictsParams{
 		RepoURL:  repoURL,
 		PRNumber: prNumber,
 	})
@@ -316,7 +681,7 @@ func (c *DepSync) handlePRConflicts(
 		zap.Int("pr_number", prNumber))
 
 	// Delete the conflicted PR and branch
-	if err := c.deleteConflictedPR(ctx, service, dep, repoURL, prNumber, branchName); err != nil {
+	if err := c.deletePRAndBranch(ctx, service, dep, repoURL, prNumber, branchName); err != nil {
 		logger.Error("Failed to delete conflicted PR", zap.Error(err))
 		return false, err
 	}
@@ -329,18 +694,18 @@ func (c *DepSync) handlePRConflicts(
 	return true, nil
 }
 
-// deleteConflictedPR deletes a conflicted PR and its associated branch.
-func (c *DepSync) deleteConflictedPR(ctx context.Context, service, dep string,
+// deletePRAndBranch deletes a PR (e.g. conflicted or superseded) and its associated branch.
+func (c *DepSync) deletePRAndBranch(ctx context.Context, service, dep string,
 	repoURL string, prNumber int, branchName string) error {
 	logger := logging.C(ctx)
-	logger.Info("Deleting conflicted PR and branch",
+	logger.Info("Deleting PR and branch",
 		zap.String("service", service),
 		zap.String("dependency", dep),
 		zap.Int("pr_number", prNumber),
 		zap.String("branch_name", branchName))
 
 	// Close the pull request
-	if err := c.client.DeletePullRequest(ctx, github.DeletePullRequestParams{
+	if err := c.client.DeletePullRequest(ctx, forge.DeletePullRequestParams{
 		RepoURL:  repoURL,
 		PRNumber: prNumber,
 	}); err != nil {
@@ -349,7 +714,7 @@ func (c *DepSync) deleteConflictedPR(ctx context.Context, service, dep string,
 	}
 
 	// Delete the branch
-	if err := c.client.DeleteBranch(ctx, github.DeleteBranchParams{
+	if err := c.client.DeleteBranch(ctx, forge.DeleteBranchParams{
 		RepoURL:    repoURL,
 		BranchName: branchName,
 	}); err != nil {
@@ -357,7 +722,7 @@ func (c *DepSync) deleteConflictedPR(ctx context.Context, service, dep string,
 		return fmt.Errorf("failed to delete branch: %w", err)
 	}
 
-	logger.Info("Successfully deleted conflicted PR and branch",
+	logger.Info("Successfully deleted PR and branch",
 		zap.String("service", service),
 		zap.String("dependency", dep),
 		zap.Int("pr_number", prNumber),
@@ -370,7 +735,7 @@ func (c *DepSync) deleteConflictedPR(ctx context.Context, service, dep string,
 func (c *DepSync) checkExistingPullRequest(ctx context.Context, service, dep, repoURL, branchName string) (
 	int, error) {
 	logger := logging.C(ctx)
-	prNumber, err := c.client.CheckPullRequestExists(ctx, github.CheckPullRequestExistsParams{
+	prNumber, err := c.client.CheckPullRequestExists(ctx, forge.CheckPullRequestExistsParams{
 		RepoURL:      repoURL,
 		SourceBranch: branchName,
 	})
@@ -399,12 +764,16 @@ func (c *DepSync) checkExistingPullRequest(ctx context.Context, service, dep, re
 func (c *DepSync) createMergeRequest(ctx context.Context, service, dep string, mismatch depgraph.Mismatch,
 	repoURL, branchName string) (int, error) {
 	logger := logging.C(ctx)
-	prNumber, err := c.client.CreateMergeRequest(ctx, github.CreateMergeRequestParams{
+	params := forge.CreateMergeRequestParams{
 		RepoURL:       repoURL,
 		SourceBranch:  branchName,
 		ModulePath:    dep,
 		TargetVersion: mismatch.Latest,
-	})
+	}
+	if summary := c.vendorSummary(ctx, repoURL, branchName); summary != "" {
+		params.Description = withVendorSummary(adapters.FormatMergeRequestDescription(dep, mismatch.Latest), summary)
+	}
+	prNumber, err := c.client.CreateMergeRequest(ctx, params)
 	if err != nil {
 		logger.Error("Failed to create merge request",
 			zap.String("service", service),
@@ -425,10 +794,11 @@ func (c *DepSync) createMergeRequest(ctx context.Context, service, dep string, m
 }
 
 // checkAndMergeMR checks the CI/CD status and merges the MR if checks pass.
+// It returns true if the MR has been merged.
 func (c *DepSync) checkAndMergeMR(ctx context.Context, service, dep string,
-	mismatch depgraph.Mismatch, repoURL string, prNumber int, branchName string) {
+	targetVersion, repoURL string, prNumber int, branchName string) bool {
 	logger := logging.C(ctx)
-	checkStatus, err := c.client.GetPullRequestChecks(ctx, github.GetPullRequestChecksParams{
+	checkStatus, err := c.client.GetPullRequestChecks(ctx, forge.GetPullRequestChecksParams{
 		RepoURL:  repoURL,
 		PRNumber: prNumber,
 	})
@@ -439,7 +809,7 @@ func (c *DepSync) checkAndMergeMR(ctx context.Context, service, dep string,
 			zap.Int("pr_number", prNumber),
 			zap.Error(err))
 		// Continue with other MRs, don't fail the entire process
-		return
+		return false
 	}
 
 	// Log the check status
@@ -455,7 +825,7 @@ func (c *DepSync) checkAndMergeMR(ctx context.Context, service, dep string,
 			zap.String("dependency", dep),
 			zap.Int("pr_number", prNumber))
 
-		if err := c.mergeMergeRequest(ctx, service, dep, mismatch, repoURL, prNumber, branchName); err != nil {
+		if err := c.mergeMergeRequest(ctx, service, dep, targetVersion, repoURL, prNumber, branchName); err != nil {
 			logger.Error("Failed to merge pull request",
 				zap.String("service", service),
 				zap.String("dependency", dep),
@@ -463,31 +833,33 @@ func (c *DepSync) checkAndMergeMR(ctx context.Context, service, dep string,
 				zap.Error(err))
 
 			// Continue with other MRs, don't fail the entire process
-			return
+			return false
 		}
 
 		logger.Info("Successfully merged pull request",
 			zap.String("service", service),
 			zap.String("dependency", dep),
 			zap.Int("pr_number", prNumber))
+		return true
 	case "failed":
 		logger.Warn("CI/CD checks have failed - manual intervention required",
 			zap.String("service", service),
 			zap.String("dependency", dep),
 			zap.Int("pr_number", prNumber))
 	}
+	return false
 }
 
 // mergeMergeRequest merges the specified pull request.
 func (c *DepSync) mergeMergeRequest(ctx context.Context, service, dep string,
-	mismatch depgraph.Mismatch, repoURL string, prNumber int, branchName string) error {
+	targetVersion, repoURL string, prNumber int, branchName string) error {
 	logger := logging.C(ctx)
 
-	err := c.client.MergeMergeRequest(ctx, github.MergeMergeRequestParams{
+	err := c.client.MergeMergeRequest(ctx, forge.MergeMergeRequestParams{
 		RepoURL:       repoURL,
 		PRNumber:      prNumber,
 		ModulePath:    dep,
-		TargetVersion: mismatch.Latest,
+		TargetVersion: targetVersion,
 	})
 	if err != nil {
 		logger.Error("Failed to merge pull request",
@@ -504,7 +876,7 @@ func (c *DepSync) mergeMergeRequest(ctx context.Context, service, dep string,
 		zap.Int("pr_number", prNumber))
 
 	// Delete the branch after successful merge
-	err = c.client.DeleteBranch(ctx, github.DeleteBranchParams{
+	err = c.client.DeleteBranch(ctx, forge.DeleteBranchParams{
 		RepoURL:    repoURL,
 		BranchName: branchName,
 	})
@@ -547,38 +919,71 @@ func generateBranchName(modulePath, targetVersion string) string {
 }
 
 // fetchModules fetches go.mod files and builds the input map for the dependency graph builder.
-func (c *DepSync) fetchModules(ctx context.Context) (map[string]depgraph.RepoModule, error) {
-	modules := make(map[string]depgraph.RepoModule)
-	for _, repoURL := range c.config.Repositories {
-		logging.C(ctx).Info("Fetching go.mod for repository",
-			zap.String("url", repoURL),
-		)
-		results, err := c.fetcher.Fetch(ctx, repoURL, "main", "go.mod")
-		if err != nil {
-			return nil, fmt.Errorf("error fetching go.mod for %s: %w", repoURL, err)
-		}
-		content, ok := results["go.mod"]
-		if !ok {
-			return nil, fmt.Errorf("go.mod not found in repository: %s", repoURL)
-		}
-		mf, err := modfile.Parse("go.mod", content, nil)
-		if err != nil || mf.Module == nil {
-			return nil, fmt.Errorf("could not parse module path for repo %s: %w", repoURL, err)
-		}
-		modulePath := mf.Module.Mod.Path
-		modules[modulePath] = depgraph.RepoModule{
-			RepoURL:      repoURL,
-			GoModContent: content,
-		}
-		logging.C(ctx).Info("Repository module info",
-			zap.String("url", repoURL),
-			zap.String("module_path", modulePath),
-			zap.Int("go_mod_size", len(content)),
-		)
+// The repositories are fetched concurrently.
+func (c *DepSync) fetchModules(ctx context.Context, repoURLs []string) (map[string]depgraph.RepoModule, error) {
+	type result struct {
+		modulePath string
+		module     depgraph.RepoModule
+	}
+	results := make([]result, len(repoURLs))
+	err := pool.ForEach(ctx, c.config.Concurrency, len(results), func(ctx context.Context, i int) error {
+		modulePath, module, err := c.fetchModule(ctx, repoURLs[i])
+		results[i] = result{modulePath: modulePath, module: module}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	modules := make(map[string]depgraph.RepoModule, len(results))
+	for _, r := range results {
+		modules[r.modulePath] = r.module
 	}
 	return modules, nil
 }
 
+// fetchModule fetches the go.mod and tools files of a repository, and returns its module path.
+func (c *DepSync) fetchModule(ctx context.Context, repoURL string) (string, depgraph.RepoModule, error) {
+	logging.C(ctx).Info("Fetching go.mod for repository",
+		zap.String("url", repoURL),
+	)
+	results, err := c.fetcher.Fetch(ctx, repoURL, adapters.DefaultBranch, "go.mod")
+	if err != nil {
+		return "", depgraph.RepoModule{}, fmt.Errorf("error fetching go.mod for %s: %w", repoURL, err)
+	}
+	content, ok := results["go.mod"]
+	if !ok {
+		return "", depgraph.RepoModule{}, fmt.Errorf("go.mod not found in repository: %s", repoURL)
+	}
+	modulePath, err := parseModulePath(repoURL, content)
+	if err != nil {
+		return "", depgraph.RepoModule{}, err
+	}
+	toolsFiles, err := c.fetchToolsFiles(ctx, repoURL)
+	if err != nil {
+		return "", depgraph.RepoModule{}, err
+	}
+	logging.C(ctx).Info("Repository module info",
+		zap.String("url", repoURL),
+		zap.String("module_path", modulePath),
+		zap.Int("go_mod_size", len(content)),
+	)
+	return modulePath, depgraph.RepoModule{
+		RepoURL:      repoURL,
+		GoModContent: content,
+		ToolsFiles:   toolsFiles,
+	}, nil
+}
+
+// parseModulePath returns the module path of a repository go.mod.
+func parseModulePath(repoURL string, content []byte) (string, error) {
+	mf, err := modfile.Parse("go.mod", content, nil)
+	if err != nil || mf.Module == nil {
+		return "", fmt.Errorf("could not parse module path for repo %s: %w", repoURL, err)
+	}
+	return mf.Module.Mod.Path, nil
+}
+
 // printDependencyGraph prints the dependency graph in a readable format.
 func (c *DepSync) printDependencyGraph(ctx context.Context, graph map[string]*depgraph.Service) {
 	logging.C(ctx).Info("Dependency graph:")
@@ -0,0 +1,107 @@
+//go:build unit
+// +build unit
+
+package depsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/actions"
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDepSync_Run_Actions_CreatesMergeRequest(t *testing.T) {
+	cfg := &config.Config{
+		Actions: config.ActionsConfig{
+			Enabled:      true,
+			Repositories: []string{"https://github.com/test/actions"},
+		},
+		Git: config.GitConfig{
+			Author: config.GitAuthor{
+				Name:  "DepSync Bot",
+				Email: "depsync@example.com",
+			},
+		},
+	}
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	update := actions.Update{
+		RepoURL:    "https://github.com/test/actions",
+		Repository: "test/actions",
+		Release:    actions.Release{Version: "v1.4.0", SHA: "sha"},
+		References: []actions.Reference{{
+			File:       ".github/workflows/ci.yml",
+			Line:       8,
+			Repository: "test/actions",
+			Path:       "setup",
+			Ref:        "v1.3.0",
+			Version:    "v1.3.0",
+		}},
+		Files: map[string][]byte{
+			".github/workflows/ci.yml": []byte("- uses: test/actions/setup@v1.4.0\n"),
+		},
+	}
+	tc.MockActionsScanner.EXPECT().Scan(gomock.Any(),
+		[]string{"https://github.com/test/actions"},
+		[]string{"https://github.com/test/actions"},
+	).Return([]actions.Update{update}, nil)
+
+	branchName := "depsync/update-actions-test-actions-v1.4.0"
+	title := "chores(depsync): update test/actions actions to v1.4.0"
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), "https://github.com/test/actions", "main").Return(nil, nil)
+	tc.MockDagger.EXPECT().CheckBranchExists(gomock.Any(), dagger.CheckBranchExistsParams{
+		BranchName: branchName,
+		RepoURL:    "https://github.com/test/actions",
+	}).Return(false, nil)
+	tc.MockDagger.EXPECT().WriteFiles(gomock.Any(), dagger.WriteFilesParams{
+		Files: []dagger.File{{
+			Path:    ".github/workflows/ci.yml",
+			Content: []byte("- uses: test/actions/setup@v1.4.0\n"),
+		}},
+	}).Return(nil, nil)
+	tc.MockDagger.EXPECT().CommitAndPush(gomock.Any(), dagger.CommitAndPushParams{
+		BranchName:    branchName,
+		AuthorName:    "DepSync Bot",
+		AuthorEmail:   "depsync@example.com",
+		RepoURL:       "https://github.com/test/actions",
+		CommitMessage: title,
+	}).Return(branchName, nil)
+
+	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(gomock.Any(), forge.CheckPullRequestExistsParams{
+		RepoURL:      "https://github.com/test/actions",
+		SourceBranch: branchName,
+	}).Return(-1, nil)
+	tc.MockGitHubClient.EXPECT().CreateMergeRequest(gomock.Any(), forge.CreateMergeRequestParams{
+		RepoURL:      "https://github.com/test/actions",
+		SourceBranch: branchName,
+		Title:        title,
+		Description:  generateActionsMRDescription(update),
+	}).Return(12, nil)
+
+	err := tc.DepSync.Run(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestGenerateActionsMRDescription(t *testing.T) {
+	description := generateActionsMRDescription(actions.Update{
+		Repository: "test/actions",
+		Release:    actions.Release{Version: "v1.4.0"},
+		References: []actions.Reference{{
+			File:       ".github/workflows/ci.yml",
+			Line:       8,
+			Repository: "test/actions",
+			Path:       "setup",
+			Version:    "v1.3.0",
+		}},
+	})
+
+	assert.Contains(t, description, "`.github/workflows/ci.yml` (line 8): `test/actions/setup` v1.3.0 → v1.4.0")
+}
@@ -8,7 +8,7 @@ import (
 	"testing"
 
 	"github.com/cryptellation/depsync/pkg/adapters/dagger"
-	"github.com/cryptellation/depsync/pkg/adapters/github"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
 	"github.com/cryptellation/depsync/pkg/config"
 	"github.com/cryptellation/depsync/pkg/depgraph"
 	"github.com/stretchr/testify/assert"
@@ -97,7 +97,7 @@ func TestDepSync_Run_WithRepositories_Success(t *testing.T) {
 	// Mock the CheckPullRequestExists call (returns -1 - no existing PR)
 	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(
 		gomock.Any(),
-		github.CheckPullRequestExistsParams{
+		forge.CheckPullRequestExistsParams{
 			RepoURL:      "https://github.com/test/repo",
 			SourceBranch: "depsync/update-github-com-test-dep-v1.1.0",
 		},
@@ -106,7 +106,7 @@ func TestDepSync_Run_WithRepositories_Success(t *testing.T) {
 	// Mock the CreateMergeRequest call
 	tc.MockGitHubClient.EXPECT().CreateMergeRequest(
 		gomock.Any(),
-		github.CreateMergeRequestParams{
+		forge.CreateMergeRequestParams{
 			RepoURL:       "https://github.com/test/repo",
 			SourceBranch:  "depsync/update-github-com-test-dep-v1.1.0",
 			ModulePath:    "github.com/test/dep",
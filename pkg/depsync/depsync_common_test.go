@@ -4,16 +4,31 @@
 package depsync
 
 import (
+	"context"
 	"testing"
 
+	"github.com/cryptellation/depsync/pkg/actions"
 	"github.com/cryptellation/depsync/pkg/adapters/dagger"
-	"github.com/cryptellation/depsync/pkg/adapters/github"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
 	"github.com/cryptellation/depsync/pkg/config"
 	"github.com/cryptellation/depsync/pkg/depgraph"
+	"github.com/cryptellation/depsync/pkg/docker"
+	"github.com/cryptellation/depsync/pkg/filesync"
+	"github.com/cryptellation/depsync/pkg/gomod"
+	"github.com/cryptellation/depsync/pkg/plan"
 	"github.com/cryptellation/depsync/pkg/repo"
+	"github.com/cryptellation/depsync/pkg/state"
 	"go.uber.org/mock/gomock"
 )
 
+// testWorkspace is a workspace returned by the Dagger mock, identified by its name.
+type testWorkspace string
+
+// RepoURL implements the dagger.Workspace interface.
+func (w testWorkspace) RepoURL() string {
+	return "https://github.com/test/" + string(w)
+}
+
 // TestDepSync contains all the mocks and the depsync instance for testing
 type TestDepSync struct {
 	DepSync             *DepSync
@@ -22,8 +37,16 @@ type TestDepSync struct {
 	MockGraphBuilder    *depgraph.MockGraphBuilder
 	MockVersionDetector *repo.MockVersionDetector
 	MockChecker         *depgraph.MockInconsistencyChecker
+	MockToolsChecker    *depgraph.MockToolsChecker
+	MockDriftDetector   *filesync.MockDriftDetector
+	MockActionsScanner  *actions.MockScanner
+	MockDockerScanner   *docker.MockScanner
+	Store               state.Store
 	MockDagger          *dagger.MockDagger
-	MockGitHubClient    *github.MockClient
+	MockGitHubClient    *forge.MockClient
+	MockUpdater         *gomod.MockUpdater
+	// VendorModules are the vendor/modules.txt files of the repositories, by ref.
+	VendorModules map[string][]byte
 }
 
 // newTestDepSync creates a TestDepSync instance with all mocked dependencies
@@ -35,11 +58,25 @@ func newTestDepSync(t *testing.T, cfg *config.Config) *TestDepSync {
 	mockGraphBuilder := depgraph.NewMockGraphBuilder(ctrl)
 	mockVersionDetector := repo.NewMockVersionDetector(ctrl)
 	mockChecker := depgraph.NewMockInconsistencyChecker(ctrl)
+	mockToolsChecker := depgraph.NewMockToolsChecker(ctrl)
+	mockDriftDetector := filesync.NewMockDriftDetector(ctrl)
+	mockActionsScanner := actions.NewMockScanner(ctrl)
+	mockDockerScanner := docker.NewMockScanner(ctrl)
+	store := state.NewMemoryStore()
 	mockDagger := dagger.NewMockDagger(ctrl)
-	mockGitHubClient := github.NewMockClient(ctrl)
+	mockGitHubClient := forge.NewMockClient(ctrl)
+	mockUpdater := gomod.NewMockUpdater(ctrl)
 
-	// Set up default expectations
-	mockDagger.EXPECT().Close().Return(nil)
+	// The repositories commit no vendor directory, unless its vendor/modules.txt file is set by ref
+	vendorModules := make(map[string][]byte)
+	mockFetcher.EXPECT().Fetch(gomock.Any(), gomock.Any(), gomock.Any(), gomod.VendorModulesFile).
+		DoAndReturn(func(_ context.Context, _, ref string, _ ...string) (map[string][]byte, error) {
+			content, ok := vendorModules[ref]
+			if !ok {
+				return nil, forge.ErrFileNotFound
+			}
+			return map[string][]byte{gomod.VendorModulesFile: content}, nil
+		}).AnyTimes()
 
 	// Create DepSync directly, avoiding New() which requires Docker
 	c := &DepSync{
@@ -49,8 +86,30 @@ func newTestDepSync(t *testing.T, cfg *config.Config) *TestDepSync {
 		graphBuilder:    mockGraphBuilder,
 		versionDetector: mockVersionDetector,
 		checker:         mockChecker,
+		toolsChecker:    mockToolsChecker,
+		driftDetector:   mockDriftDetector,
+		actionsScanner:  mockActionsScanner,
+		dockerScanner:   mockDockerScanner,
+		store:           store,
 		dagger:          mockDagger,
 	}
+	if cfg.Lightweight.Enabled {
+		c.updater = mockUpdater
+	}
+
+	// Record the actions in a plan in dry-run mode, as New() does: the Dagger mock methods,
+	// except the image digest resolution, and the GitHub client mock write methods must then
+	// never be called
+	if cfg.DryRun {
+		c.plan = plan.New()
+		c.dagger = plan.NewDagger(mockGitHubClient, c.plan, mockDagger)
+		c.client = plan.NewGitHubClient(mockGitHubClient, c.plan)
+		c.store = state.NewReadOnlyStore(store)
+	} else {
+		mockDagger.EXPECT().Close().Return(nil)
+		// The working copies are removed at the end of the runs
+		mockDagger.EXPECT().RemoveRepo(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	}
 
 	return &TestDepSync{
 		DepSync:             c,
@@ -59,7 +118,34 @@ func newTestDepSync(t *testing.T, cfg *config.Config) *TestDepSync {
 		MockGraphBuilder:    mockGraphBuilder,
 		MockVersionDetector: mockVersionDetector,
 		MockChecker:         mockChecker,
+		MockToolsChecker:    mockToolsChecker,
+		MockDriftDetector:   mockDriftDetector,
+		MockActionsScanner:  mockActionsScanner,
+		MockDockerScanner:   mockDockerScanner,
+		Store:               store,
 		MockDagger:          mockDagger,
 		MockGitHubClient:    mockGitHubClient,
+		MockUpdater:         mockUpdater,
+		VendorModules:       vendorModules,
+	}
+}
+
+// expectSingleServiceMismatches sets up the expectations of a run on github.com/test/repo,
+// the only service of the graph, detecting the given mismatches of its dependencies.
+func expectSingleServiceMismatches(tc *TestDepSync, mismatches map[string]depgraph.Mismatch) {
+	tc.MockFetcher.EXPECT().
+		Fetch(gomock.Any(), "https://github.com/test/repo", "main", "go.mod").
+		Return(map[string][]byte{"go.mod": []byte("module github.com/test/repo\n")}, nil)
+
+	mockGraph := map[string]*depgraph.Service{
+		"github.com/test/repo": {
+			ModulePath:   "github.com/test/repo",
+			Dependencies: map[string]depgraph.Dependency{},
+		},
 	}
+	tc.MockGraphBuilder.EXPECT().BuildGraph(gomock.Any()).Return(mockGraph, nil)
+	tc.MockVersionDetector.EXPECT().DetectAndSetCurrentVersions(gomock.Any(), gomock.Any(), mockGraph).Return(nil)
+	tc.MockChecker.EXPECT().Check(mockGraph).Return(map[string]map[string]depgraph.Mismatch{
+		"github.com/test/repo": mismatches,
+	}, nil)
 }
@@ -0,0 +1,113 @@
+//go:build unit
+// +build unit
+
+package depsync
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/depgraph"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// expectConcurrentGraph sets the expectations for a graph of the given services, each
+// requiring github.com/test/dep v1.0.0 whose latest version is v1.1.0.
+func expectConcurrentGraph(tc *TestDepSync, services ...string) {
+	mismatches := make(map[string]map[string]depgraph.Mismatch)
+	for _, svc := range services {
+		tc.MockFetcher.EXPECT().
+			Fetch(gomock.Any(), "https://"+svc, "main", "go.mod").
+			Return(map[string][]byte{"go.mod": []byte("module " + svc + "\n")}, nil)
+		mismatches[svc] = map[string]depgraph.Mismatch{
+			"github.com/test/dep": {Actual: "v1.0.0", Latest: "v1.1.0"},
+		}
+	}
+
+	graph := map[string]*depgraph.Service{}
+	tc.MockGraphBuilder.EXPECT().BuildGraph(gomock.Any()).Return(graph, nil)
+	tc.MockVersionDetector.EXPECT().DetectAndSetCurrentVersions(gomock.Any(), gomock.Any(), graph).Return(nil)
+	tc.MockChecker.EXPECT().Check(graph).Return(mismatches, nil)
+}
+
+// barrier returns a function blocking until it has been called n times, or failing after a timeout.
+func barrier(t *testing.T, n int) func() {
+	var wg sync.WaitGroup
+	wg.Add(n)
+	return func() {
+		wg.Done()
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Error("services were not processed concurrently")
+		}
+	}
+}
+
+func TestDepSync_Run_Concurrency(t *testing.T) {
+	services := []string{"github.com/test/a", "github.com/test/b", "github.com/test/c"}
+	cfg := &config.Config{
+		Repositories: []string{"https://github.com/test/a", "https://github.com/test/b", "https://github.com/test/c"},
+		Concurrency:  3,
+	}
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	expectConcurrentGraph(tc, services...)
+
+	// Every clone waits for the others: the run only completes if the services are processed concurrently
+	wait := barrier(t, len(services))
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), gomock.Any(), "main").
+		DoAndReturn(func(context.Context, string, string) (dagger.Workspace, error) {
+			wait()
+			return nil, nil
+		}).Times(len(services))
+	tc.MockDagger.EXPECT().CheckBranchExists(gomock.Any(), gomock.Any()).Return(true, nil).Times(len(services))
+	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(gomock.Any(), gomock.Any()).Return(-1, nil).Times(len(services))
+	tc.MockGitHubClient.EXPECT().CreateMergeRequest(gomock.Any(), gomock.Any()).Return(1, nil).Times(len(services))
+
+	require.NoError(t, tc.DepSync.Run(context.Background()))
+}
+
+func TestDepSync_Run_Concurrency_Errors(t *testing.T) {
+	cfg := &config.Config{
+		Repositories: []string{"https://github.com/test/a", "https://github.com/test/b"},
+		Concurrency:  2,
+	}
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	expectConcurrentGraph(tc, "github.com/test/a", "github.com/test/b")
+
+	// Both clones fail, b before a: the errors are still reported in the services order
+	wait := barrier(t, 2)
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), "https://github.com/test/a", "main").
+		DoAndReturn(func(context.Context, string, string) (dagger.Workspace, error) {
+			wait()
+			time.Sleep(10 * time.Millisecond)
+			return nil, errors.New("clone a failed")
+		})
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), "https://github.com/test/b", "main").
+		DoAndReturn(func(context.Context, string, string) (dagger.Workspace, error) {
+			wait()
+			return nil, errors.New("clone b failed")
+		})
+
+	err := tc.DepSync.Run(context.Background())
+	require.ErrorContains(t, err, "clone a failed\nclone b failed")
+}
@@ -8,7 +8,7 @@ import (
 	"testing"
 
 	"github.com/cryptellation/depsync/pkg/adapters/dagger"
-	"github.com/cryptellation/depsync/pkg/adapters/github"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
 	"github.com/cryptellation/depsync/pkg/config"
 	"github.com/cryptellation/depsync/pkg/depgraph"
 	"github.com/stretchr/testify/assert"
@@ -69,7 +69,7 @@ func TestDepSync_Run_WithRepositories_DeleteConflictedPRsEnabled(t *testing.T) {
 	// Mock the CheckPullRequestExists call (returns PR number - PR already exists)
 	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(
 		gomock.Any(),
-		github.CheckPullRequestExistsParams{
+		forge.CheckPullRequestExistsParams{
 			RepoURL:      "https://github.com/test/repo",
 			SourceBranch: "depsync/update-github-com-test-dep-v1.1.0",
 		},
@@ -78,7 +78,7 @@ func TestDepSync_Run_WithRepositories_DeleteConflictedPRsEnabled(t *testing.T) {
 	// Mock the CheckMergeConflicts call - conflicts detected
 	tc.MockGitHubClient.EXPECT().CheckMergeConflicts(
 		gomock.Any(),
-		github.CheckMergeConflictsParams{
+		forge.CheckMergeConflictsParams{
 			RepoURL:  "https://github.com/test/repo",
 			PRNumber: 123,
 		},
@@ -87,7 +87,7 @@ func TestDepSync_Run_WithRepositories_DeleteConflictedPRsEnabled(t *testing.T) {
 	// Mock the deletion operations
 	tc.MockGitHubClient.EXPECT().DeletePullRequest(
 		gomock.Any(),
-		github.DeletePullRequestParams{
+		forge.DeletePullRequestParams{
 			RepoURL:  "https://github.com/test/repo",
 			PRNumber: 123,
 		},
@@ -95,7 +95,7 @@ func TestDepSync_Run_WithRepositories_DeleteConflictedPRsEnabled(t *testing.T) {
 
 	tc.MockGitHubClient.EXPECT().DeleteBranch(
 		gomock.Any(),
-		github.DeleteBranchParams{
+		forge.DeleteBranchParams{
 			RepoURL:    "https://github.com/test/repo",
 			BranchName: "depsync/update-github-com-test-dep-v1.1.0",
 		},
@@ -160,7 +160,7 @@ func TestDepSync_Run_WithRepositories_DeleteConflictedPRsDisabled(t *testing.T)
 	// Mock the CheckPullRequestExists call (returns PR number - PR already exists)
 	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(
 		gomock.Any(),
-		github.CheckPullRequestExistsParams{
+		forge.CheckPullRequestExistsParams{
 			RepoURL:      "https://github.com/test/repo",
 			SourceBranch: "depsync/update-github-com-test-dep-v1.1.0",
 		},
@@ -169,15 +169,15 @@ func TestDepSync_Run_WithRepositories_DeleteConflictedPRsDisabled(t *testing.T)
 	// Mock the check and merge operations (deletion is disabled, so continue with normal flow)
 	tc.MockGitHubClient.EXPECT().GetPullRequestChecks(
 		gomock.Any(),
-		github.GetPullRequestChecksParams{
+		forge.GetPullRequestChecksParams{
 			RepoURL:  "https://github.com/test/repo",
 			PRNumber: 123,
 		},
-	).Return(&github.CheckStatus{Status: "passed"}, nil)
+	).Return(&forge.CheckStatus{Status: "passed"}, nil)
 
 	tc.MockGitHubClient.EXPECT().MergeMergeRequest(
 		gomock.Any(),
-		github.MergeMergeRequestParams{
+		forge.MergeMergeRequestParams{
 			RepoURL:       "https://github.com/test/repo",
 			PRNumber:      123,
 			ModulePath:    "github.com/test/dep",
@@ -187,7 +187,7 @@ func TestDepSync_Run_WithRepositories_DeleteConflictedPRsDisabled(t *testing.T)
 
 	tc.MockGitHubClient.EXPECT().DeleteBranch(
 		gomock.Any(),
-		github.DeleteBranchParams{
+		forge.DeleteBranchParams{
 			RepoURL:    "https://github.com/test/repo",
 			BranchName: "depsync/update-github-com-test-dep-v1.1.0",
 		},
@@ -252,7 +252,7 @@ func TestDepSync_Run_WithRepositories_DeleteConflictedPRsError(t *testing.T) {
 	// Mock the CheckPullRequestExists call (returns PR number - PR already exists)
 	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(
 		gomock.Any(),
-		github.CheckPullRequestExistsParams{
+		forge.CheckPullRequestExistsParams{
 			RepoURL:      "https://github.com/test/repo",
 			SourceBranch: "depsync/update-github-com-test-dep-v1.1.0",
 		},
@@ -261,7 +261,7 @@ func TestDepSync_Run_WithRepositories_DeleteConflictedPRsError(t *testing.T) {
 	// Mock the CheckMergeConflicts call - conflicts detected
 	tc.MockGitHubClient.EXPECT().CheckMergeConflicts(
 		gomock.Any(),
-		github.CheckMergeConflictsParams{
+		forge.CheckMergeConflictsParams{
 			RepoURL:  "https://github.com/test/repo",
 			PRNumber: 123,
 		},
@@ -270,7 +270,7 @@ func TestDepSync_Run_WithRepositories_DeleteConflictedPRsError(t *testing.T) {
 	// Mock the deletion operations - PR deletion fails
 	tc.MockGitHubClient.EXPECT().DeletePullRequest(
 		gomock.Any(),
-		github.DeletePullRequestParams{
+		forge.DeletePullRequestParams{
 			RepoURL:  "https://github.com/test/repo",
 			PRNumber: 123,
 		},
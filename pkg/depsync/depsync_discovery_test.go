@@ -0,0 +1,101 @@
+//go:build unit
+// +build unit
+
+package depsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/depgraph"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDepSync_BuildGraph_GraphQLDiscovery(t *testing.T) {
+	cfg := &config.Config{
+		Repositories: []string{"https://github.com/test/repo", "https://github.com/test/dep"},
+		GitHub:       config.GitHubConfig{Discovery: config.DiscoveryGraphQL},
+		Tools:        config.ToolsConfig{Enabled: true, Files: []string{"tools.go"}},
+	}
+
+	// Neither the files fetcher nor the version detector are used
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	goMod := []byte("module github.com/test/repo\nrequire github.com/test/dep v1.0.0\n")
+	tools := []byte("package tools\n")
+	tc.MockGitHubClient.EXPECT().DiscoverRepositories(gomock.Any(), forge.DiscoverRepositoriesParams{
+		RepoURLs: cfg.Repositories,
+		Ref:      "main",
+		Paths:    []string{"go.mod", "tools.go"},
+	}).Return([]forge.DiscoveredRepository{
+		{
+			RepoURL: "https://github.com/test/repo",
+			Files:   map[string][]byte{"go.mod": goMod, "tools.go": tools},
+		},
+		{
+			RepoURL: "https://github.com/test/dep",
+			Files:   map[string][]byte{"go.mod": []byte("module github.com/test/dep\n")},
+			Tags: []forge.Tag{
+				{Name: "v1.2.0-rc.1"}, {Name: "v1.1.0"}, {Name: "v1.0.0"},
+			},
+		},
+	}, nil)
+
+	graph := map[string]*depgraph.Service{
+		"github.com/test/repo": {ModulePath: "github.com/test/repo"},
+		"github.com/test/dep":  {ModulePath: "github.com/test/dep"},
+	}
+	tc.MockGraphBuilder.EXPECT().BuildGraph(map[string]depgraph.RepoModule{
+		"github.com/test/repo": {
+			RepoURL:      "https://github.com/test/repo",
+			GoModContent: goMod,
+			ToolsFiles:   map[string][]byte{"tools.go": tools},
+		},
+		"github.com/test/dep": {
+			RepoURL:      "https://github.com/test/dep",
+			GoModContent: []byte("module github.com/test/dep\n"),
+			ToolsFiles:   map[string][]byte{},
+		},
+	}).Return(graph, nil)
+
+	res, err := tc.DepSync.buildGraph(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "v1.1.0", res["github.com/test/dep"].LatestVersion)
+	require.Empty(t, res["github.com/test/repo"].LatestVersion)
+}
+
+func TestDepSync_BuildGraph_GraphQLDiscovery_MissingGoMod(t *testing.T) {
+	cfg := &config.Config{
+		Repositories: []string{"https://github.com/test/repo"},
+		GitHub:       config.GitHubConfig{Discovery: config.DiscoveryGraphQL},
+	}
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	tc.MockGitHubClient.EXPECT().DiscoverRepositories(gomock.Any(), gomock.Any()).Return(
+		[]forge.DiscoveredRepository{{RepoURL: "https://github.com/test/repo", Files: map[string][]byte{}}}, nil)
+
+	_, err := tc.DepSync.buildGraph(context.Background())
+	require.ErrorContains(t, err, "go.mod not found in repository: https://github.com/test/repo")
+}
+
+func TestDepSync_BuildGraph_UnknownDiscovery(t *testing.T) {
+	cfg := &config.Config{
+		Repositories: []string{"https://github.com/test/repo"},
+		GitHub:       config.GitHubConfig{Discovery: "ftp"},
+	}
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	_, err := tc.DepSync.buildGraph(context.Background())
+	require.ErrorContains(t, err, "unknown discovery method: ftp")
+}
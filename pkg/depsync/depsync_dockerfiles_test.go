@@ -0,0 +1,89 @@
+//go:build unit
+// +build unit
+
+package depsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/depgraph"
+	"github.com/cryptellation/depsync/pkg/docker"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDepSync_Run_Dockerfiles_CreatesMergeRequest(t *testing.T) {
+	cfg := &config.Config{
+		Repositories: []string{"https://github.com/test/repo"},
+		Dockerfiles: config.DockerfilesConfig{
+			Enabled: true,
+			Paths:   []string{"Dockerfile"},
+		},
+		Git: config.GitConfig{
+			Author: config.GitAuthor{
+				Name:  "DepSync Bot",
+				Email: "depsync@example.com",
+			},
+		},
+	}
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	tc.MockFetcher.EXPECT().
+		Fetch(gomock.Any(), "https://github.com/test/repo", "main", "go.mod").
+		Return(map[string][]byte{"go.mod": []byte("module github.com/test/repo\ngo 1.24.5\n")}, nil)
+
+	mockGraph := map[string]*depgraph.Service{
+		"github.com/test/repo": {
+			ModulePath:   "github.com/test/repo",
+			RepoURL:      "https://github.com/test/repo",
+			GoVersion:    "1.24.5",
+			Dependencies: map[string]depgraph.Dependency{},
+		},
+	}
+	tc.MockGraphBuilder.EXPECT().BuildGraph(gomock.Any()).Return(mockGraph, nil)
+	tc.MockVersionDetector.EXPECT().DetectAndSetCurrentVersions(gomock.Any(), gomock.Any(), mockGraph).Return(nil)
+	tc.MockChecker.EXPECT().Check(mockGraph).Return(map[string]map[string]depgraph.Mismatch{}, nil)
+
+	drift := docker.Drift{
+		RepoURL: "https://github.com/test/repo",
+		Images: []docker.ImageDrift{
+			{File: "Dockerfile", Line: 1, Actual: "golang:1.23-alpine", Expected: "golang:1.24-alpine"},
+		},
+		Files: map[string][]byte{"Dockerfile": []byte("FROM golang:1.24-alpine\n")},
+	}
+	tc.MockDockerScanner.EXPECT().Scan(gomock.Any(), cfg.Dockerfiles, []docker.Target{
+		{RepoURL: "https://github.com/test/repo", GoVersion: "1.24.5"},
+	}).Return([]docker.Drift{drift}, nil)
+
+	change := newDockerfilesChange(drift)
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), "https://github.com/test/repo", "main").Return(nil, nil)
+	tc.MockDagger.EXPECT().CheckBranchExists(gomock.Any(), gomock.Any()).Return(false, nil)
+	tc.MockDagger.EXPECT().WriteFiles(gomock.Any(), dagger.WriteFilesParams{
+		Files: []dagger.File{{Path: "Dockerfile", Content: []byte("FROM golang:1.24-alpine\n")}},
+	}).Return(nil, nil)
+	tc.MockDagger.EXPECT().CommitAndPush(gomock.Any(), dagger.CommitAndPushParams{
+		BranchName:    change.BranchName,
+		AuthorName:    "DepSync Bot",
+		AuthorEmail:   "depsync@example.com",
+		RepoURL:       "https://github.com/test/repo",
+		CommitMessage: "chores(depsync): align Dockerfiles base images",
+	}).Return(change.BranchName, nil)
+
+	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(gomock.Any(), gomock.Any()).Return(-1, nil)
+	tc.MockGitHubClient.EXPECT().CreateMergeRequest(gomock.Any(), forge.CreateMergeRequestParams{
+		RepoURL:      "https://github.com/test/repo",
+		SourceBranch: change.BranchName,
+		Title:        "chores(depsync): align Dockerfiles base images",
+		Description:  generateDockerfilesMRDescription(drift),
+	}).Return(3, nil)
+
+	err := tc.DepSync.Run(context.Background())
+	assert.NoError(t, err)
+}
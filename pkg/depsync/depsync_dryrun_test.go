@@ -0,0 +1,113 @@
+//go:build unit
+// +build unit
+
+package depsync
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/depgraph"
+	"github.com/cryptellation/depsync/pkg/plan"
+	"github.com/cryptellation/depsync/pkg/state"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDepSync_Run_DryRun_NewUpdate(t *testing.T) {
+	cfg := &config.Config{
+		Repositories: []string{"https://github.com/test/repo"},
+		DryRun:       true,
+	}
+
+	// No expectation is set on the Dagger mock nor on the GitHub write methods:
+	// any call to them fails the test
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	expectSingleServiceMismatches(tc, map[string]depgraph.Mismatch{
+		"github.com/test/dep": {Actual: "v1.0.0", Latest: "v1.1.0"},
+	})
+	branchName := "depsync/update-github-com-test-dep-v1.1.0"
+	tc.MockGitHubClient.EXPECT().BranchExists(gomock.Any(), forge.BranchExistsParams{
+		RepoURL:    "https://github.com/test/repo",
+		BranchName: branchName,
+	}).Return(false, nil)
+	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(gomock.Any(), forge.CheckPullRequestExistsParams{
+		RepoURL:      "https://github.com/test/repo",
+		SourceBranch: branchName,
+	}).Return(-1, nil)
+
+	require.NoError(t, tc.DepSync.Run(context.Background()))
+
+	require.Equal(t, []plan.Action{
+		{Type: plan.ActionClone, RepoURL: "https://github.com/test/repo", BranchName: "main"},
+		{Type: plan.ActionUpdate, RepoURL: "https://github.com/test/repo", Details: "go get github.com/test/dep@v1.1.0"},
+		{
+			Type:       plan.ActionPushBranch,
+			RepoURL:    "https://github.com/test/repo",
+			BranchName: branchName,
+			Details:    "chores(depsync): update github.com/test/dep to v1.1.0",
+		},
+		{
+			Type:       plan.ActionCreatePR,
+			RepoURL:    "https://github.com/test/repo",
+			BranchName: branchName,
+			Details:    "chores(depsync): update github.com/test/dep to v1.1.0",
+		},
+	}, tc.DepSync.Plan().Actions())
+
+	// Nothing is recorded in the state store
+	records, err := tc.Store.List(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, records)
+}
+
+func TestDepSync_Run_DryRun_MergeExistingPullRequest(t *testing.T) {
+	cfg := &config.Config{
+		Repositories:        []string{"https://github.com/test/repo"},
+		DeleteConflictedPRs: true,
+		DryRun:              true,
+	}
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	expectSingleServiceMismatches(tc, map[string]depgraph.Mismatch{
+		"github.com/test/dep": {Actual: "v1.0.0", Latest: "v1.1.0"},
+	})
+	branchName := "depsync/update-github-com-test-dep-v1.1.0"
+	tc.MockGitHubClient.EXPECT().BranchExists(gomock.Any(), gomock.Any()).Return(true, nil)
+	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(gomock.Any(), gomock.Any()).Return(42, nil)
+	tc.MockGitHubClient.EXPECT().CheckMergeConflicts(gomock.Any(), forge.CheckMergeConflictsParams{
+		RepoURL:  "https://github.com/test/repo",
+		PRNumber: 42,
+	}).Return(false, nil)
+	tc.MockGitHubClient.EXPECT().GetPullRequestChecks(gomock.Any(), forge.GetPullRequestChecksParams{
+		RepoURL:  "https://github.com/test/repo",
+		PRNumber: 42,
+	}).Return(&forge.CheckStatus{Status: "passed"}, nil)
+
+	require.NoError(t, tc.DepSync.Run(context.Background()))
+
+	require.Equal(t, []plan.Action{
+		{Type: plan.ActionClone, RepoURL: "https://github.com/test/repo", BranchName: "main"},
+		{Type: plan.ActionMerge, RepoURL: "https://github.com/test/repo", PRNumber: 42},
+		{Type: plan.ActionDeleteBranch, RepoURL: "https://github.com/test/repo", BranchName: branchName},
+	}, tc.DepSync.Plan().Actions())
+
+	var out bytes.Buffer
+	require.NoError(t, tc.DepSync.Plan().Print(&out))
+	require.Equal(t, "3 action(s) to take:\n"+
+		"  1. clone https://github.com/test/repo branch=main\n"+
+		"  2. merge https://github.com/test/repo pr=#42\n"+
+		"  3. delete_branch https://github.com/test/repo branch="+branchName+"\n", out.String())
+
+	_, err := tc.Store.Get(context.Background(), updateKey("github.com/test/repo", "github.com/test/dep", "v1.1.0"))
+	require.ErrorIs(t, err, state.ErrNotFound)
+}
@@ -0,0 +1,132 @@
+//go:build unit
+// +build unit
+
+package depsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/filesync"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func newFileSyncConfig() *config.Config {
+	return &config.Config{
+		Files: []config.FileSync{{
+			Source: config.FileSource{
+				Repository: "https://github.com/test/templates",
+				Path:       "lint.yml",
+				Ref:        "main",
+			},
+			Targets: []config.FileTarget{
+				{Repository: "https://github.com/test/repo", Path: ".golangci.yml"},
+			},
+		}},
+		Git: config.GitConfig{
+			Author: config.GitAuthor{
+				Name:  "DepSync Bot",
+				Email: "depsync@example.com",
+			},
+		},
+	}
+}
+
+func TestDepSync_Run_FileSync_CreatesMergeRequest(t *testing.T) {
+	cfg := newFileSyncConfig()
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	drift := filesync.Drift{
+		RepoURL:    "https://github.com/test/repo",
+		Path:       ".golangci.yml",
+		SourceURL:  "https://github.com/test/templates",
+		SourcePath: "lint.yml",
+		Content:    []byte("linters: {}\n"),
+	}
+	branchName := generateFileSyncBranchName(drift.Path, drift.Content)
+	tc.MockDriftDetector.EXPECT().Detect(gomock.Any(), cfg.Files).Return([]filesync.Drift{drift}, nil)
+
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), "https://github.com/test/repo", "main").Return(nil, nil)
+	tc.MockDagger.EXPECT().CheckBranchExists(gomock.Any(), dagger.CheckBranchExistsParams{
+		BranchName: branchName,
+		RepoURL:    "https://github.com/test/repo",
+	}).Return(false, nil)
+	tc.MockDagger.EXPECT().WriteFiles(gomock.Any(), dagger.WriteFilesParams{
+		Files: []dagger.File{{Path: ".golangci.yml", Content: []byte("linters: {}\n")}},
+	}).Return(nil, nil)
+	tc.MockDagger.EXPECT().CommitAndPush(gomock.Any(), dagger.CommitAndPushParams{
+		BranchName:    branchName,
+		AuthorName:    "DepSync Bot",
+		AuthorEmail:   "depsync@example.com",
+		RepoURL:       "https://github.com/test/repo",
+		CommitMessage: "chores(depsync): sync .golangci.yml",
+	}).Return(branchName, nil)
+
+	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(gomock.Any(), forge.CheckPullRequestExistsParams{
+		RepoURL:      "https://github.com/test/repo",
+		SourceBranch: branchName,
+	}).Return(-1, nil)
+	tc.MockGitHubClient.EXPECT().CreateMergeRequest(gomock.Any(), forge.CreateMergeRequestParams{
+		RepoURL:      "https://github.com/test/repo",
+		SourceBranch: branchName,
+		Title:        "chores(depsync): sync .golangci.yml",
+		Description:  generateFileSyncMRDescription(drift),
+	}).Return(42, nil)
+
+	err := tc.DepSync.Run(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestDepSync_Run_FileSync_ExistingMergeRequestMerged(t *testing.T) {
+	cfg := newFileSyncConfig()
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	drift := filesync.Drift{
+		RepoURL:    "https://github.com/test/repo",
+		Path:       ".golangci.yml",
+		SourceURL:  "https://github.com/test/templates",
+		SourcePath: "lint.yml",
+		Content:    []byte("linters: {}\n"),
+	}
+	branchName := generateFileSyncBranchName(drift.Path, drift.Content)
+	tc.MockDriftDetector.EXPECT().Detect(gomock.Any(), cfg.Files).Return([]filesync.Drift{drift}, nil)
+
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), "https://github.com/test/repo", "main").Return(nil, nil)
+	tc.MockDagger.EXPECT().CheckBranchExists(gomock.Any(), gomock.Any()).Return(true, nil)
+
+	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(gomock.Any(), gomock.Any()).Return(7, nil)
+	tc.MockGitHubClient.EXPECT().GetPullRequestChecks(gomock.Any(), forge.GetPullRequestChecksParams{
+		RepoURL:  "https://github.com/test/repo",
+		PRNumber: 7,
+	}).Return(&forge.CheckStatus{Status: "passed"}, nil)
+	tc.MockGitHubClient.EXPECT().MergeMergeRequest(gomock.Any(), forge.MergeMergeRequestParams{
+		RepoURL:    "https://github.com/test/repo",
+		PRNumber:   7,
+		ModulePath: ".golangci.yml",
+	}).Return(nil)
+	tc.MockGitHubClient.EXPECT().DeleteBranch(gomock.Any(), forge.DeleteBranchParams{
+		RepoURL:    "https://github.com/test/repo",
+		BranchName: branchName,
+	}).Return(nil)
+
+	err := tc.DepSync.Run(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestGenerateFileSyncBranchName(t *testing.T) {
+	a := generateFileSyncBranchName(".github/workflows/ci.yaml", []byte("a"))
+	b := generateFileSyncBranchName(".github/workflows/ci.yaml", []byte("b"))
+
+	assert.Contains(t, a, "depsync/sync-github-workflows-ci-yaml-")
+	assert.NotEqual(t, a, b)
+}
@@ -0,0 +1,192 @@
+//go:build unit
+// +build unit
+
+package depsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/depgraph"
+	"github.com/cryptellation/depsync/pkg/state"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGroupMismatches_Rules(t *testing.T) {
+	cfg := config.GroupingConfig{
+		Enabled: true,
+		Groups: []config.DependencyGroup{
+			{Name: "internal", Patterns: []string{"github.com/test/..."}, BumpTypes: []string{"minor", "patch"}},
+			{Name: "patches", BumpTypes: []string{"patch"}},
+		},
+	}
+	mismatches := map[string]depgraph.Mismatch{
+		"github.com/test/a":     {Actual: "v1.0.0", Latest: "v1.1.0"},
+		"github.com/test/b":     {Actual: "v1.0.0", Latest: "v1.0.1"},
+		"github.com/test/c/v2":  {Actual: "v1.0.0", Latest: "v2.0.0"},
+		"github.com/other/d":    {Actual: "v0.1.0", Latest: "v0.1.3"},
+		"github.com/other/e/v3": {Actual: "v3.1.0", Latest: "v3.2.0"},
+	}
+
+	groups, singles := groupMismatches(cfg, mismatches)
+	assert.Equal(t, []dependencyGroup{
+		{Name: "internal", Mismatches: map[string]depgraph.Mismatch{
+			"github.com/test/a": {Actual: "v1.0.0", Latest: "v1.1.0"},
+			"github.com/test/b": {Actual: "v1.0.0", Latest: "v1.0.1"},
+		}},
+		{Name: "patches", Mismatches: map[string]depgraph.Mismatch{
+			"github.com/other/d": {Actual: "v0.1.0", Latest: "v0.1.3"},
+		}},
+	}, groups)
+	assert.Equal(t, map[string]depgraph.Mismatch{
+		"github.com/test/c/v2":  {Actual: "v1.0.0", Latest: "v2.0.0"},
+		"github.com/other/e/v3": {Actual: "v3.1.0", Latest: "v3.2.0"},
+	}, singles)
+}
+
+func TestGroupMismatches_NoRules(t *testing.T) {
+	mismatches := map[string]depgraph.Mismatch{
+		"github.com/test/a": {Actual: "v1.0.0", Latest: "v1.1.0"},
+		"github.com/test/b": {Actual: "v1.0.0", Latest: "v2.0.0"},
+	}
+
+	groups, singles := groupMismatches(config.GroupingConfig{Enabled: true}, mismatches)
+	assert.Equal(t, []dependencyGroup{{Name: "go", Mismatches: mismatches}}, groups)
+	assert.Empty(t, singles)
+}
+
+func TestDepSync_Run_Grouping_CreatesSingleMergeRequest(t *testing.T) {
+	cfg := &config.Config{
+		Repositories: []string{"https://github.com/test/repo"},
+		Grouping:     config.GroupingConfig{Enabled: true},
+		Git: config.GitConfig{
+			Author: config.GitAuthor{
+				Name:  "DepSync Bot",
+				Email: "depsync@example.com",
+			},
+		},
+	}
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	tc.MockFetcher.EXPECT().
+		Fetch(gomock.Any(), "https://github.com/test/repo", "main", "go.mod").
+		Return(map[string][]byte{"go.mod": []byte("module github.com/test/repo\n")}, nil)
+
+	mockGraph := map[string]*depgraph.Service{
+		"github.com/test/repo": {
+			ModulePath:   "github.com/test/repo",
+			GoVersion:    "1.24.5",
+			Dependencies: map[string]depgraph.Dependency{},
+		},
+	}
+	tc.MockGraphBuilder.EXPECT().BuildGraph(gomock.Any()).Return(mockGraph, nil)
+	tc.MockVersionDetector.EXPECT().DetectAndSetCurrentVersions(gomock.Any(), gomock.Any(), mockGraph).Return(nil)
+
+	group := dependencyGroup{Name: "go", Mismatches: map[string]depgraph.Mismatch{
+		"github.com/test/dep1": {Actual: "v1.0.0", Latest: "v1.1.0"},
+		"github.com/test/dep2": {Actual: "v0.2.0", Latest: "v0.2.1"},
+	}}
+	tc.MockChecker.EXPECT().Check(mockGraph).
+		Return(map[string]map[string]depgraph.Mismatch{"github.com/test/repo": group.Mismatches}, nil)
+
+	modules := []dagger.Module{
+		{Path: "github.com/test/dep1", Version: "v1.1.0"},
+		{Path: "github.com/test/dep2", Version: "v0.2.1"},
+	}
+	branchName := generateGroupBranchName("go", modules)
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), "https://github.com/test/repo", "main").Return(nil, nil).Times(1)
+	tc.MockDagger.EXPECT().CheckBranchExists(gomock.Any(), gomock.Any()).Return(false, nil)
+	tc.MockDagger.EXPECT().UpdateGoDependencies(gomock.Any(), dagger.UpdateGoDependenciesParams{
+		Modules:   modules,
+		GoVersion: "1.24.5",
+	}).Return(nil, nil)
+	tc.MockDagger.EXPECT().CommitAndPush(gomock.Any(), dagger.CommitAndPushParams{
+		BranchName:    branchName,
+		AuthorName:    "DepSync Bot",
+		AuthorEmail:   "depsync@example.com",
+		RepoURL:       "https://github.com/test/repo",
+		CommitMessage: "chores(depsync): update 2 go dependencies",
+	}).Return(branchName, nil)
+
+	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(gomock.Any(), gomock.Any()).Return(-1, nil)
+	tc.MockGitHubClient.EXPECT().CreateMergeRequest(gomock.Any(), forge.CreateMergeRequestParams{
+		RepoURL:      "https://github.com/test/repo",
+		SourceBranch: branchName,
+		Title:        "chores(depsync): update 2 go dependencies",
+		Description:  generateGroupMRDescription(group),
+	}).Return(5, nil)
+
+	err := tc.DepSync.Run(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestDepSync_Run_Grouping_ClosesSupersededGroup(t *testing.T) {
+	const repoURL = "https://github.com/test/repo"
+	tc := newTestDepSync(t, &config.Config{
+		Repositories: []string{repoURL},
+		Grouping:     config.GroupingConfig{Enabled: true},
+	})
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	// The pull request of the previous members of the group is open
+	ctx := context.Background()
+	oldBranch := generateGroupBranchName("go", []dagger.Module{{Path: "github.com/test/dep1", Version: "v1.1.0"}})
+	oldKey := updateKey(repoURL, "go", oldBranch)
+	assert.NoError(t, tc.Store.Record(ctx, oldKey, state.Event{
+		Type: state.EventPROpened, RepoURL: repoURL, BranchName: oldBranch, PRNumber: 4,
+	}))
+
+	tc.MockFetcher.EXPECT().
+		Fetch(gomock.Any(), repoURL, "main", "go.mod").
+		Return(map[string][]byte{"go.mod": []byte("module github.com/test/repo\n")}, nil)
+	mockGraph := map[string]*depgraph.Service{
+		"github.com/test/repo": {ModulePath: "github.com/test/repo", Dependencies: map[string]depgraph.Dependency{}},
+	}
+	tc.MockGraphBuilder.EXPECT().BuildGraph(gomock.Any()).Return(mockGraph, nil)
+	tc.MockVersionDetector.EXPECT().DetectAndSetCurrentVersions(gomock.Any(), gomock.Any(), mockGraph).Return(nil)
+	tc.MockChecker.EXPECT().Check(mockGraph).Return(map[string]map[string]depgraph.Mismatch{
+		"github.com/test/repo": {
+			"github.com/test/dep1": {Actual: "v1.0.0", Latest: "v1.1.0"},
+			"github.com/test/dep2": {Actual: "v0.2.0", Latest: "v0.2.1"},
+		},
+	}, nil)
+
+	branchName := generateGroupBranchName("go", []dagger.Module{
+		{Path: "github.com/test/dep1", Version: "v1.1.0"},
+		{Path: "github.com/test/dep2", Version: "v0.2.1"},
+	})
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), repoURL, "main").Return(nil, nil)
+	tc.MockDagger.EXPECT().CheckBranchExists(gomock.Any(), gomock.Any()).Return(false, nil)
+	tc.MockDagger.EXPECT().UpdateGoDependencies(gomock.Any(), gomock.Any()).Return(nil, nil)
+	tc.MockDagger.EXPECT().CommitAndPush(gomock.Any(), gomock.Any()).Return(branchName, nil)
+	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(gomock.Any(), gomock.Any()).Return(-1, nil)
+	tc.MockGitHubClient.EXPECT().CreateMergeRequest(gomock.Any(), gomock.Any()).Return(5, nil)
+
+	// The new branch supersedes the previous one, whose pull request is closed
+	tc.MockGitHubClient.EXPECT().GetPullRequestState(gomock.Any(), forge.GetPullRequestStateParams{
+		RepoURL: repoURL, PRNumber: 4,
+	}).Return(forge.PullRequestStateOpen, nil)
+	tc.MockGitHubClient.EXPECT().DeletePullRequest(gomock.Any(), forge.DeletePullRequestParams{
+		RepoURL: repoURL, PRNumber: 4,
+	}).Return(nil)
+	tc.MockGitHubClient.EXPECT().DeleteBranch(gomock.Any(), forge.DeleteBranchParams{
+		RepoURL: repoURL, BranchName: oldBranch,
+	}).Return(nil)
+
+	assert.NoError(t, tc.DepSync.Run(ctx))
+
+	record, err := tc.Store.Get(ctx, oldKey)
+	assert.NoError(t, err)
+	assert.Equal(t, state.EventPRDeleted, record.Status)
+	record, err = tc.Store.Get(ctx, updateKey(repoURL, "go", branchName))
+	assert.NoError(t, err)
+	assert.Equal(t, state.EventPROpened, record.Status)
+}
@@ -0,0 +1,164 @@
+//go:build unit
+// +build unit
+
+package depsync
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/depgraph"
+	"github.com/cryptellation/depsync/pkg/gomod"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/mod/module"
+)
+
+const (
+	lightweightRepoURL = "https://github.com/test/repo"
+	lightweightBranch  = "depsync/update-github-com-test-dep-v1.1.0"
+	// lightweightBaseSHA is the head of main the files are read at.
+	lightweightBaseSHA = "base123"
+)
+
+// newLightweightTestDepSync creates a TestDepSync with the lightweight updates enabled, and
+// expects the detection of an update of github.com/test/dep to v1.1.0.
+func newLightweightTestDepSync(t *testing.T, grouping bool) *TestDepSync {
+	cfg := &config.Config{
+		Repositories: []string{lightweightRepoURL},
+		Grouping:     config.GroupingConfig{Enabled: grouping},
+		Lightweight:  config.LightweightConfig{Enabled: true},
+		Git: config.GitConfig{
+			Author: config.GitAuthor{
+				Name:  "DepSync Bot",
+				Email: "depsync@example.com",
+			},
+		},
+	}
+	tc := newTestDepSync(t, cfg)
+
+	expectSingleServiceMismatches(tc, map[string]depgraph.Mismatch{
+		"github.com/test/dep": {Actual: "v1.0.0", Latest: "v1.1.0"},
+	})
+	return tc
+}
+
+// expectLightweightBase expects the check of the branch, and the retrieval of the head of main.
+func expectLightweightBase(tc *TestDepSync, branchName string) {
+	tc.MockGitHubClient.EXPECT().BranchExists(gomock.Any(), forge.BranchExistsParams{
+		RepoURL:    lightweightRepoURL,
+		BranchName: branchName,
+	}).Return(false, nil)
+	tc.MockGitHubClient.EXPECT().GetBranchSHA(gomock.Any(), forge.GetBranchSHAParams{
+		RepoURL:    lightweightRepoURL,
+		BranchName: "main",
+	}).Return(lightweightBaseSHA, nil)
+}
+
+// expectLightweightFiles expects the fetch of the go.mod and go.sum files of the repository,
+// at the head of main.
+func expectLightweightFiles(tc *TestDepSync, branchName string) {
+	expectLightweightBase(tc, branchName)
+	tc.MockFetcher.EXPECT().
+		Fetch(gomock.Any(), lightweightRepoURL, lightweightBaseSHA, "go.mod", "go.sum").
+		Return(map[string][]byte{"go.mod": []byte("old go.mod"), "go.sum": []byte("old go.sum")}, nil)
+}
+
+// expectMergeRequest expects the creation of the merge request of the branch.
+func expectMergeRequest(tc *TestDepSync, branchName string) {
+	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(gomock.Any(), forge.CheckPullRequestExistsParams{
+		RepoURL:      lightweightRepoURL,
+		SourceBranch: branchName,
+	}).Return(-1, nil)
+	tc.MockGitHubClient.EXPECT().CreateMergeRequest(gomock.Any(), gomock.Any()).Return(123, nil)
+}
+
+func TestDepSync_Run_Lightweight_CommitsWithoutClone(t *testing.T) {
+	tc := newLightweightTestDepSync(t, false)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	expectLightweightFiles(tc, lightweightBranch)
+	tc.MockUpdater.EXPECT().Update(gomock.Any(), gomod.UpdateParams{
+		GoMod:   []byte("old go.mod"),
+		GoSum:   []byte("old go.sum"),
+		Modules: []module.Version{{Path: "github.com/test/dep", Version: "v1.1.0"}},
+	}).Return(gomod.UpdateResult{GoMod: []byte("new go.mod"), GoSum: []byte("new go.sum")}, nil)
+	tc.MockGitHubClient.EXPECT().CreateCommit(gomock.Any(), forge.CreateCommitParams{
+		RepoURL:    lightweightRepoURL,
+		BaseBranch: "main",
+		BaseSHA:    lightweightBaseSHA,
+		BranchName: lightweightBranch,
+		Message:    "chores(depsync): update github.com/test/dep to v1.1.0",
+		Files: []forge.CommitFile{
+			{Path: "go.mod", Content: []byte("new go.mod")},
+			{Path: "go.sum", Content: []byte("new go.sum")},
+		},
+		AuthorName:  "DepSync Bot",
+		AuthorEmail: "depsync@example.com",
+	}).Return("abc123", nil)
+	expectMergeRequest(tc, lightweightBranch)
+
+	// The runner is not used: the Dagger mock expects no call
+	assert.NoError(t, tc.DepSync.Run(context.Background()))
+}
+
+func TestDepSync_Run_Lightweight_FallsBackToRunner(t *testing.T) {
+	tc := newLightweightTestDepSync(t, false)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	expectLightweightFiles(tc, lightweightBranch)
+	tc.MockUpdater.EXPECT().Update(gomock.Any(), gomock.Any()).
+		Return(gomod.UpdateResult{}, fmt.Errorf("%w: go version", gomod.ErrTidyRequired))
+
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), lightweightRepoURL, "main").Return(nil, nil)
+	tc.MockDagger.EXPECT().CheckBranchExists(gomock.Any(), gomock.Any()).Return(false, nil)
+	tc.MockDagger.EXPECT().UpdateGoDependency(gomock.Any(), gomock.Any()).Return(nil, nil)
+	tc.MockDagger.EXPECT().CommitAndPush(gomock.Any(), gomock.Any()).Return(lightweightBranch, nil)
+	expectMergeRequest(tc, lightweightBranch)
+
+	assert.NoError(t, tc.DepSync.Run(context.Background()))
+}
+
+func TestDepSync_Run_Lightweight_BranchExists(t *testing.T) {
+	tc := newLightweightTestDepSync(t, false)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	tc.MockGitHubClient.EXPECT().BranchExists(gomock.Any(), forge.BranchExistsParams{
+		RepoURL:    lightweightRepoURL,
+		BranchName: lightweightBranch,
+	}).Return(true, nil)
+	expectMergeRequest(tc, lightweightBranch)
+
+	assert.NoError(t, tc.DepSync.Run(context.Background()))
+}
+
+func TestDepSync_Run_Lightweight_Group(t *testing.T) {
+	tc := newLightweightTestDepSync(t, true)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	branchName := generateGroupBranchName("go", []dagger.Module{{Path: "github.com/test/dep", Version: "v1.1.0"}})
+	expectLightweightFiles(tc, branchName)
+	tc.MockUpdater.EXPECT().Update(gomock.Any(), gomod.UpdateParams{
+		GoMod:   []byte("old go.mod"),
+		GoSum:   []byte("old go.sum"),
+		Modules: []module.Version{{Path: "github.com/test/dep", Version: "v1.1.0"}},
+	}).Return(gomod.UpdateResult{GoMod: []byte("new go.mod"), GoSum: []byte("new go.sum")}, nil)
+	tc.MockGitHubClient.EXPECT().CreateCommit(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, params forge.CreateCommitParams) (string, error) {
+			assert.Equal(t, branchName, params.BranchName)
+			assert.Equal(t, lightweightBaseSHA, params.BaseSHA)
+			assert.Equal(t, "chores(depsync): update 1 go dependencies", params.Message)
+			return "abc123", nil
+		})
+	expectMergeRequest(tc, branchName)
+
+	assert.NoError(t, tc.DepSync.Run(context.Background()))
+}
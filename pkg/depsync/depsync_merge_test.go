@@ -8,7 +8,7 @@ import (
 	"testing"
 
 	"github.com/cryptellation/depsync/pkg/adapters/dagger"
-	"github.com/cryptellation/depsync/pkg/adapters/github"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
 	"github.com/cryptellation/depsync/pkg/config"
 	"github.com/cryptellation/depsync/pkg/depgraph"
 	"github.com/stretchr/testify/assert"
@@ -81,7 +81,7 @@ func TestDepSync_Run_WithRepositories_ChecksPassAndMerge(t *testing.T) {
 	// Mock the CheckPullRequestExists call (returns -1 - no existing PR)
 	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(
 		gomock.Any(),
-		github.CheckPullRequestExistsParams{
+		forge.CheckPullRequestExistsParams{
 			RepoURL:      "https://github.com/test/repo",
 			SourceBranch: "depsync/update-github-com-test-dep-v1.1.0",
 		},
@@ -90,7 +90,7 @@ func TestDepSync_Run_WithRepositories_ChecksPassAndMerge(t *testing.T) {
 	// Mock the CreateMergeRequest call
 	tc.MockGitHubClient.EXPECT().CreateMergeRequest(
 		gomock.Any(),
-		github.CreateMergeRequestParams{
+		forge.CreateMergeRequestParams{
 			RepoURL:       "https://github.com/test/repo",
 			SourceBranch:  "depsync/update-github-com-test-dep-v1.1.0",
 			ModulePath:    "github.com/test/dep",
@@ -170,7 +170,7 @@ func TestDepSync_Run_WithRepositories_MergeFails(t *testing.T) {
 	// Mock the CheckPullRequestExists call (returns -1 - no existing PR)
 	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(
 		gomock.Any(),
-		github.CheckPullRequestExistsParams{
+		forge.CheckPullRequestExistsParams{
 			RepoURL:      "https://github.com/test/repo",
 			SourceBranch: "depsync/update-github-com-test-dep-v1.1.0",
 		},
@@ -179,7 +179,7 @@ func TestDepSync_Run_WithRepositories_MergeFails(t *testing.T) {
 	// Mock the CreateMergeRequest call
 	tc.MockGitHubClient.EXPECT().CreateMergeRequest(
 		gomock.Any(),
-		github.CreateMergeRequestParams{
+		forge.CreateMergeRequestParams{
 			RepoURL:       "https://github.com/test/repo",
 			SourceBranch:  "depsync/update-github-com-test-dep-v1.1.0",
 			ModulePath:    "github.com/test/dep",
@@ -248,7 +248,7 @@ func TestDepSync_Run_WithRepositories_BranchExists(t *testing.T) {
 	// Mock the CheckPullRequestExists call (returns PR number - PR already exists)
 	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(
 		gomock.Any(),
-		github.CheckPullRequestExistsParams{
+		forge.CheckPullRequestExistsParams{
 			RepoURL:      "https://github.com/test/repo",
 			SourceBranch: "depsync/update-github-com-test-dep-v1.1.0",
 		},
@@ -257,11 +257,11 @@ func TestDepSync_Run_WithRepositories_BranchExists(t *testing.T) {
 	// Mock the GetPullRequestChecks call for existing PR
 	tc.MockGitHubClient.EXPECT().GetPullRequestChecks(
 		gomock.Any(),
-		github.GetPullRequestChecksParams{
+		forge.GetPullRequestChecksParams{
 			RepoURL:  "https://github.com/test/repo",
 			PRNumber: 123,
 		},
-	).Return(&github.CheckStatus{Status: "running"}, nil)
+	).Return(&forge.CheckStatus{Status: "running"}, nil)
 
 	// No CreateMergeRequest call expected since PR already exists
 
@@ -0,0 +1,187 @@
+//go:build unit
+// +build unit
+
+package depsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/depgraph"
+	"github.com/cryptellation/depsync/pkg/plan"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// expectPlanGraph sets the expectations for a graph where github.com/test/repo requires
+// github.com/test/dep at the given version, whose latest version is the given one.
+func expectPlanGraph(tc *TestDepSync, current, latest string) map[string]*depgraph.Service {
+	tc.MockFetcher.EXPECT().
+		Fetch(gomock.Any(), gomock.Any(), "main", "go.mod").
+		Return(map[string][]byte{"go.mod": []byte("module github.com/test/repo\n")}, nil).
+		AnyTimes()
+
+	dep := &depgraph.Service{
+		ModulePath:    "github.com/test/dep",
+		RepoURL:       "https://github.com/test/dep",
+		LatestVersion: latest,
+	}
+	graph := map[string]*depgraph.Service{
+		"github.com/test/repo": {
+			ModulePath: "github.com/test/repo",
+			RepoURL:    "https://github.com/test/repo",
+			Dependencies: map[string]depgraph.Dependency{
+				"github.com/test/dep": {Service: dep, CurrentVersion: current},
+			},
+		},
+		"github.com/test/dep": dep,
+	}
+	tc.MockGraphBuilder.EXPECT().BuildGraph(gomock.Any()).Return(graph, nil)
+	tc.MockVersionDetector.EXPECT().DetectAndSetCurrentVersions(gomock.Any(), gomock.Any(), graph).Return(nil)
+	return graph
+}
+
+// testPlanFile returns the plan updating github.com/test/dep from v1.0.0 to v1.1.0 in github.com/test/repo.
+func testPlanFile() *plan.File {
+	return &plan.File{
+		Version: plan.FileVersion,
+		Operations: []plan.Operation{{
+			Service:    "github.com/test/repo",
+			RepoURL:    "https://github.com/test/repo",
+			Dependency: "github.com/test/dep",
+			From:       "v1.0.0",
+			To:         "v1.1.0",
+			Preconditions: plan.Preconditions{
+				MainSHA:       "abc123",
+				TargetVersion: "v1.1.0",
+			},
+		}},
+	}
+}
+
+func TestDepSync_CreatePlan(t *testing.T) {
+	cfg := &config.Config{
+		Repositories: []string{"https://github.com/test/repo", "https://github.com/test/dep"},
+		DryRun:       true,
+	}
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	graph := expectPlanGraph(tc, "v1.0.0", "v1.1.0")
+	tc.MockChecker.EXPECT().Check(graph).Return(map[string]map[string]depgraph.Mismatch{
+		"github.com/test/repo": {
+			"github.com/test/dep": {Actual: "v1.0.0", Latest: "v1.1.0"},
+		},
+	}, nil)
+	tc.MockGitHubClient.EXPECT().GetBranchSHA(gomock.Any(), forge.GetBranchSHAParams{
+		RepoURL:    "https://github.com/test/repo",
+		BranchName: "main",
+	}).Return("abc123", nil)
+	tc.MockGitHubClient.EXPECT().BranchExists(gomock.Any(), gomock.Any()).Return(false, nil)
+	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(gomock.Any(), gomock.Any()).Return(-1, nil)
+
+	file, err := tc.DepSync.CreatePlan(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, testPlanFile().Operations, file.Operations)
+	require.Equal(t, []plan.ServiceSnapshot{
+		{
+			ModulePath:    "github.com/test/dep",
+			RepoURL:       "https://github.com/test/dep",
+			LatestVersion: "v1.1.0",
+			Dependencies:  map[string]string{},
+		},
+		{
+			ModulePath:   "github.com/test/repo",
+			RepoURL:      "https://github.com/test/repo",
+			Dependencies: map[string]string{"github.com/test/dep": "v1.0.0"},
+		},
+	}, file.Graph)
+	require.Len(t, file.Actions, 4)
+	require.Equal(t, plan.ActionCreatePR, file.Actions[3].Type)
+}
+
+func TestDepSync_CreatePlan_RequiresDryRun(t *testing.T) {
+	tc := newTestDepSync(t, &config.Config{})
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	_, err := tc.DepSync.CreatePlan(context.Background())
+	require.Error(t, err)
+}
+
+func TestDepSync_Plan_RefusesUnplannedSynchronizations(t *testing.T) {
+	cfg := &config.Config{
+		Repositories: []string{"https://github.com/test/repo"},
+		Files:        []config.FileSync{{}},
+		Actions:      config.ActionsConfig{Enabled: true},
+		Dockerfiles:  config.DockerfilesConfig{Enabled: true},
+	}
+
+	// No expectation is set on the mocks: nothing must be planned nor applied
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	err := tc.DepSync.ApplyPlan(context.Background(), testPlanFile())
+	require.ErrorContains(t, err, "disable the synchronization of: dockerfiles, files, actions")
+}
+
+func TestDepSync_ApplyPlan(t *testing.T) {
+	cfg := &config.Config{
+		Repositories: []string{"https://github.com/test/repo", "https://github.com/test/dep"},
+	}
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	expectPlanGraph(tc, "v1.0.0", "v1.1.0")
+	tc.MockGitHubClient.EXPECT().GetBranchSHA(gomock.Any(), gomock.Any()).Return("abc123", nil)
+
+	branchName := "depsync/update-github-com-test-dep-v1.1.0"
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), "https://github.com/test/repo", "main").Return(nil, nil)
+	tc.MockDagger.EXPECT().CheckBranchExists(gomock.Any(), gomock.Any()).Return(false, nil)
+	tc.MockDagger.EXPECT().UpdateGoDependency(gomock.Any(), dagger.UpdateGoDependencyParams{
+		ModulePath:    "github.com/test/dep",
+		TargetVersion: "v1.1.0",
+	}).Return(nil, nil)
+	tc.MockDagger.EXPECT().CommitAndPush(gomock.Any(), gomock.Any()).Return(branchName, nil)
+	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(gomock.Any(), gomock.Any()).Return(-1, nil)
+	tc.MockGitHubClient.EXPECT().CreateMergeRequest(gomock.Any(), forge.CreateMergeRequestParams{
+		RepoURL:       "https://github.com/test/repo",
+		SourceBranch:  branchName,
+		ModulePath:    "github.com/test/dep",
+		TargetVersion: "v1.1.0",
+	}).Return(12, nil)
+
+	// The failures of a previous run are not reported again
+	tc.DepSync.Report().add(VerificationFailure{RepoURL: "https://github.com/test/other"})
+
+	require.NoError(t, tc.DepSync.ApplyPlan(context.Background(), testPlanFile()))
+	require.Empty(t, tc.DepSync.Report().Failures())
+}
+
+func TestDepSync_ApplyPlan_Stale(t *testing.T) {
+	cfg := &config.Config{
+		Repositories: []string{"https://github.com/test/repo", "https://github.com/test/dep"},
+	}
+
+	// No expectation is set on the Dagger mock: nothing must be applied
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	expectPlanGraph(tc, "v1.0.0", "v1.2.0")
+	tc.MockGitHubClient.EXPECT().GetBranchSHA(gomock.Any(), gomock.Any()).Return("def456", nil)
+
+	err := tc.DepSync.ApplyPlan(context.Background(), testPlanFile())
+	require.ErrorIs(t, err, ErrStalePlan)
+	require.ErrorContains(t, err, "main of https://github.com/test/repo moved from abc123 to def456")
+	require.ErrorContains(t, err, "latest version of github.com/test/dep changed from v1.1.0 to v1.2.0")
+}
@@ -0,0 +1,131 @@
+//go:build unit
+// +build unit
+
+package depsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/depgraph"
+	"github.com/cryptellation/depsync/pkg/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDepSync_Run_State_SkipsPullRequestClosedByHuman(t *testing.T) {
+	cfg := &config.Config{Repositories: []string{"https://github.com/test/repo"}}
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	ctx := context.Background()
+	key := updateKey("github.com/test/repo", "github.com/test/dep", "v1.1.0")
+	require.NoError(t, tc.Store.Record(ctx, key, state.Event{Type: state.EventPROpened, PRNumber: 7}))
+
+	expectSingleServiceMismatches(tc, map[string]depgraph.Mismatch{
+		"github.com/test/dep": {Actual: "v1.0.0", Latest: "v1.1.0"},
+	})
+	tc.MockGitHubClient.EXPECT().GetPullRequestState(gomock.Any(), forge.GetPullRequestStateParams{
+		RepoURL:  "https://github.com/test/repo",
+		PRNumber: 7,
+	}).Return(forge.PullRequestStateClosed, nil)
+
+	// No clone, push nor pull request is expected
+	err := tc.DepSync.Run(ctx)
+	assert.NoError(t, err)
+
+	record, err := tc.Store.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, state.EventPRClosed, record.Status)
+
+	// The next runs do not query GitHub anymore
+	expectSingleServiceMismatches(tc, map[string]depgraph.Mismatch{
+		"github.com/test/dep": {Actual: "v1.0.0", Latest: "v1.1.0"},
+	})
+	assert.NoError(t, tc.DepSync.Run(ctx))
+}
+
+func TestDepSync_Run_State_RecordsLifecycle(t *testing.T) {
+	cfg := &config.Config{Repositories: []string{"https://github.com/test/repo"}}
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	expectSingleServiceMismatches(tc, map[string]depgraph.Mismatch{
+		"github.com/test/dep": {Actual: "v1.0.0", Latest: "v1.1.0"},
+	})
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), "https://github.com/test/repo", "main").Return(nil, nil)
+	tc.MockDagger.EXPECT().CheckBranchExists(gomock.Any(), gomock.Any()).Return(false, nil)
+	tc.MockDagger.EXPECT().UpdateGoDependency(gomock.Any(), gomock.Any()).Return(nil, nil)
+	tc.MockDagger.EXPECT().CommitAndPush(gomock.Any(), gomock.Any()).Return("", nil)
+	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(gomock.Any(), gomock.Any()).Return(-1, nil)
+	tc.MockGitHubClient.EXPECT().CreateMergeRequest(gomock.Any(), gomock.Any()).Return(8, nil)
+
+	ctx := context.Background()
+	err := tc.DepSync.Run(ctx)
+	assert.NoError(t, err)
+
+	record, err := tc.Store.Get(ctx, updateKey("github.com/test/repo", "github.com/test/dep", "v1.1.0"))
+	require.NoError(t, err)
+	assert.Equal(t, state.EventPROpened, record.Status)
+	assert.Equal(t, 8, record.PRNumber)
+	assert.Equal(t, "depsync/update-github-com-test-dep-v1.1.0", record.BranchName)
+	events := make([]state.EventType, 0, len(record.Events))
+	for _, e := range record.Events {
+		events = append(events, e.Type)
+	}
+	assert.Equal(t, []state.EventType{
+		state.EventMismatchDetected,
+		state.EventBranchPushed,
+		state.EventPROpened,
+	}, events)
+}
+
+func TestDepSync_CheckPullRequests_MergesOpenPullRequest(t *testing.T) {
+	cfg := &config.Config{Repositories: []string{"https://github.com/test/repo"}}
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	ctx := context.Background()
+	key := updateKey("github.com/test/repo", "github.com/test/dep", "v1.1.0")
+	require.NoError(t, tc.Store.Record(ctx, key, state.Event{
+		Type:       state.EventPROpened,
+		RepoURL:    "https://github.com/test/repo",
+		BranchName: "depsync/update-github-com-test-dep-v1.1.0",
+		PRNumber:   7,
+	}))
+	closedKey := updateKey("github.com/test/repo", "github.com/test/other", "v2.0.0")
+	require.NoError(t, tc.Store.Record(ctx, closedKey, state.Event{Type: state.EventPRClosed, PRNumber: 6}))
+
+	tc.MockGitHubClient.EXPECT().GetPullRequestState(gomock.Any(), forge.GetPullRequestStateParams{
+		RepoURL:  "https://github.com/test/repo",
+		PRNumber: 7,
+	}).Return(forge.PullRequestStateOpen, nil)
+	tc.MockGitHubClient.EXPECT().GetPullRequestChecks(gomock.Any(), gomock.Any()).
+		Return(&forge.CheckStatus{Status: "passed"}, nil)
+	tc.MockGitHubClient.EXPECT().MergeMergeRequest(gomock.Any(), forge.MergeMergeRequestParams{
+		RepoURL:       "https://github.com/test/repo",
+		PRNumber:      7,
+		ModulePath:    "github.com/test/dep",
+		TargetVersion: "v1.1.0",
+	}).Return(nil)
+	tc.MockGitHubClient.EXPECT().DeleteBranch(gomock.Any(), forge.DeleteBranchParams{
+		RepoURL:    "https://github.com/test/repo",
+		BranchName: "depsync/update-github-com-test-dep-v1.1.0",
+	}).Return(nil)
+
+	err := tc.DepSync.CheckPullRequests(ctx)
+	assert.NoError(t, err)
+
+	record, err := tc.Store.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, state.EventPRMerged, record.Status)
+}
@@ -0,0 +1,110 @@
+//go:build unit
+// +build unit
+
+package depsync
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/depgraph"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDepSync_Run_Tools_CreatesMergeRequest(t *testing.T) {
+	cfg := &config.Config{
+		Repositories: []string{"https://github.com/test/repo"},
+		Tools: config.ToolsConfig{
+			Enabled:  true,
+			Files:    []string{"tools.go"},
+			Versions: []config.ToolVersion{{Module: "go.uber.org/mock", Version: "v0.5.2"}},
+			Generate: true,
+		},
+		Git: config.GitConfig{
+			Author: config.GitAuthor{
+				Name:  "DepSync Bot",
+				Email: "depsync@example.com",
+			},
+		},
+	}
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	goMod := []byte(`module github.com/test/repo
+go 1.24.5
+tool go.uber.org/mock/mockgen
+require go.uber.org/mock v0.5.0
+`)
+	tc.MockFetcher.EXPECT().
+		Fetch(gomock.Any(), "https://github.com/test/repo", "main", "go.mod").
+		Return(map[string][]byte{"go.mod": goMod}, nil)
+	tc.MockFetcher.EXPECT().
+		Fetch(gomock.Any(), "https://github.com/test/repo", "main", "tools.go").
+		Return(nil, fmt.Errorf("%w: tools.go", forge.ErrFileNotFound))
+
+	svc := &depgraph.Service{
+		ModulePath:   "github.com/test/repo",
+		RepoURL:      "https://github.com/test/repo",
+		GoVersion:    "1.24.5",
+		Dependencies: map[string]depgraph.Dependency{},
+		Tools: map[string]depgraph.Tool{
+			"go.uber.org/mock": {
+				Module:   "go.uber.org/mock",
+				Version:  "v0.5.0",
+				Packages: []string{"go.uber.org/mock/mockgen"},
+				Source:   depgraph.ToolSourceDirective,
+			},
+		},
+	}
+	mockGraph := map[string]*depgraph.Service{"github.com/test/repo": svc}
+	tc.MockGraphBuilder.EXPECT().BuildGraph(map[string]depgraph.RepoModule{
+		"github.com/test/repo": {
+			RepoURL:      "https://github.com/test/repo",
+			GoModContent: goMod,
+			ToolsFiles:   map[string][]byte{},
+		},
+	}).Return(mockGraph, nil)
+	tc.MockVersionDetector.EXPECT().DetectAndSetCurrentVersions(gomock.Any(), gomock.Any(), mockGraph).Return(nil)
+	tc.MockChecker.EXPECT().Check(mockGraph).Return(map[string]map[string]depgraph.Mismatch{}, nil)
+
+	mismatches := map[string]depgraph.Mismatch{
+		"go.uber.org/mock": {Actual: "v0.5.0", Latest: "v0.5.2"},
+	}
+	tc.MockToolsChecker.EXPECT().Check(mockGraph, map[string]string{"go.uber.org/mock": "v0.5.2"}).
+		Return(map[string]map[string]depgraph.Mismatch{"github.com/test/repo": mismatches}, nil)
+
+	tools := []dagger.Module{{Path: "go.uber.org/mock", Version: "v0.5.2"}}
+	branchName := generateToolsBranchName(tools)
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), "https://github.com/test/repo", "main").Return(nil, nil)
+	tc.MockDagger.EXPECT().CheckBranchExists(gomock.Any(), gomock.Any()).Return(false, nil)
+	tc.MockDagger.EXPECT().UpdateGoTools(gomock.Any(), dagger.UpdateGoToolsParams{
+		Tools:     tools,
+		GoVersion: "1.24.5",
+		Generate:  true,
+	}).Return(nil, nil)
+	tc.MockDagger.EXPECT().CommitAndPush(gomock.Any(), dagger.CommitAndPushParams{
+		BranchName:    branchName,
+		AuthorName:    "DepSync Bot",
+		AuthorEmail:   "depsync@example.com",
+		RepoURL:       "https://github.com/test/repo",
+		CommitMessage: "chores(depsync): align tools versions",
+	}).Return(branchName, nil)
+
+	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(gomock.Any(), gomock.Any()).Return(-1, nil)
+	tc.MockGitHubClient.EXPECT().CreateMergeRequest(gomock.Any(), forge.CreateMergeRequestParams{
+		RepoURL:      "https://github.com/test/repo",
+		SourceBranch: branchName,
+		Title:        "chores(depsync): align tools versions",
+		Description:  generateToolsMRDescription(svc, mismatches, true),
+	}).Return(4, nil)
+
+	err := tc.DepSync.Run(context.Background())
+	assert.NoError(t, err)
+}
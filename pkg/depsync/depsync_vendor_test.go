@@ -0,0 +1,83 @@
+//go:build unit
+// +build unit
+
+package depsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	vendorModulesBefore = "# github.com/test/dep v1.0.0\n## explicit\ngithub.com/test/dep\n" +
+		"# github.com/test/old v0.1.0\ngithub.com/test/old\n"
+	vendorModulesAfter = "# github.com/test/dep v1.1.0\n## explicit\ngithub.com/test/dep\n" +
+		"# github.com/test/new v0.2.0\ngithub.com/test/new\n"
+	vendorSummaryDescription = "### Vendored Modules\nThe vendor directory was updated with `go mod vendor`.\n\n" +
+		"- Updated `github.com/test/dep`: `v1.0.0` → `v1.1.0`\n" +
+		"- Added `github.com/test/new` `v0.2.0`\n" +
+		"- Removed `github.com/test/old` `v0.1.0`\n"
+)
+
+func TestDepSync_Run_Vendor_FallsBackToRunner(t *testing.T) {
+	tc := newLightweightTestDepSync(t, false)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+	tc.VendorModules["main"] = []byte(vendorModulesBefore)
+	tc.VendorModules[lightweightBaseSHA] = []byte(vendorModulesBefore)
+	tc.VendorModules[lightweightBranch] = []byte(vendorModulesAfter)
+
+	// The vendor directory is updated by the runner: the go.mod file is not edited through the forge API
+	expectLightweightBase(tc, lightweightBranch)
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), lightweightRepoURL, "main").Return(nil, nil)
+	tc.MockDagger.EXPECT().CheckBranchExists(gomock.Any(), gomock.Any()).Return(false, nil)
+	tc.MockDagger.EXPECT().UpdateGoDependency(gomock.Any(), gomock.Any()).Return(nil, nil)
+	tc.MockDagger.EXPECT().CommitAndPush(gomock.Any(), gomock.Any()).Return(lightweightBranch, nil)
+
+	// The merge request description summarizes the vendor diff
+	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(gomock.Any(), gomock.Any()).Return(-1, nil)
+	tc.MockGitHubClient.EXPECT().CreateMergeRequest(gomock.Any(), forge.CreateMergeRequestParams{
+		RepoURL:       lightweightRepoURL,
+		SourceBranch:  lightweightBranch,
+		ModulePath:    "github.com/test/dep",
+		TargetVersion: "v1.1.0",
+		Description: adapters.FormatMergeRequestDescription("github.com/test/dep", "v1.1.0") +
+			"\n\n" + vendorSummaryDescription,
+	}).Return(123, nil)
+
+	assert.NoError(t, tc.DepSync.Run(context.Background()))
+}
+
+func TestDepSync_Run_Vendor_Group(t *testing.T) {
+	tc := newLightweightTestDepSync(t, true)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	branchName := generateGroupBranchName("go", []dagger.Module{{Path: "github.com/test/dep", Version: "v1.1.0"}})
+	tc.VendorModules["main"] = []byte(vendorModulesBefore)
+	tc.VendorModules[lightweightBaseSHA] = []byte(vendorModulesBefore)
+	tc.VendorModules[branchName] = []byte(vendorModulesAfter)
+
+	expectLightweightBase(tc, branchName)
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), lightweightRepoURL, "main").Return(nil, nil)
+	tc.MockDagger.EXPECT().CheckBranchExists(gomock.Any(), gomock.Any()).Return(false, nil)
+	tc.MockDagger.EXPECT().UpdateGoDependencies(gomock.Any(), gomock.Any()).Return(nil, nil)
+	tc.MockDagger.EXPECT().CommitAndPush(gomock.Any(), gomock.Any()).Return(branchName, nil)
+
+	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(gomock.Any(), gomock.Any()).Return(-1, nil)
+	tc.MockGitHubClient.EXPECT().CreateMergeRequest(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, params forge.CreateMergeRequestParams) (int, error) {
+			assert.Equal(t, branchName, params.SourceBranch)
+			assert.Contains(t, params.Description, "`github.com/test/dep` (minor): `v1.0.0` → `v1.1.0`")
+			assert.Contains(t, params.Description, "\n\n"+vendorSummaryDescription)
+			return 123, nil
+		})
+
+	assert.NoError(t, tc.DepSync.Run(context.Background()))
+}
@@ -0,0 +1,118 @@
+//go:build unit
+// +build unit
+
+package depsync
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/depgraph"
+	"github.com/cryptellation/depsync/pkg/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// newVerificationTestDepSync creates a TestDepSync with the verification enabled, and expects
+// the detection of updates of github.com/test/dep1 and github.com/test/dep2.
+func newVerificationTestDepSync(t *testing.T, grouping bool) *TestDepSync {
+	cfg := &config.Config{
+		Repositories: []string{"https://github.com/test/repo"},
+		Grouping:     config.GroupingConfig{Enabled: grouping},
+		// The lightweight updates are not verified, so not used
+		Lightweight: config.LightweightConfig{Enabled: true},
+		Verification: config.VerificationConfig{
+			Enabled:  true,
+			Commands: []string{"go build ./...", "go test ./..."},
+		},
+	}
+	tc := newTestDepSync(t, cfg)
+
+	expectSingleServiceMismatches(tc, map[string]depgraph.Mismatch{
+		"github.com/test/dep1": {Actual: "v1.0.0", Latest: "v1.1.0"},
+		"github.com/test/dep2": {Actual: "v1.0.0", Latest: "v1.2.0"},
+	})
+	return tc
+}
+
+func TestDepSync_Run_VerificationFailure_SkipsPush(t *testing.T) {
+	tc := newVerificationTestDepSync(t, false)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), "https://github.com/test/repo", "main").Return(nil, nil)
+	tc.MockDagger.EXPECT().ResetRepo(gomock.Any(), gomock.Any()).Return(nil, nil)
+	tc.MockDagger.EXPECT().CheckBranchExists(gomock.Any(), gomock.Any()).Return(false, nil).Times(2)
+
+	// The first update fails its verification: it is not pushed, and the next one goes on
+	tc.MockDagger.EXPECT().UpdateGoDependency(gomock.Any(), dagger.UpdateGoDependencyParams{
+		ModulePath:    "github.com/test/dep1",
+		TargetVersion: "v1.1.0",
+		Verify:        []string{"go build ./...", "go test ./..."},
+	}).Return(nil, &dagger.VerificationError{Command: "go build ./...", Output: "undefined: dep1.Old\n"})
+	tc.MockDagger.EXPECT().UpdateGoDependency(gomock.Any(), dagger.UpdateGoDependencyParams{
+		ModulePath:    "github.com/test/dep2",
+		TargetVersion: "v1.2.0",
+		Verify:        []string{"go build ./...", "go test ./..."},
+	}).Return(nil, nil)
+	tc.MockDagger.EXPECT().CommitAndPush(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, params dagger.CommitAndPushParams) (string, error) {
+			assert.Equal(t, "github.com/test/dep2", params.ModulePath)
+			return params.BranchName, nil
+		})
+	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(gomock.Any(), gomock.Any()).Return(-1, nil)
+	tc.MockGitHubClient.EXPECT().CreateMergeRequest(gomock.Any(), gomock.Any()).Return(123, nil)
+
+	require.NoError(t, tc.DepSync.Run(context.Background()))
+
+	// The failure is in the run report, and in the state store
+	assert.Equal(t, []VerificationFailure{{
+		RepoURL:    "https://github.com/test/repo",
+		Subject:    "github.com/test/dep1",
+		BranchName: "depsync/update-github-com-test-dep1-v1.1.0",
+		Command:    "go build ./...",
+		Output:     "undefined: dep1.Old\n",
+	}}, tc.DepSync.Report().Failures())
+	record, err := tc.Store.Get(context.Background(), updateKey("github.com/test/repo", "github.com/test/dep1", "v1.1.0"))
+	require.NoError(t, err)
+	assert.Equal(t, state.EventVerificationFailed, record.Status)
+	assert.Equal(t, "go build ./...", record.Events[len(record.Events)-1].Details)
+
+	var out bytes.Buffer
+	require.NoError(t, tc.DepSync.Report().Print(&out))
+	assert.Equal(t, "1 update(s) not pushed, as their verification failed:\n"+
+		"  1. https://github.com/test/repo github.com/test/dep1 "+
+		"branch=depsync/update-github-com-test-dep1-v1.1.0 (go build ./...)\n"+
+		"       undefined: dep1.Old\n", out.String())
+}
+
+func TestDepSync_Run_VerificationFailure_Group(t *testing.T) {
+	tc := newVerificationTestDepSync(t, true)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), "https://github.com/test/repo", "main").Return(nil, nil)
+	tc.MockDagger.EXPECT().CheckBranchExists(gomock.Any(), gomock.Any()).Return(false, nil)
+	tc.MockDagger.EXPECT().UpdateGoDependencies(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, params dagger.UpdateGoDependenciesParams) (dagger.Workspace, error) {
+			assert.Equal(t, []string{"go build ./...", "go test ./..."}, params.Verify)
+			return nil, &dagger.VerificationError{Command: "go test ./...", Output: "FAIL\n"}
+		})
+
+	// Neither pushed nor proposed
+	require.NoError(t, tc.DepSync.Run(context.Background()))
+	failures := tc.DepSync.Report().Failures()
+	require.Len(t, failures, 1)
+	assert.Equal(t, "go", failures[0].Subject)
+	assert.Equal(t, "go test ./...", failures[0].Command)
+}
+
+func TestReport_PrintEmpty(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, (&Report{}).Print(&out))
+	assert.Empty(t, out.String())
+}
@@ -0,0 +1,99 @@
+//go:build unit
+// +build unit
+
+package depsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/depgraph"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDepSync_PropagateTag_UpdatesOnlyTaggedModule(t *testing.T) {
+	cfg := &config.Config{
+		Repositories: []string{
+			"https://github.com/test/repo.git",
+			"https://github.com/test/lib.git",
+		},
+	}
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	// The module of the tagged repository is resolved at the tag
+	tc.MockFetcher.EXPECT().
+		Fetch(gomock.Any(), "https://github.com/test/repo.git", "main", "go.mod").
+		Return(map[string][]byte{"go.mod": []byte("module github.com/test/repo\n")}, nil)
+	tc.MockFetcher.EXPECT().
+		Fetch(gomock.Any(), "https://github.com/test/lib.git", "v1.1.0", "go.mod").
+		Return(map[string][]byte{"go.mod": []byte("module github.com/test/lib\n")}, nil)
+
+	mockGraph := map[string]*depgraph.Service{
+		"github.com/test/repo": {ModulePath: "github.com/test/repo", RepoURL: "https://github.com/test/repo.git"},
+		"github.com/test/lib":  {ModulePath: "github.com/test/lib", RepoURL: "https://github.com/test/lib.git"},
+	}
+	tc.MockGraphBuilder.EXPECT().
+		BuildGraph(map[string]depgraph.RepoModule{
+			"github.com/test/repo": {
+				RepoURL:      "https://github.com/test/repo.git",
+				GoModContent: []byte("module github.com/test/repo\n"),
+			},
+			"github.com/test/lib": {
+				RepoURL:      "https://github.com/test/lib.git",
+				GoModContent: []byte("module github.com/test/lib\n"),
+			},
+		}).
+		Return(mockGraph, nil)
+
+	// The versions of the other modules are not detected: only the tag is the latest version
+	tc.MockChecker.EXPECT().Check(mockGraph).DoAndReturn(
+		func(graph map[string]*depgraph.Service) (map[string]map[string]depgraph.Mismatch, error) {
+			assert.Equal(t, "v1.1.0", graph["github.com/test/lib"].LatestVersion)
+			assert.Empty(t, graph["github.com/test/repo"].LatestVersion)
+			return map[string]map[string]depgraph.Mismatch{
+				"github.com/test/repo": {"github.com/test/lib": {Actual: "v1.0.0", Latest: "v1.1.0"}},
+			}, nil
+		})
+
+	// Only the tagged module is updated
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), "https://github.com/test/repo.git", "main").Return(nil, nil)
+	tc.MockDagger.EXPECT().CheckBranchExists(gomock.Any(), gomock.Any()).Return(false, nil)
+	tc.MockDagger.EXPECT().UpdateGoDependency(gomock.Any(), dagger.UpdateGoDependencyParams{
+		ModulePath:    "github.com/test/lib",
+		TargetVersion: "v1.1.0",
+	}).Return(nil, nil)
+	tc.MockDagger.EXPECT().CommitAndPush(gomock.Any(), gomock.Any()).Return("", nil)
+	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(gomock.Any(), gomock.Any()).Return(-1, nil)
+	tc.MockGitHubClient.EXPECT().CreateMergeRequest(gomock.Any(), gomock.Any()).Return(9, nil)
+
+	err := tc.DepSync.PropagateTag(context.Background(), "https://github.com/Test/lib", "v1.1.0")
+	assert.NoError(t, err)
+}
+
+func TestDepSync_PropagateTag_IgnoresPrerelease(t *testing.T) {
+	cfg := &config.Config{Repositories: []string{"https://github.com/test/lib.git"}}
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	err := tc.DepSync.PropagateTag(context.Background(), "https://github.com/test/lib", "v1.1.0-rc.1")
+	assert.NoError(t, err)
+}
+
+func TestDepSync_PropagateTag_IgnoresUnknownRepository(t *testing.T) {
+	cfg := &config.Config{Repositories: []string{"https://github.com/test/repo.git"}}
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	err := tc.DepSync.PropagateTag(context.Background(), "https://github.com/external/lib", "v1.1.0")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,106 @@
+//go:build unit
+// +build unit
+
+package depsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/depgraph"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDepSync_Run_ReusesWorkingCopy(t *testing.T) {
+	cfg := &config.Config{Repositories: []string{"https://github.com/test/repo"}}
+
+	tc := newTestDepSync(t, cfg)
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+
+	expectSingleServiceMismatches(tc, map[string]depgraph.Mismatch{
+		"github.com/test/dep1": {Actual: "v1.0.0", Latest: "v1.1.0"},
+		"github.com/test/dep2": {Actual: "v1.0.0", Latest: "v1.2.0"},
+	})
+
+	// The repository is cloned once, and reset to main before the second update
+	clone, reset := testWorkspace("clone"), testWorkspace("reset")
+	gomock.InOrder(
+		tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), "https://github.com/test/repo", "main").Return(clone, nil),
+		tc.MockDagger.EXPECT().CheckBranchExists(gomock.Any(), gomock.Any()).Return(false, nil),
+		tc.MockDagger.EXPECT().UpdateGoDependency(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, params dagger.UpdateGoDependencyParams) (dagger.Workspace, error) {
+				assert.Equal(t, clone, params.Dir)
+				assert.Equal(t, "github.com/test/dep1", params.ModulePath)
+				return testWorkspace("updated"), nil
+			}),
+		tc.MockDagger.EXPECT().CommitAndPush(gomock.Any(), gomock.Any()).Return("", nil),
+		tc.MockDagger.EXPECT().ResetRepo(gomock.Any(), clone).Return(reset, nil),
+		tc.MockDagger.EXPECT().CheckBranchExists(gomock.Any(), gomock.Any()).Return(false, nil),
+		tc.MockDagger.EXPECT().UpdateGoDependency(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, params dagger.UpdateGoDependencyParams) (dagger.Workspace, error) {
+				assert.Equal(t, reset, params.Dir)
+				assert.Equal(t, "github.com/test/dep2", params.ModulePath)
+				return testWorkspace("updated"), nil
+			}),
+		tc.MockDagger.EXPECT().CommitAndPush(gomock.Any(), gomock.Any()).Return("", nil),
+	)
+	tc.MockGitHubClient.EXPECT().CheckPullRequestExists(gomock.Any(), gomock.Any()).Return(-1, nil).Times(2)
+	tc.MockGitHubClient.EXPECT().CreateMergeRequest(gomock.Any(), gomock.Any()).Return(123, nil).Times(2)
+
+	assert.NoError(t, tc.DepSync.Run(context.Background()))
+
+	// The working copy is removed at the end of the run, and cloned again by the next one
+	tc.DepSync.workspacesMu.Lock()
+	defer tc.DepSync.workspacesMu.Unlock()
+	assert.False(t, tc.DepSync.workspaces["https://github.com/test/repo"].cloned)
+}
+
+func TestDepSync_Workspace_ResetFailure(t *testing.T) {
+	tc := newTestDepSync(t, &config.Config{})
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+	ctx := context.Background()
+
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), "https://github.com/test/repo", "main").Return(testWorkspace("first"), nil)
+	_, release, err := tc.DepSync.workspace(ctx, "https://github.com/test/repo")
+	assert.NoError(t, err)
+	release()
+
+	// A working copy that cannot be reset is removed, and cloned again
+	tc.MockDagger.EXPECT().ResetRepo(gomock.Any(), testWorkspace("first")).Return(nil, assert.AnError)
+	_, _, err = tc.DepSync.workspace(ctx, "https://github.com/test/repo")
+	assert.ErrorIs(t, err, assert.AnError)
+
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), "https://github.com/test/repo", "main").Return(testWorkspace("second"), nil)
+	dir, release, err := tc.DepSync.workspace(ctx, "https://github.com/test/repo")
+	assert.NoError(t, err)
+	assert.Equal(t, testWorkspace("second"), dir)
+	release()
+}
+
+func TestDepSync_Workspace_PanicReleasesLock(t *testing.T) {
+	tc := newTestDepSync(t, &config.Config{})
+	defer tc.MockController.Finish()
+	defer tc.DepSync.Close()
+	ctx := context.Background()
+
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), "https://github.com/test/repo", "main").
+		DoAndReturn(func(context.Context, string, string) (dagger.Workspace, error) {
+			panic("clone panicked")
+		})
+	assert.Panics(t, func() {
+		_, _, _ = tc.DepSync.workspace(ctx, "https://github.com/test/repo")
+	})
+
+	// The working copy is not locked anymore
+	tc.MockDagger.EXPECT().CloneRepo(gomock.Any(), "https://github.com/test/repo", "main").Return(testWorkspace("first"), nil)
+	dir, release, err := tc.DepSync.workspace(ctx, "https://github.com/test/repo")
+	assert.NoError(t, err)
+	assert.Equal(t, testWorkspace("first"), dir)
+	release()
+	tc.DepSync.removeWorkspaces(ctx)
+}
@@ -0,0 +1,88 @@
+package depsync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/depgraph"
+	"github.com/cryptellation/depsync/pkg/logging"
+	"github.com/cryptellation/depsync/pkg/repo"
+	"go.uber.org/zap"
+)
+
+// buildGraphWithDiscovery builds the dependency graph of the configured repositories and
+// sets their latest versions, discovering them with batched GraphQL queries.
+func (c *DepSync) buildGraphWithDiscovery(ctx context.Context) (map[string]*depgraph.Service, error) {
+	modules, versions, err := c.discoverModules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := c.graphBuilder.BuildGraph(modules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build dependency graph: %w", err)
+	}
+
+	for path, svc := range graph {
+		if version := versions[path]; version != "" {
+			svc.LatestVersion = version
+		}
+	}
+
+	c.printDependencyGraph(ctx, graph)
+	c.printCurrentVersions(ctx, graph)
+
+	return graph, nil
+}
+
+// discoverModules returns the modules of the configured repositories, as fetchModules does,
+// and their latest versions by module path.
+func (c *DepSync) discoverModules(ctx context.Context) (map[string]depgraph.RepoModule, map[string]string, error) {
+	paths := []string{"go.mod"}
+	if c.config.Tools.Enabled {
+		paths = append(paths, c.config.Tools.Files...)
+	}
+
+	logging.C(ctx).Info("Discovering repositories", zap.Int("repository_count", len(c.config.Repositories)))
+	repositories, err := c.client.DiscoverRepositories(ctx, forge.DiscoverRepositoriesParams{
+		RepoURLs: c.config.Repositories,
+		Ref:      adapters.DefaultBranch,
+		Paths:    paths,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	modules := make(map[string]depgraph.RepoModule, len(repositories))
+	versions := make(map[string]string, len(repositories))
+	for _, r := range repositories {
+		content, ok := r.Files["go.mod"]
+		if !ok {
+			return nil, nil, fmt.Errorf("go.mod not found in repository: %s", r.RepoURL)
+		}
+		modulePath, err := parseModulePath(r.RepoURL, content)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var toolsFiles map[string][]byte
+		if c.config.Tools.Enabled {
+			toolsFiles = make(map[string][]byte)
+			for _, path := range c.config.Tools.Files {
+				if file, ok := r.Files[path]; ok {
+					toolsFiles[path] = file
+				}
+			}
+		}
+
+		modules[modulePath] = depgraph.RepoModule{
+			RepoURL:      r.RepoURL,
+			GoModContent: content,
+			ToolsFiles:   toolsFiles,
+		}
+		versions[modulePath] = repo.LatestSemverTag(r.Tags)
+	}
+	return modules, versions, nil
+}
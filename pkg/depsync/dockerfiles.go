@@ -0,0 +1,103 @@
+package depsync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/depgraph"
+	"github.com/cryptellation/depsync/pkg/docker"
+	"github.com/cryptellation/depsync/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// syncDockerfiles opens merge requests for Dockerfiles whose base images drift from their target.
+func (c *DepSync) syncDockerfiles(ctx context.Context, graph map[string]*depgraph.Service) error {
+	if !c.config.Dockerfiles.Enabled {
+		return nil
+	}
+
+	logger := logging.C(ctx)
+	targets := make([]docker.Target, 0, len(graph))
+	for _, svc := range graph {
+		targets = append(targets, docker.Target{
+			RepoURL:   svc.RepoURL,
+			GoVersion: svc.GoVersion,
+		})
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].RepoURL < targets[j].RepoURL
+	})
+
+	drifts, err := c.dockerScanner.Scan(ctx, c.config.Dockerfiles, targets)
+	if err != nil {
+		return fmt.Errorf("failed to scan dockerfiles: %w", err)
+	}
+	logger.Info("Starting Dockerfiles alignment", zap.Int("drift_count", len(drifts)))
+
+	for _, drift := range drifts {
+		for _, image := range drift.Images {
+			logger.Warn("Base image drift",
+				zap.String("repo_url", drift.RepoURL),
+				zap.String("file", image.File),
+				zap.Int("line", image.Line),
+				zap.String("actual", image.Actual),
+				zap.String("expected", image.Expected))
+		}
+
+		if err := c.applyFileChange(ctx, newDockerfilesChange(drift)); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("Dockerfiles alignment completed successfully")
+	return nil
+}
+
+// newDockerfilesChange creates the change that aligns the base images of a repository Dockerfiles.
+func newDockerfilesChange(drift docker.Drift) fileChange {
+	paths := make([]string, 0, len(drift.Files))
+	for path := range drift.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// The hash of the expected content makes the branch change whenever the target changes
+	hash := sha256.New()
+	files := make([]dagger.File, 0, len(paths))
+	for _, path := range paths {
+		files = append(files, dagger.File{Path: path, Content: drift.Files[path]})
+		hash.Write([]byte(path))
+		hash.Write(drift.Files[path])
+	}
+
+	return fileChange{
+		RepoURL:     drift.RepoURL,
+		Subject:     "dockerfiles",
+		BranchName:  "depsync/update-base-images-" + hex.EncodeToString(hash.Sum(nil))[:8],
+		Files:       files,
+		Title:       adapters.FormatDockerfilesCommitMessage(),
+		Description: generateDockerfilesMRDescription(drift),
+	}
+}
+
+// generateDockerfilesMRDescription generates the description of a Dockerfiles alignment merge request.
+func generateDockerfilesMRDescription(drift docker.Drift) string {
+	var images strings.Builder
+	for _, image := range drift.Images {
+		fmt.Fprintf(&images, "- `%s` (line %d): `%s` → `%s`\n", image.File, image.Line, image.Actual, image.Expected)
+	}
+
+	return fmt.Sprintf(`## Base Images Alignment
+
+This merge request aligns the Dockerfiles base images with their target versions.
+
+### Changes
+%s
+This update was automatically generated by DepSync.`, images.String())
+}
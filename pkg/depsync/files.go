@@ -0,0 +1,84 @@
+package depsync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/filesync"
+	"github.com/cryptellation/depsync/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// syncFiles opens merge requests for synchronized files that diverge from their source of truth.
+func (c *DepSync) syncFiles(ctx context.Context) error {
+	if len(c.config.Files) == 0 {
+		return nil
+	}
+
+	logger := logging.C(ctx)
+	drifts, err := c.driftDetector.Detect(ctx, c.config.Files)
+	if err != nil {
+		return fmt.Errorf("failed to detect file drifts: %w", err)
+	}
+	logger.Info("Starting file synchronization", zap.Int("drift_count", len(drifts)))
+
+	for _, drift := range drifts {
+		logger.Warn("Synchronized file drift",
+			zap.String("repo_url", drift.RepoURL),
+			zap.String("path", drift.Path),
+			zap.Bool("missing", drift.Missing),
+			zap.String("source_url", drift.SourceURL),
+			zap.String("source_path", drift.SourcePath))
+
+		if err := c.applyFileChange(ctx, newFileSyncChange(drift)); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("File synchronization completed successfully")
+	return nil
+}
+
+// newFileSyncChange creates the change that fixes a drifted file.
+func newFileSyncChange(drift filesync.Drift) fileChange {
+	return fileChange{
+		RepoURL:    drift.RepoURL,
+		Subject:    drift.Path,
+		BranchName: generateFileSyncBranchName(drift.Path, drift.Content),
+		Files: []dagger.File{{
+			Path:       drift.Path,
+			Content:    drift.Content,
+			Executable: drift.Executable,
+		}},
+		Title:       adapters.FormatFileSyncCommitMessage(drift.Path),
+		Description: generateFileSyncMRDescription(drift),
+	}
+}
+
+// generateFileSyncBranchName generates a branch name for a synchronized file.
+// The content hash makes it change whenever the source of truth changes.
+func generateFileSyncBranchName(path string, content []byte) string {
+	sum := sha256.Sum256(content)
+	return fmt.Sprintf("depsync/sync-%s-%s", sanitizeBranchName(path), hex.EncodeToString(sum[:])[:8])
+}
+
+// generateFileSyncMRDescription generates the description of a synchronized file merge request.
+func generateFileSyncMRDescription(drift filesync.Drift) string {
+	action := "updates"
+	if drift.Missing {
+		action = "creates"
+	}
+	return fmt.Sprintf(`## File Synchronization
+
+This merge request %s **%s** to match its source of truth.
+
+### Source
+- Repository: `+"`%s`"+`
+- Path: `+"`%s`"+`
+
+This update was automatically generated by DepSync.`, action, drift.Path, drift.SourceURL, drift.SourcePath)
+}
@@ -0,0 +1,57 @@
+package depsync
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/adapters/gitea"
+	"github.com/cryptellation/depsync/pkg/adapters/gitlab"
+	"github.com/cryptellation/depsync/pkg/adapters/local"
+	"github.com/cryptellation/depsync/pkg/config"
+)
+
+// forgeClients returns the clients of the configured forges, by host, with their tokens
+// read from the environment, and these tokens by host. The local forges have no token.
+func forgeClients(forges []config.ForgeConfig) (map[string]forge.Client, map[string]string, error) {
+	clients := make(map[string]forge.Client, len(forges))
+	tokens := make(map[string]string, len(forges))
+	for _, f := range forges {
+		if f.Host == "" {
+			return nil, nil, fmt.Errorf("forge without host")
+		}
+
+		// The local forge has no token
+		if f.Type == config.ForgeLocal {
+			client, err := local.New(local.Options{Root: f.Path, Checks: f.Checks})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to create forge %s: %w", f.Host, err)
+			}
+			clients[f.Host] = client
+			continue
+		}
+
+		token := os.Getenv(f.TokenEnv)
+		if f.TokenEnv == "" || token == "" {
+			return nil, nil, fmt.Errorf("token environment variable %q is not set for forge %s", f.TokenEnv, f.Host)
+		}
+
+		var client forge.Client
+		var err error
+		switch f.Type {
+		case config.ForgeGitLab:
+			client, err = gitlab.New(gitlab.Options{BaseURL: f.BaseURL, Token: token})
+		case config.ForgeGitea:
+			client, err = gitea.New(gitea.Options{BaseURL: f.BaseURL, Token: token})
+		default:
+			err = fmt.Errorf("unknown forge type: %s", f.Type)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create forge %s: %w", f.Host, err)
+		}
+
+		clients[f.Host] = client
+		tokens[f.Host] = token
+	}
+	return clients, tokens, nil
+}
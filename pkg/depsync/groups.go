@@ -0,0 +1,266 @@
+package depsync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/depgraph"
+	"github.com/cryptellation/depsync/pkg/logging"
+	"github.com/cryptellation/depsync/pkg/state"
+	"go.uber.org/zap"
+)
+
+// defaultGroupName is the name of the group batching every update of a service
+// when no grouping rule is configured.
+const defaultGroupName = "go"
+
+// dependencyGroup is a batch of dependency updates of a service.
+type dependencyGroup struct {
+	Name       string
+	Mismatches map[string]depgraph.Mismatch
+}
+
+// groupMismatches splits the mismatches of a service into batched groups, in the order of the
+// configured rules, and the mismatches matching no group, to update individually.
+func groupMismatches(cfg config.GroupingConfig, mismatches map[string]depgraph.Mismatch) (
+	[]dependencyGroup, map[string]depgraph.Mismatch) {
+	if len(cfg.Groups) == 0 {
+		return []dependencyGroup{{Name: defaultGroupName, Mismatches: mismatches}}, nil
+	}
+
+	batched := make(map[string]map[string]depgraph.Mismatch)
+	singles := make(map[string]depgraph.Mismatch)
+	for dep, mismatch := range mismatches {
+		group, ok := matchGroup(cfg.Groups, dep, bumpType(mismatch))
+		if !ok {
+			singles[dep] = mismatch
+			continue
+		}
+		if batched[group] == nil {
+			batched[group] = make(map[string]depgraph.Mismatch)
+		}
+		batched[group][dep] = mismatch
+	}
+
+	var groups []dependencyGroup
+	for _, g := range cfg.Groups {
+		if deps, ok := batched[g.Name]; ok {
+			groups = append(groups, dependencyGroup{Name: g.Name, Mismatches: deps})
+			delete(batched, g.Name)
+		}
+	}
+	return groups, singles
+}
+
+// matchGroup returns the name of the first group matching the dependency and bump type.
+func matchGroup(groups []config.DependencyGroup, dep, bump string) (string, bool) {
+	for _, g := range groups {
+		if matchesAny(g.Patterns, dep, matchModulePattern) && matchesAny(g.BumpTypes, bump, strings.EqualFold) {
+			return g.Name, true
+		}
+	}
+	return "", false
+}
+
+// matchesAny checks if the value matches one of the patterns, or if there is no pattern.
+func matchesAny(patterns []string, value string, match func(pattern, value string) bool) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if match(p, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchModulePattern checks if a module path matches a path.Match pattern, or a module
+// path prefix when the pattern ends with "/..." (e.g. github.com/example/...).
+func matchModulePattern(pattern, modulePath string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/..."); ok {
+		return modulePath == prefix || strings.HasPrefix(modulePath, prefix+"/")
+	}
+	matched, err := path.Match(pattern, modulePath)
+	return err == nil && matched
+}
+
+// bumpType returns the bump type (major, minor or patch) of a dependency update.
+func bumpType(mismatch depgraph.Mismatch) string {
+	actual, errActual := semver.NewVersion(mismatch.Actual)
+	latest, errLatest := semver.NewVersion(mismatch.Latest)
+	switch {
+	case errActual != nil || errLatest != nil || actual.Major() != latest.Major():
+		return config.BumpMajor
+	case actual.Minor() != latest.Minor():
+		return config.BumpMinor
+	default:
+		return config.BumpPatch
+	}
+}
+
+// updateDependencyGroups applies the batched updates of a service, when the grouping is enabled,
+// and returns the mismatches left to update individually.
+func (c *DepSync) updateDependencyGroups(ctx context.Context, repoURL, goVersion string,
+	mismatches map[string]depgraph.Mismatch) (map[string]depgraph.Mismatch, error) {
+	if !c.config.Grouping.Enabled {
+		return mismatches, nil
+	}
+
+	groups, singles := groupMismatches(c.config.Grouping, mismatches)
+	for _, group := range groups {
+		change := c.newGroupChange(repoURL, goVersion, group)
+		if err := c.applyFileChange(ctx, change); err != nil {
+			return nil, err
+		}
+		if err := c.closeSupersededGroups(ctx, change); err != nil {
+			return nil, err
+		}
+	}
+	return singles, nil
+}
+
+// closeSupersededGroups closes the pull requests of the previous branches of a group, once
+// the pull request of its current branch is open. The branch of a group changes with its
+// members and target versions, so the previous pull requests are superseded.
+func (c *DepSync) closeSupersededGroups(ctx context.Context, change fileChange) error {
+	current, err := c.store.Get(ctx, change.key())
+	if errors.Is(err, state.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get state of group %s: %w", change.Subject, err)
+	}
+	if current.Status != state.EventPROpened {
+		return nil
+	}
+
+	records, err := c.store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list states: %w", err)
+	}
+	prefix := fmt.Sprintf("depsync/update-group-%s-", sanitizeBranchName(change.Subject))
+	for _, record := range records {
+		if record.Key.Service != change.RepoURL || record.Key.Dependency != change.Subject ||
+			record.Key.Version == change.BranchName || !strings.HasPrefix(record.Key.Version, prefix) ||
+			record.Status != state.EventPROpened {
+			continue
+		}
+		if err := c.closeSupersededGroup(ctx, record, change.BranchName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// closeSupersededGroup closes the pull request of a previous branch of a group, and deletes the branch.
+func (c *DepSync) closeSupersededGroup(ctx context.Context, record state.Record, branchName string) error {
+	logger := logging.C(ctx).WithOptions(zap.Fields(
+		zap.String("repo_url", record.Key.Service),
+		zap.String("group", record.Key.Dependency),
+		zap.String("branch_name", record.BranchName),
+		zap.Int("pr_number", record.PRNumber)))
+
+	// The pull request may have been closed or merged outside of depsync since the last run
+	prState, err := c.client.GetPullRequestState(ctx, forge.GetPullRequestStateParams{
+		RepoURL:  record.Key.Service,
+		PRNumber: record.PRNumber,
+	})
+	if err != nil {
+		return err
+	}
+	switch prState {
+	case forge.PullRequestStateClosed:
+		c.recordEvent(ctx, record.Key, state.Event{Type: state.EventPRClosed, PRNumber: record.PRNumber})
+		return nil
+	case forge.PullRequestStateMerged:
+		c.recordEvent(ctx, record.Key, state.Event{Type: state.EventPRMerged, PRNumber: record.PRNumber})
+		return nil
+	}
+
+	logger.Info("Closing pull request superseded by a new group branch", zap.String("new_branch", branchName))
+	if err := c.deletePRAndBranch(ctx, record.Key.Service, record.Key.Dependency, record.Key.Service,
+		record.PRNumber, record.BranchName); err != nil {
+		return err
+	}
+	c.recordEvent(ctx, record.Key, state.Event{
+		Type:     state.EventPRDeleted,
+		PRNumber: record.PRNumber,
+		Details:  "superseded by " + branchName,
+	})
+	return nil
+}
+
+// newGroupChange creates the change that applies the batched dependency updates of a service
+// with a single "go get".
+func (c *DepSync) newGroupChange(repoURL, goVersion string, group dependencyGroup) fileChange {
+	modules := make([]dagger.Module, 0, len(group.Mismatches))
+	for dep, mismatch := range group.Mismatches {
+		modules = append(modules, dagger.Module{Path: dep, Version: mismatch.Latest})
+	}
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Path < modules[j].Path
+	})
+
+	return fileChange{
+		RepoURL:    repoURL,
+		Subject:    group.Name,
+		BranchName: generateGroupBranchName(group.Name, modules),
+		Update: func(ctx context.Context, dir dagger.Workspace) (dagger.Workspace, error) {
+			return c.dagger.UpdateGoDependencies(ctx, dagger.UpdateGoDependenciesParams{
+				Dir:       dir,
+				Modules:   modules,
+				GoVersion: goVersion,
+				Verify:    c.verifyCommands(),
+			})
+		},
+		Modules:     modules,
+		Title:       adapters.FormatGroupCommitMessage(group.Name, len(modules)),
+		Description: generateGroupMRDescription(group),
+		Vendor:      true,
+	}
+}
+
+// generateGroupBranchName generates a branch name for batched dependency updates.
+// The hash of the target versions makes it change whenever a target changes.
+func generateGroupBranchName(group string, modules []dagger.Module) string {
+	hash := sha256.New()
+	for _, module := range modules {
+		fmt.Fprintf(hash, "%s@%s\n", module.Path, module.Version)
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))[:8]
+	return fmt.Sprintf("depsync/update-group-%s-%s", sanitizeBranchName(group), sum)
+}
+
+// generateGroupMRDescription generates the description of a batched dependency updates merge request.
+func generateGroupMRDescription(group dependencyGroup) string {
+	deps := make([]string, 0, len(group.Mismatches))
+	for dep := range group.Mismatches {
+		deps = append(deps, dep)
+	}
+	sort.Strings(deps)
+
+	var changes strings.Builder
+	for _, dep := range deps {
+		m := group.Mismatches[dep]
+		fmt.Fprintf(&changes, "- `%s` (%s): `%s` → `%s`\n", dep, bumpType(m), m.Actual, m.Latest)
+	}
+
+	return fmt.Sprintf(`## Dependencies Update
+
+This merge request updates the **%s** dependencies to their latest versions.
+
+### Changes
+%s
+This update was automatically generated by DepSync.`, group.Name, changes.String())
+}
@@ -0,0 +1,134 @@
+package depsync
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/gomod"
+	"github.com/cryptellation/depsync/pkg/logging"
+	"github.com/cryptellation/depsync/pkg/state"
+	"go.uber.org/zap"
+	"golang.org/x/mod/module"
+)
+
+// newUpdater returns the updater of the lightweight updates, or nil when they are disabled.
+func newUpdater(cfg config.LightweightConfig) (gomod.Updater, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	return gomod.NewUpdater(gomod.Options{
+		Proxy:   cfg.Proxy,
+		SumDB:   cfg.SumDB,
+		Private: cfg.Private,
+	})
+}
+
+// lightweightUpdateParams contains parameters for lightweightUpdate.
+type lightweightUpdateParams struct {
+	RepoURL    string
+	BranchName string
+	Message    string
+	Modules    []dagger.Module
+	// Key is the state key of the update, recording the pushed branch.
+	Key state.Key
+}
+
+// lightweightUpdate applies dependency updates by editing the go.mod and go.sum files of the
+// repository and committing them on a new branch through the forge API, without cloning it.
+// It returns false when the updates must be applied by the runner instead: when the lightweight
+// updates are disabled, when the updates are not plain version bumps, when the repository
+// commits its vendor directory, or when the updates must be verified in a working copy.
+func (c *DepSync) lightweightUpdate(ctx context.Context, params lightweightUpdateParams) (bool, error) {
+	if c.updater == nil || c.config.Verification.Enabled {
+		return false, nil
+	}
+	logger := logging.C(ctx).WithOptions(zap.Fields(
+		zap.String("repo_url", params.RepoURL),
+		zap.String("branch_name", params.BranchName)))
+
+	exists, err := c.client.BranchExists(ctx, forge.BranchExistsParams{
+		RepoURL:    params.RepoURL,
+		BranchName: params.BranchName,
+	})
+	if err != nil {
+		logger.Error("Failed to check branch existence", zap.Error(err))
+		return false, err
+	}
+	if exists {
+		logger.Warn("Branch already exists, skipping update")
+		return true, nil
+	}
+
+	// The files are read at the head of main, which is the parent of the commit, so that the
+	// commit does not revert the changes pushed on main in the meantime
+	baseSHA, err := c.client.GetBranchSHA(ctx, forge.GetBranchSHAParams{
+		RepoURL:    params.RepoURL,
+		BranchName: adapters.DefaultBranch,
+	})
+	if err != nil {
+		logger.Error("Failed to get head of main", zap.Error(err))
+		return false, err
+	}
+
+	// The vendor directory must be updated with the go command
+	vendorModules, err := c.fetchVendorModules(ctx, params.RepoURL, baseSHA)
+	if err != nil {
+		logger.Error("Failed to detect vendor directory", zap.Error(err))
+		return false, err
+	} else if vendorModules != nil {
+		logger.Info("Repository with vendor directory, falling back to the runner")
+		return false, nil
+	}
+
+	files, err := c.fetcher.Fetch(ctx, params.RepoURL, baseSHA, "go.mod", "go.sum")
+	if errors.Is(err, forge.ErrFileNotFound) {
+		logger.Info("Repository without go.sum file, falling back to the runner")
+		return false, nil
+	} else if err != nil {
+		logger.Error("Failed to fetch go.mod and go.sum", zap.Error(err))
+		return false, err
+	}
+
+	modules := make([]module.Version, 0, len(params.Modules))
+	for _, m := range params.Modules {
+		modules = append(modules, module.Version{Path: m.Path, Version: m.Version})
+	}
+	result, err := c.updater.Update(ctx, gomod.UpdateParams{
+		GoMod:   files["go.mod"],
+		GoSum:   files["go.sum"],
+		Modules: modules,
+	})
+	if errors.Is(err, gomod.ErrTidyRequired) {
+		logger.Info("Update requires go mod tidy, falling back to the runner", zap.Error(err))
+		return false, nil
+	} else if err != nil {
+		logger.Warn("Failed to prepare lightweight update, falling back to the runner", zap.Error(err))
+		return false, nil
+	}
+
+	_, err = c.client.CreateCommit(ctx, forge.CreateCommitParams{
+		RepoURL:    params.RepoURL,
+		BaseBranch: adapters.DefaultBranch,
+		BaseSHA:    baseSHA,
+		BranchName: params.BranchName,
+		Message:    params.Message,
+		Files: []forge.CommitFile{
+			{Path: "go.mod", Content: result.GoMod},
+			{Path: "go.sum", Content: result.GoSum},
+		},
+		AuthorName:  c.config.Git.Author.Name,
+		AuthorEmail: c.config.Git.Author.Email,
+	})
+	if err != nil {
+		logger.Error("Failed to commit changes", zap.Error(err))
+		return false, err
+	}
+
+	logger.Info("Successfully committed changes through the forge API")
+	c.recordEvent(ctx, params.Key, state.Event{Type: state.EventBranchPushed, BranchName: params.BranchName})
+	return true, nil
+}
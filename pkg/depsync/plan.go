@@ -0,0 +1,246 @@
+package depsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/depgraph"
+	"github.com/cryptellation/depsync/pkg/logging"
+	"github.com/cryptellation/depsync/pkg/plan"
+	"go.uber.org/zap"
+)
+
+// ErrStalePlan is returned when applying a plan whose preconditions no longer hold.
+var ErrStalePlan = errors.New("stale plan")
+
+// CreatePlan detects the dependency updates to make and returns them as a plan, with
+// their preconditions and the actions they lead to. It requires the dry-run mode.
+func (c *DepSync) CreatePlan(ctx context.Context) (*plan.File, error) {
+	if c.plan == nil {
+		return nil, errors.New("creating a plan requires the dry-run mode")
+	}
+	if err := c.checkPlannable(); err != nil {
+		return nil, err
+	}
+
+	graph, err := c.buildGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mismatches, err := c.checker.Check(graph)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for inconsistencies: %w", err)
+	}
+
+	operations, err := c.planOperations(ctx, graph, mismatches)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record the actions the operations lead to
+	if len(mismatches) > 0 {
+		if err := c.fixModules(ctx, graph, mismatches); err != nil {
+			return nil, fmt.Errorf("failed to fix modules: %w", err)
+		}
+	}
+
+	return &plan.File{
+		Version:    plan.FileVersion,
+		CreatedAt:  time.Now().UTC(),
+		Graph:      snapshotGraph(graph),
+		Operations: operations,
+		Actions:    c.plan.Actions(),
+	}, nil
+}
+
+// ApplyPlan applies the operations of a plan, after checking that their preconditions
+// still hold. Otherwise, nothing is applied and ErrStalePlan is returned.
+func (c *DepSync) ApplyPlan(ctx context.Context, file *plan.File) error {
+	if err := c.checkPlannable(); err != nil {
+		return err
+	}
+	defer c.removeWorkspaces(ctx)
+	c.report.reset()
+
+	graph, err := c.buildGraph(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.checkPreconditions(ctx, graph, file.Operations); err != nil {
+		return err
+	}
+
+	mismatches := make(map[string]map[string]depgraph.Mismatch)
+	for _, op := range file.Operations {
+		if mismatches[op.Service] == nil {
+			mismatches[op.Service] = make(map[string]depgraph.Mismatch)
+		}
+		mismatches[op.Service][op.Dependency] = depgraph.Mismatch{Actual: op.From, Latest: op.To}
+	}
+	if len(mismatches) == 0 {
+		logging.C(ctx).Info("Nothing to apply")
+		return nil
+	}
+
+	logging.C(ctx).Info("Applying plan", zap.Int("operation_count", len(file.Operations)))
+	if err := c.fixModules(ctx, graph, mismatches); err != nil {
+		return fmt.Errorf("failed to fix modules: %w", err)
+	}
+	return nil
+}
+
+// checkPlannable returns an error if the configuration enables synchronizations that
+// plans do not cover, as they would be silently skipped between the plan and its application.
+func (c *DepSync) checkPlannable() error {
+	var unplanned []string
+	if c.config.Tools.Enabled {
+		unplanned = append(unplanned, "tools")
+	}
+	if c.config.Dockerfiles.Enabled {
+		unplanned = append(unplanned, "dockerfiles")
+	}
+	if len(c.config.Files) > 0 {
+		unplanned = append(unplanned, "files")
+	}
+	if c.config.Actions.Enabled {
+		unplanned = append(unplanned, "actions")
+	}
+	if len(unplanned) > 0 {
+		return fmt.Errorf("plans only cover the Go dependency updates, disable the synchronization of: %s",
+			strings.Join(unplanned, ", "))
+	}
+	return nil
+}
+
+// planOperations returns the operations fixing the mismatches, sorted by service and dependency.
+func (c *DepSync) planOperations(ctx context.Context, graph map[string]*depgraph.Service,
+	mismatches map[string]map[string]depgraph.Mismatch) ([]plan.Operation, error) {
+	shas := make(map[string]string)
+	var operations []plan.Operation
+	for _, svc := range sortedKeys(mismatches) {
+		repoURL := graph[svc].RepoURL
+		sha, err := c.mainSHA(ctx, repoURL, shas)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, dep := range sortedKeys(mismatches[svc]) {
+			mismatch := mismatches[svc][dep]
+			operations = append(operations, plan.Operation{
+				Service:    svc,
+				RepoURL:    repoURL,
+				Dependency: dep,
+				From:       mismatch.Actual,
+				To:         mismatch.Latest,
+				Preconditions: plan.Preconditions{
+					MainSHA:       sha,
+					TargetVersion: mismatch.Latest,
+				},
+			})
+		}
+	}
+	return operations, nil
+}
+
+// checkPreconditions returns ErrStalePlan with every violated precondition of the operations, if any.
+func (c *DepSync) checkPreconditions(ctx context.Context, graph map[string]*depgraph.Service,
+	operations []plan.Operation) error {
+	shas := make(map[string]string)
+	var violations []string
+	for _, op := range operations {
+		svc, ok := graph[op.Service]
+		if !ok {
+			violations = append(violations, fmt.Sprintf("service %s is no longer in the graph", op.Service))
+			continue
+		}
+
+		sha, err := c.mainSHA(ctx, op.RepoURL, shas)
+		if err != nil {
+			return err
+		}
+		if sha != op.Preconditions.MainSHA {
+			violations = append(violations, fmt.Sprintf("main of %s moved from %s to %s",
+				op.RepoURL, op.Preconditions.MainSHA, sha))
+		}
+
+		if d, ok := svc.Dependencies[op.Dependency]; !ok || d.CurrentVersion != op.From {
+			violations = append(violations, fmt.Sprintf("%s no longer requires %s@%s",
+				op.Service, op.Dependency, op.From))
+		}
+		if d, ok := graph[op.Dependency]; ok && d.LatestVersion != op.Preconditions.TargetVersion {
+			violations = append(violations, fmt.Sprintf("latest version of %s changed from %s to %s",
+				op.Dependency, op.Preconditions.TargetVersion, d.LatestVersion))
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("%w: %s", ErrStalePlan, strings.Join(uniqueStrings(violations), "; "))
+	}
+	return nil
+}
+
+// mainSHA returns the head commit of the main branch of a repository, caching the results.
+func (c *DepSync) mainSHA(ctx context.Context, repoURL string, shas map[string]string) (string, error) {
+	if sha, ok := shas[repoURL]; ok {
+		return sha, nil
+	}
+	sha, err := c.client.GetBranchSHA(ctx, forge.GetBranchSHAParams{
+		RepoURL:    repoURL,
+		BranchName: adapters.DefaultBranch,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get main branch of %s: %w", repoURL, err)
+	}
+	shas[repoURL] = sha
+	return sha, nil
+}
+
+// snapshotGraph returns the snapshot of the dependency graph, sorted by module path.
+func snapshotGraph(graph map[string]*depgraph.Service) []plan.ServiceSnapshot {
+	snapshot := make([]plan.ServiceSnapshot, 0, len(graph))
+	for _, path := range sortedKeys(graph) {
+		svc := graph[path]
+		deps := make(map[string]string, len(svc.Dependencies))
+		for dep, d := range svc.Dependencies {
+			deps[dep] = d.CurrentVersion
+		}
+		snapshot = append(snapshot, plan.ServiceSnapshot{
+			ModulePath:    svc.ModulePath,
+			RepoURL:       svc.RepoURL,
+			LatestVersion: svc.LatestVersion,
+			Dependencies:  deps,
+		})
+	}
+	return snapshot
+}
+
+// sortedKeys returns the keys of a map, sorted.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// uniqueStrings removes the duplicates of a list, keeping the order.
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	res := values[:0]
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			res = append(res, v)
+		}
+	}
+	return res
+}
@@ -0,0 +1,93 @@
+package depsync
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/logging"
+	"github.com/cryptellation/depsync/pkg/state"
+	"go.uber.org/zap"
+)
+
+// followUpPullRequest follows up on an open pull request of an update: it deletes it if
+// it has conflicts, or merges it if its checks passed.
+func (c *DepSync) followUpPullRequest(ctx context.Context, key state.Key,
+	targetVersion, repoURL string, prNumber int, branchName string) error {
+	deleted, err := c.handlePRConflicts(ctx, key.Service, key.Dependency, repoURL, prNumber, branchName)
+	if err != nil {
+		logging.C(ctx).Error("Failed to handle PR conflicts", zap.Error(err))
+		return err
+	} else if deleted {
+		// Skip checkAndMergeMR if deletion was performed
+		c.recordEvent(ctx, key, state.Event{Type: state.EventPRDeleted, BranchName: branchName, PRNumber: prNumber})
+		return nil
+	}
+
+	// Check and merge MR if checks pass
+	if c.checkAndMergeMR(ctx, key.Service, key.Dependency, targetVersion, repoURL, prNumber, branchName) {
+		c.recordEvent(ctx, key, state.Event{Type: state.EventPRMerged, BranchName: branchName, PRNumber: prNumber})
+	}
+	return nil
+}
+
+// CheckPullRequests follows up on the open pull requests recorded in the state store, without
+// rescanning the repositories: it detects the ones closed or merged outside of depsync, deletes
+// the conflicted ones and merges the ones whose checks passed.
+func (c *DepSync) CheckPullRequests(ctx context.Context) error {
+	return c.checkPullRequests(ctx, func(state.Record) bool { return true })
+}
+
+// CheckBranchPullRequests follows up on the open pull requests recorded in the state store
+// for the given repository branch, e.g. when their checks completed.
+func (c *DepSync) CheckBranchPullRequests(ctx context.Context, repoURL, branch string) error {
+	return c.checkPullRequests(ctx, func(r state.Record) bool {
+		return r.BranchName == branch && sameRepository(r.RepoURL, repoURL)
+	})
+}
+
+// checkPullRequests follows up on the open pull requests recorded in the state store that match the filter.
+func (c *DepSync) checkPullRequests(ctx context.Context, filter func(state.Record) bool) error {
+	records, err := c.store.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	logger := logging.C(ctx)
+	var errs []error
+	for _, record := range records {
+		if record.Status != state.EventPROpened || record.RepoURL == "" || !filter(record) {
+			continue
+		}
+
+		logger.Info("Checking pull request",
+			zap.String("service", record.Key.Service),
+			zap.String("dependency", record.Key.Dependency),
+			zap.String("version", record.Key.Version),
+			zap.Int("pr_number", record.PRNumber))
+
+		skip, err := c.skipUpdate(ctx, record.Key, record.RepoURL)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		} else if skip {
+			continue
+		}
+
+		err = c.followUpPullRequest(ctx, record.Key, record.Key.Version, record.RepoURL, record.PRNumber, record.BranchName)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sameRepository checks if two GitHub repository URLs designate the same repository of the
+// same host, regardless of the case and of the ".git" suffix.
+func sameRepository(a, b string) bool {
+	hostA, ownerA, nameA := adapters.ParseRepository(a)
+	hostB, ownerB, nameB := adapters.ParseRepository(b)
+	return hostA != "" && hostA == hostB &&
+		strings.EqualFold(ownerA, ownerB) && strings.EqualFold(nameA, nameB)
+}
@@ -0,0 +1,72 @@
+package depsync
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// VerificationFailure is an update whose verification failed: its branch was not pushed.
+type VerificationFailure struct {
+	RepoURL string
+	// Subject is the updated dependency, or the subject of the change (e.g. the group name).
+	Subject    string
+	BranchName string
+	Command    string
+	Output     string
+}
+
+// Report is the report of a run, with the updates that were not pushed.
+type Report struct {
+	mu       sync.Mutex
+	failures []VerificationFailure
+}
+
+// add appends a verification failure to the report.
+func (r *Report) add(failure VerificationFailure) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failures = append(r.failures, failure)
+}
+
+// reset empties the report, at the start of a run.
+func (r *Report) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failures = nil
+}
+
+// Failures returns the verification failures of the report.
+func (r *Report) Failures() []VerificationFailure {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]VerificationFailure(nil), r.failures...)
+}
+
+// Print writes the report in a human readable format, nothing when there is no failure.
+func (r *Report) Print(w io.Writer) error {
+	failures := r.Failures()
+	if len(failures) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(w, "%d update(s) not pushed, as their verification failed:\n", len(failures)); err != nil {
+		return err
+	}
+	for i, f := range failures {
+		_, err := fmt.Fprintf(w, "%3d. %s %s branch=%s (%s)\n", i+1, f.RepoURL, f.Subject, f.BranchName, f.Command)
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(strings.TrimRight(f.Output, "\n"), "\n") {
+			if _, err := fmt.Fprintf(w, "       %s\n", line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
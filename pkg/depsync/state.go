@@ -0,0 +1,94 @@
+package depsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/logging"
+	"github.com/cryptellation/depsync/pkg/state"
+	"go.uber.org/zap"
+)
+
+// updateKey returns the state key of a dependency update.
+func updateKey(service, dep, version string) state.Key {
+	return state.Key{
+		Service:    service,
+		Dependency: dep,
+		Version:    version,
+	}
+}
+
+// recordEvent records an event of an update lifecycle. Failures are only logged,
+// as GitHub remains the source of truth of the updates.
+func (c *DepSync) recordEvent(ctx context.Context, key state.Key, event state.Event) {
+	if err := c.store.Record(ctx, key, event); err != nil {
+		logging.C(ctx).Error("Failed to record state event",
+			zap.String("service", key.Service),
+			zap.String("dependency", key.Dependency),
+			zap.String("version", key.Version),
+			zap.String("event", string(event.Type)),
+			zap.Error(err))
+	}
+}
+
+// skipUpdate checks the recorded lifecycle of an update and returns true if depsync
+// should leave it alone: when its pull request has been closed without being merged
+// (e.g. by a human) or has been merged.
+func (c *DepSync) skipUpdate(ctx context.Context, key state.Key, repoURL string) (bool, error) {
+	record, err := c.store.Get(ctx, key)
+	if errors.Is(err, state.ErrNotFound) {
+		c.recordEvent(ctx, key, state.Event{Type: state.EventMismatchDetected})
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to get state of %s@%s for %s: %w", key.Dependency, key.Version, key.Service, err)
+	}
+
+	logger := logging.C(ctx).WithOptions(zap.Fields(
+		zap.String("service", key.Service),
+		zap.String("dependency", key.Dependency),
+		zap.String("version", key.Version),
+		zap.Int("pr_number", record.PRNumber)))
+
+	switch record.Status {
+	case state.EventPRClosed, state.EventPRMerged:
+		logger.Info("Pull request already closed or merged, skipping update", zap.String("status", string(record.Status)))
+		return true, nil
+	case state.EventPROpened:
+		// Detect pull requests closed or merged outside of depsync since the last run
+		prState, err := c.client.GetPullRequestState(ctx, forge.GetPullRequestStateParams{
+			RepoURL:  repoURL,
+			PRNumber: record.PRNumber,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		switch prState {
+		case forge.PullRequestStateClosed:
+			logger.Warn("Pull request closed without merge, skipping update")
+			c.recordEvent(ctx, key, state.Event{Type: state.EventPRClosed, PRNumber: record.PRNumber})
+			return true, nil
+		case forge.PullRequestStateMerged:
+			logger.Info("Pull request already merged, skipping update")
+			c.recordEvent(ctx, key, state.Event{Type: state.EventPRMerged, PRNumber: record.PRNumber})
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// recordPullRequest records the pull request of an update, unless it is already recorded.
+func (c *DepSync) recordPullRequest(ctx context.Context, key state.Key, repoURL, branchName string, prNumber int) {
+	record, err := c.store.Get(ctx, key)
+	if err == nil && record.Status == state.EventPROpened && record.PRNumber == prNumber {
+		return
+	}
+	c.recordEvent(ctx, key, state.Event{
+		Type:       state.EventPROpened,
+		RepoURL:    repoURL,
+		BranchName: branchName,
+		PRNumber:   prNumber,
+	})
+}
@@ -0,0 +1,149 @@
+package depsync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/depgraph"
+	"github.com/cryptellation/depsync/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// fetchToolsFiles fetches the tools files of a repository, when the tools alignment is enabled.
+// Missing files are ignored.
+func (c *DepSync) fetchToolsFiles(ctx context.Context, repoURL string) (map[string][]byte, error) {
+	if !c.config.Tools.Enabled {
+		return nil, nil
+	}
+
+	files := make(map[string][]byte)
+	for _, path := range c.config.Tools.Files {
+		results, err := c.fetcher.Fetch(ctx, repoURL, adapters.DefaultBranch, path)
+		if errors.Is(err, forge.ErrFileNotFound) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("error fetching %s for %s: %w", path, repoURL, err)
+		}
+		files[path] = results[path]
+	}
+	return files, nil
+}
+
+// syncTools opens merge requests aligning the tools versions of the services.
+func (c *DepSync) syncTools(ctx context.Context, graph map[string]*depgraph.Service) error {
+	if !c.config.Tools.Enabled {
+		return nil
+	}
+
+	versions := make(map[string]string, len(c.config.Tools.Versions))
+	for _, v := range c.config.Tools.Versions {
+		versions[v.Module] = v.Version
+	}
+
+	mismatches, err := c.toolsChecker.Check(graph, versions)
+	if err != nil {
+		return fmt.Errorf("failed to check tools versions: %w", err)
+	}
+
+	logger := logging.C(ctx)
+	logger.Info("Starting tools alignment", zap.Int("service_count", len(mismatches)))
+
+	services := make([]string, 0, len(mismatches))
+	for service := range mismatches {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	for _, service := range services {
+		for tool, mismatch := range mismatches[service] {
+			logger.Warn("Tool version mismatch",
+				zap.String("service", service),
+				zap.String("tool", tool),
+				zap.String("actual", mismatch.Actual),
+				zap.String("target", mismatch.Latest))
+		}
+
+		svc := graph[service]
+		if err := c.applyFileChange(ctx, c.newToolsChange(svc, mismatches[service])); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("Tools alignment completed successfully")
+	return nil
+}
+
+// newToolsChange creates the change that aligns the tools of a service.
+func (c *DepSync) newToolsChange(svc *depgraph.Service, mismatches map[string]depgraph.Mismatch) fileChange {
+	tools := make([]dagger.Module, 0, len(mismatches))
+	for tool, mismatch := range mismatches {
+		tools = append(tools, dagger.Module{Path: tool, Version: mismatch.Latest})
+	}
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].Path < tools[j].Path
+	})
+
+	return fileChange{
+		RepoURL:    svc.RepoURL,
+		Subject:    "tools",
+		BranchName: generateToolsBranchName(tools),
+		Update: func(ctx context.Context, dir dagger.Workspace) (dagger.Workspace, error) {
+			return c.dagger.UpdateGoTools(ctx, dagger.UpdateGoToolsParams{
+				Dir:       dir,
+				Tools:     tools,
+				GoVersion: svc.GoVersion,
+				Generate:  c.config.Tools.Generate,
+				Verify:    c.verifyCommands(),
+			})
+		},
+		Title:       adapters.FormatToolsCommitMessage(),
+		Description: generateToolsMRDescription(svc, mismatches, c.config.Tools.Generate),
+		Vendor:      true,
+	}
+}
+
+// generateToolsBranchName generates a branch name for a tools alignment.
+// The hash of the target versions makes it change whenever a target changes.
+func generateToolsBranchName(tools []dagger.Module) string {
+	hash := sha256.New()
+	for _, tool := range tools {
+		fmt.Fprintf(hash, "%s@%s\n", tool.Path, tool.Version)
+	}
+	return "depsync/update-tools-" + hex.EncodeToString(hash.Sum(nil))[:8]
+}
+
+// generateToolsMRDescription generates the description of a tools alignment merge request.
+func generateToolsMRDescription(svc *depgraph.Service, mismatches map[string]depgraph.Mismatch, generate bool) string {
+	names := make([]string, 0, len(mismatches))
+	for tool := range mismatches {
+		names = append(names, tool)
+	}
+	sort.Strings(names)
+
+	var changes strings.Builder
+	for _, tool := range names {
+		fmt.Fprintf(&changes, "- `%s` (%s): `%s` → `%s`\n",
+			tool, svc.Tools[tool].Source, mismatches[tool].Actual, mismatches[tool].Latest)
+	}
+
+	generated := ""
+	if generate {
+		generated = "\nThe generated code has been updated with `go generate ./...`.\n"
+	}
+
+	return fmt.Sprintf(`## Tools Alignment
+
+This merge request aligns the tools versions with the other repositories.
+
+### Changes
+%s%s
+This update was automatically generated by DepSync.`, changes.String(), generated)
+}
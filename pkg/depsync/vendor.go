@@ -0,0 +1,85 @@
+package depsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/gomod"
+	"github.com/cryptellation/depsync/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// fetchVendorModules returns the vendor/modules.txt file of the repository at the given ref,
+// or nil when the repository does not commit its vendor directory.
+func (c *DepSync) fetchVendorModules(ctx context.Context, repoURL, ref string) ([]byte, error) {
+	files, err := c.fetcher.Fetch(ctx, repoURL, ref, gomod.VendorModulesFile)
+	if errors.Is(err, forge.ErrFileNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", gomod.VendorModulesFile, err)
+	}
+	return files[gomod.VendorModulesFile], nil
+}
+
+// vendorSummary returns the summary of the changes of the vendored modules between main and
+// the branch, for the merge request description. It returns an empty summary when the
+// repository does not commit its vendor directory, or when the files cannot be fetched.
+func (c *DepSync) vendorSummary(ctx context.Context, repoURL, branchName string) string {
+	// The branches are not pushed in dry-run mode
+	if c.plan != nil {
+		return ""
+	}
+	logger := logging.C(ctx).WithOptions(zap.Fields(
+		zap.String("repo_url", repoURL),
+		zap.String("branch_name", branchName)))
+
+	before, err := c.fetchVendorModules(ctx, repoURL, adapters.DefaultBranch)
+	if err != nil {
+		logger.Warn("Failed to fetch vendored modules, skipping vendor summary", zap.Error(err))
+		return ""
+	} else if before == nil {
+		return ""
+	}
+	after, err := c.fetchVendorModules(ctx, repoURL, branchName)
+	if err != nil || after == nil {
+		logger.Warn("Failed to fetch vendored modules of the branch, skipping vendor summary", zap.Error(err))
+		return ""
+	}
+
+	return formatVendorSummary(gomod.DiffVendorModules(before, after))
+}
+
+// formatVendorSummary formats the changes of the vendored modules as a merge request description section.
+func formatVendorSummary(diff gomod.VendorDiff) string {
+	var changes strings.Builder
+	for _, m := range diff.Updated {
+		fmt.Fprintf(&changes, "- Updated `%s`: `%s` → `%s`\n", m.Path, m.From, m.To)
+	}
+	for _, m := range diff.Added {
+		fmt.Fprintf(&changes, "- Added `%s` `%s`\n", m.Path, m.To)
+	}
+	for _, m := range diff.Removed {
+		fmt.Fprintf(&changes, "- Removed `%s` `%s`\n", m.Path, m.From)
+	}
+	if diff.Empty() {
+		changes.WriteString("- No vendored module changed\n")
+	}
+
+	return fmt.Sprintf(`### Vendored Modules
+The vendor directory was updated with `+"`go mod vendor`"+`.
+
+%s`, changes.String())
+}
+
+// withVendorSummary appends the summary of the vendored modules changes to a merge request
+// description, when there is one.
+func withVendorSummary(description, summary string) string {
+	if summary == "" {
+		return description
+	}
+	return description + "\n\n" + summary
+}
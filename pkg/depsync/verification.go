@@ -0,0 +1,52 @@
+package depsync
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/logging"
+	"github.com/cryptellation/depsync/pkg/state"
+	"go.uber.org/zap"
+)
+
+// errVerificationFailed is returned when the verification of an update fails: its branch is
+// not pushed, and the next updates go on.
+var errVerificationFailed = errors.New("verification failed")
+
+// verifyCommands returns the commands verifying the updates, none when disabled.
+func (c *DepSync) verifyCommands() []string {
+	if !c.config.Verification.Enabled {
+		return nil
+	}
+	return c.config.Verification.Commands
+}
+
+// checkVerification reports the update whose verification failed in the run report and the
+// state store, and returns errVerificationFailed. The other errors are returned unchanged.
+func (c *DepSync) checkVerification(ctx context.Context, key state.Key, repoURL, branchName string, err error) error {
+	var verifyErr *dagger.VerificationError
+	if !errors.As(err, &verifyErr) {
+		return err
+	}
+
+	logging.C(ctx).Warn("Update verification failed, skipping push",
+		zap.String("repo_url", repoURL),
+		zap.String("subject", key.Dependency),
+		zap.String("branch_name", branchName),
+		zap.String("command", verifyErr.Command),
+		zap.String("output", verifyErr.Output))
+	c.report.add(VerificationFailure{
+		RepoURL:    repoURL,
+		Subject:    key.Dependency,
+		BranchName: branchName,
+		Command:    verifyErr.Command,
+		Output:     verifyErr.Output,
+	})
+	c.recordEvent(ctx, key, state.Event{
+		Type:       state.EventVerificationFailed,
+		BranchName: branchName,
+		Details:    verifyErr.Command,
+	})
+	return errVerificationFailed
+}
@@ -0,0 +1,91 @@
+package depsync
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// repoWorkspace is the working copy of a repository, shared by its updates during a run.
+type repoWorkspace struct {
+	// mu serializes the updates of the repository, and the removal of the working copy.
+	mu     sync.Mutex
+	dir    dagger.Workspace
+	cloned bool
+}
+
+// workspace returns the working copy of the repository on its main branch: it is cloned for
+// the first update of the run, and reset for the next ones, which reuse its module downloads.
+// The release function must be called once the update is pushed or skipped.
+func (c *DepSync) workspace(ctx context.Context, repoURL string) (dagger.Workspace, func(), error) {
+	c.workspacesMu.Lock()
+	if c.workspaces == nil {
+		c.workspaces = make(map[string]*repoWorkspace)
+	}
+	ws, ok := c.workspaces[repoURL]
+	if !ok {
+		ws = &repoWorkspace{}
+		c.workspaces[repoURL] = ws
+	}
+	c.workspacesMu.Unlock()
+
+	// The lock is released on every exit path but a successful one, including a panic or a
+	// runtime.Goexit in the clone or the reset, for removeWorkspaces not to block forever
+	ws.mu.Lock()
+	handedOut := false
+	defer func() {
+		if !handedOut {
+			ws.mu.Unlock()
+		}
+	}()
+
+	if !ws.cloned {
+		dir, err := c.dagger.CloneRepo(ctx, repoURL, adapters.DefaultBranch)
+		if err != nil {
+			return nil, nil, err
+		}
+		ws.dir, ws.cloned = dir, true
+	} else {
+		dir, err := c.dagger.ResetRepo(ctx, ws.dir)
+		if err != nil {
+			// The working copy is cloned again by the next update
+			c.removeWorkspace(ctx, repoURL, ws)
+			return nil, nil, err
+		}
+		ws.dir = dir
+	}
+
+	handedOut = true
+	return ws.dir, ws.mu.Unlock, nil
+}
+
+// removeWorkspaces removes the working copies of the run.
+func (c *DepSync) removeWorkspaces(ctx context.Context) {
+	c.workspacesMu.Lock()
+	workspaces := make(map[string]*repoWorkspace, len(c.workspaces))
+	for repoURL, ws := range c.workspaces {
+		workspaces[repoURL] = ws
+	}
+	c.workspacesMu.Unlock()
+
+	for repoURL, ws := range workspaces {
+		ws.mu.Lock()
+		c.removeWorkspace(ctx, repoURL, ws)
+		ws.mu.Unlock()
+	}
+}
+
+// removeWorkspace removes a working copy, whose lock is held.
+func (c *DepSync) removeWorkspace(ctx context.Context, repoURL string, ws *repoWorkspace) {
+	if !ws.cloned {
+		return
+	}
+	if err := c.dagger.RemoveRepo(ctx, ws.dir); err != nil {
+		logging.C(ctx).Warn("Failed to remove working copy", zap.String("repo_url", repoURL), zap.Error(err))
+	}
+	ws.dir, ws.cloned = nil, false
+}
@@ -0,0 +1,105 @@
+package docker
+
+import (
+	"regexp"
+	"strings"
+)
+
+// fromRE matches the FROM instructions of a Dockerfile, with optional flags (e.g. --platform).
+var fromRE = regexp.MustCompile(`(?i)^(\s*FROM\s+)((?:--\S+\s+)*)(\S+)(.*)$`)
+
+// stageRE matches the stage name of a FROM instruction (e.g. "AS build").
+var stageRE = regexp.MustCompile(`(?i)^\s+AS\s+(\S+)`)
+
+// BaseImage is an image referenced by a FROM instruction of a Dockerfile.
+type BaseImage struct {
+	File string
+	Line int
+	// Name is the image name as written in the Dockerfile (e.g. golang, gcr.io/distroless/static-debian12).
+	Name   string
+	Tag    string
+	Digest string
+}
+
+// Ref returns the image reference as written in a Dockerfile.
+func (i BaseImage) Ref() string {
+	return formatRef(i.Name, i.Tag, i.Digest)
+}
+
+// ParseDockerfile returns the base images of a Dockerfile. References to previous
+// stages, to "scratch" and to build arguments are ignored.
+func ParseDockerfile(file string, content []byte) []BaseImage {
+	var images []BaseImage
+	stages := make(map[string]bool)
+	for i, line := range strings.Split(string(content), "\n") {
+		m := fromRE.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		ref := m[3]
+		skip := strings.Contains(ref, "$") || strings.EqualFold(ref, "scratch") || stages[strings.ToLower(ref)]
+		if s := stageRE.FindStringSubmatch(m[4]); s != nil {
+			stages[strings.ToLower(s[1])] = true
+		}
+		if skip {
+			continue
+		}
+
+		name, tag, digest := parseRef(ref)
+		images = append(images, BaseImage{
+			File:   file,
+			Line:   i + 1,
+			Name:   name,
+			Tag:    tag,
+			Digest: digest,
+		})
+	}
+	return images
+}
+
+// RewriteDockerfile replaces the image reference of the FROM instructions on the
+// given lines (1-based) with the given references.
+func RewriteDockerfile(content []byte, refs map[int]string) []byte {
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		ref, ok := refs[i+1]
+		if !ok {
+			continue
+		}
+		if m := fromRE.FindStringSubmatch(line); m != nil {
+			lines[i] = m[1] + m[2] + ref + m[4]
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// parseRef splits an image reference into its name, tag and digest.
+func parseRef(ref string) (name, tag, digest string) {
+	name, digest, _ = strings.Cut(ref, "@")
+	// The tag separator is the last colon after the last slash (the registry may have a port)
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+	return name, tag, digest
+}
+
+// formatRef formats an image reference from its name, tag and digest.
+func formatRef(name, tag, digest string) string {
+	ref := name
+	if tag != "" {
+		ref += ":" + tag
+	}
+	if digest != "" {
+		ref += "@" + digest
+	}
+	return ref
+}
+
+// normalizeName removes the Docker Hub default registry and namespace from an image name.
+func normalizeName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.TrimPrefix(name, "docker.io/")
+	name = strings.TrimPrefix(name, "index.docker.io/")
+	return strings.TrimPrefix(name, "library/")
+}
@@ -0,0 +1,57 @@
+//go:build unit
+// +build unit
+
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testDockerfile = `ARG BASE=alpine
+FROM --platform=$BUILDPLATFORM golang:1.23-alpine AS build
+RUN go build -o /app ./cmd/app
+
+FROM build AS test
+RUN go test ./...
+
+FROM docker.io/library/golang:1.23.4-bookworm@sha256:abc AS tools
+FROM ${BASE}
+FROM scratch
+FROM gcr.io/distroless/static-debian12:latest
+COPY --from=build /app /app
+`
+
+func TestParseDockerfile(t *testing.T) {
+	images := ParseDockerfile("Dockerfile", []byte(testDockerfile))
+	require.Equal(t, []BaseImage{
+		{File: "Dockerfile", Line: 2, Name: "golang", Tag: "1.23-alpine"},
+		{File: "Dockerfile", Line: 8, Name: "docker.io/library/golang", Tag: "1.23.4-bookworm", Digest: "sha256:abc"},
+		{File: "Dockerfile", Line: 11, Name: "gcr.io/distroless/static-debian12", Tag: "latest"},
+	}, images)
+}
+
+func TestParseRef(t *testing.T) {
+	name, tag, digest := parseRef("registry.example.com:5000/team/app:1.0@sha256:abc")
+	require.Equal(t, "registry.example.com:5000/team/app", name)
+	require.Equal(t, "1.0", tag)
+	require.Equal(t, "sha256:abc", digest)
+
+	name, tag, digest = parseRef("registry.example.com:5000/team/app")
+	require.Equal(t, "registry.example.com:5000/team/app", name)
+	require.Empty(t, tag)
+	require.Empty(t, digest)
+}
+
+func TestRewriteDockerfile(t *testing.T) {
+	out := RewriteDockerfile([]byte(testDockerfile), map[int]string{
+		2: "golang:1.24-alpine",
+		8: "docker.io/library/golang:1.24.5-bookworm",
+	})
+
+	images := ParseDockerfile("Dockerfile", out)
+	require.Equal(t, "golang:1.24-alpine", images[0].Ref())
+	require.Equal(t, "docker.io/library/golang:1.24.5-bookworm", images[1].Ref())
+	require.Contains(t, string(out), "FROM --platform=$BUILDPLATFORM golang:1.24-alpine AS build\n")
+}
@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: scanner.go
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_scanner.gen.go -package=docker -source=scanner.go Scanner
+//
+
+// Package docker is a generated GoMock package.
+package docker
+
+import (
+	context "context"
+	reflect "reflect"
+
+	config "github.com/cryptellation/depsync/pkg/config"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDigestResolver is a mock of DigestResolver interface.
+type MockDigestResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockDigestResolverMockRecorder
+	isgomock struct{}
+}
+
+// MockDigestResolverMockRecorder is the mock recorder for MockDigestResolver.
+type MockDigestResolverMockRecorder struct {
+	mock *MockDigestResolver
+}
+
+// NewMockDigestResolver creates a new mock instance.
+func NewMockDigestResolver(ctrl *gomock.Controller) *MockDigestResolver {
+	mock := &MockDigestResolver{ctrl: ctrl}
+	mock.recorder = &MockDigestResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDigestResolver) EXPECT() *MockDigestResolverMockRecorder {
+	return m.recorder
+}
+
+// ResolveImageDigest mocks base method.
+func (m *MockDigestResolver) ResolveImageDigest(ctx context.Context, image string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveImageDigest", ctx, image)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveImageDigest indicates an expected call of ResolveImageDigest.
+func (mr *MockDigestResolverMockRecorder) ResolveImageDigest(ctx, image any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveImageDigest", reflect.TypeOf((*MockDigestResolver)(nil).ResolveImageDigest), ctx, image)
+}
+
+// MockScanner is a mock of Scanner interface.
+type MockScanner struct {
+	ctrl     *gomock.Controller
+	recorder *MockScannerMockRecorder
+	isgomock struct{}
+}
+
+// MockScannerMockRecorder is the mock recorder for MockScanner.
+type MockScannerMockRecorder struct {
+	mock *MockScanner
+}
+
+// NewMockScanner creates a new mock instance.
+func NewMockScanner(ctrl *gomock.Controller) *MockScanner {
+	mock := &MockScanner{ctrl: ctrl}
+	mock.recorder = &MockScannerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScanner) EXPECT() *MockScannerMockRecorder {
+	return m.recorder
+}
+
+// Scan mocks base method.
+func (m *MockScanner) Scan(ctx context.Context, cfg config.DockerfilesConfig, targets []Target) ([]Drift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Scan", ctx, cfg, targets)
+	ret0, _ := ret[0].([]Drift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Scan indicates an expected call of Scan.
+func (mr *MockScannerMockRecorder) Scan(ctx, cfg, targets any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*MockScanner)(nil).Scan), ctx, cfg, targets)
+}
@@ -0,0 +1,204 @@
+//go:generate go run go.uber.org/mock/mockgen@v0.5.2 -destination=mock_scanner.gen.go -package=docker -source=scanner.go Scanner
+
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/repo"
+)
+
+// golangImage is the normalized name of the official Go image.
+const golangImage = "golang"
+
+// golangTagRE matches the versioned tags of the Go image (e.g. 1.24, 1.24.5-alpine).
+var golangTagRE = regexp.MustCompile(`^(\d+\.\d+(?:\.\d+)?)(-.+)?$`)
+
+// Target is a repository whose Dockerfiles should be aligned.
+type Target struct {
+	RepoURL string
+	// GoVersion is the Go version required by the repository go.mod.
+	GoVersion string
+}
+
+// ImageDrift is a base image that differs from its expected reference.
+type ImageDrift struct {
+	File     string
+	Line     int
+	Actual   string
+	Expected string
+}
+
+// Drift represents the Dockerfiles of a repository whose base images need to be updated.
+type Drift struct {
+	RepoURL string
+	Images  []ImageDrift
+	// Files contains the updated content of the Dockerfiles, by path.
+	Files map[string][]byte
+}
+
+// DigestResolver resolves the digest of image references.
+type DigestResolver interface {
+	ResolveImageDigest(ctx context.Context, image string) (string, error)
+}
+
+// Scanner scans the Dockerfiles of repositories for base images that drift from their target.
+type Scanner interface {
+	// Scan returns the drifts of the targets Dockerfiles, sorted by repository URL.
+	Scan(ctx context.Context, cfg config.DockerfilesConfig, targets []Target) ([]Drift, error)
+}
+
+type scanner struct {
+	fetcher  repo.FilesFetcher
+	resolver DigestResolver
+}
+
+// NewScanner creates a new Scanner.
+func NewScanner(fetcher repo.FilesFetcher, resolver DigestResolver) Scanner {
+	return &scanner{
+		fetcher:  fetcher,
+		resolver: resolver,
+	}
+}
+
+// Scan implements the Scanner interface.
+func (s *scanner) Scan(ctx context.Context, cfg config.DockerfilesConfig, targets []Target) ([]Drift, error) {
+	digests := make(map[string]string)
+	var drifts []Drift
+	for _, target := range targets {
+		drift := Drift{
+			RepoURL: target.RepoURL,
+			Files:   make(map[string][]byte),
+		}
+
+		for _, path := range cfg.Paths {
+			results, err := s.fetcher.Fetch(ctx, target.RepoURL, adapters.DefaultBranch, path)
+			if errors.Is(err, forge.ErrFileNotFound) {
+				continue
+			} else if err != nil {
+				return nil, fmt.Errorf("error fetching %s from %s: %w", path, target.RepoURL, err)
+			}
+
+			images, content, err := s.scanDockerfile(ctx, cfg, target, path, results[path], digests)
+			if err != nil {
+				return nil, err
+			}
+			if len(images) > 0 {
+				drift.Images = append(drift.Images, images...)
+				drift.Files[path] = content
+			}
+		}
+
+		if len(drift.Images) > 0 {
+			drifts = append(drifts, drift)
+		}
+	}
+
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].RepoURL < drifts[j].RepoURL
+	})
+	return drifts, nil
+}
+
+// scanDockerfile returns the drifted images of a Dockerfile and its updated content.
+func (s *scanner) scanDockerfile(
+	ctx context.Context,
+	cfg config.DockerfilesConfig,
+	target Target,
+	path string,
+	content []byte,
+	digests map[string]string,
+) ([]ImageDrift, []byte, error) {
+	var drifts []ImageDrift
+	refs := make(map[int]string)
+	for _, image := range ParseDockerfile(path, content) {
+		tag, ok := expectedTag(cfg, target, image)
+		if !ok {
+			continue
+		}
+
+		expected := BaseImage{Name: image.Name, Tag: tag}
+		switch {
+		case cfg.PinDigests:
+			digest, err := s.resolveDigest(ctx, expected.Ref(), digests)
+			if err != nil {
+				return nil, nil, err
+			}
+			expected.Digest = digest
+		case tag == image.Tag:
+			// Keep the existing digest, if any, when the tag is already aligned
+			expected.Digest = image.Digest
+		}
+
+		if expected.Ref() == image.Ref() {
+			continue
+		}
+		drifts = append(drifts, ImageDrift{
+			File:     path,
+			Line:     image.Line,
+			Actual:   image.Ref(),
+			Expected: expected.Ref(),
+		})
+		refs[image.Line] = expected.Ref()
+	}
+
+	return drifts, RewriteDockerfile(content, refs), nil
+}
+
+// resolveDigest resolves the digest of an image reference, caching the results.
+func (s *scanner) resolveDigest(ctx context.Context, ref string, digests map[string]string) (string, error) {
+	if digest, ok := digests[ref]; ok {
+		return digest, nil
+	}
+	digest, err := s.resolver.ResolveImageDigest(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	digests[ref] = digest
+	return digest, nil
+}
+
+// expectedTag returns the tag the image should use, if the image is aligned by depsync.
+func expectedTag(cfg config.DockerfilesConfig, target Target, image BaseImage) (string, bool) {
+	name := normalizeName(image.Name)
+	if name == golangImage {
+		return expectedGolangTag(cfg, target, image.Tag)
+	}
+
+	for _, i := range cfg.Images {
+		if normalizeName(i.Name) == name {
+			return i.Tag, i.Tag != ""
+		}
+	}
+	return "", false
+}
+
+// expectedGolangTag returns the tag of the Go image for the target Go version,
+// keeping the precision and the variant (e.g. -alpine) of the current tag.
+func expectedGolangTag(cfg config.DockerfilesConfig, target Target, tag string) (string, bool) {
+	version := cfg.GoVersion
+	if version == "" {
+		version = target.GoVersion
+	}
+	m := golangTagRE.FindStringSubmatch(tag)
+	if version == "" || m == nil {
+		return "", false
+	}
+
+	// Keep only major and minor if the current tag does not specify the patch
+	if strings.Count(m[1], ".") == 1 {
+		parts := strings.SplitN(version, ".", 3)
+		if len(parts) >= 2 {
+			version = parts[0] + "." + parts[1]
+		}
+	}
+	return version + m[2], true
+}
@@ -0,0 +1,88 @@
+//go:build unit
+// +build unit
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/repo"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestScan_GoVersionFromGoMod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockFetcher := repo.NewMockFilesFetcher(ctrl)
+	mockResolver := NewMockDigestResolver(ctrl)
+	scanner := NewScanner(mockFetcher, mockResolver)
+
+	cfg := config.DockerfilesConfig{
+		Paths:  []string{"Dockerfile", "build/Dockerfile"},
+		Images: []config.DockerImage{{Name: "gcr.io/distroless/static-debian12", Tag: "nonroot"}},
+	}
+	targets := []Target{
+		{RepoURL: "https://github.com/example/b", GoVersion: "1.24.5"},
+		{RepoURL: "https://github.com/example/a", GoVersion: "1.24.5"},
+	}
+
+	mockFetcher.EXPECT().Fetch(gomock.Any(), "https://github.com/example/a", "main", "Dockerfile").
+		Return(map[string][]byte{"Dockerfile": []byte(testDockerfile)}, nil)
+	mockFetcher.EXPECT().Fetch(gomock.Any(), "https://github.com/example/a", "main", "build/Dockerfile").
+		Return(nil, fmt.Errorf("%w: build/Dockerfile", forge.ErrFileNotFound))
+	mockFetcher.EXPECT().Fetch(gomock.Any(), "https://github.com/example/b", "main", "Dockerfile").
+		Return(map[string][]byte{"Dockerfile": []byte("FROM golang:1.24-alpine\n")}, nil)
+	mockFetcher.EXPECT().Fetch(gomock.Any(), "https://github.com/example/b", "main", "build/Dockerfile").
+		Return(nil, fmt.Errorf("%w: build/Dockerfile", forge.ErrFileNotFound))
+
+	drifts, err := scanner.Scan(context.Background(), cfg, targets)
+	require.NoError(t, err)
+	require.Len(t, drifts, 1)
+	require.Equal(t, "https://github.com/example/a", drifts[0].RepoURL)
+	require.Equal(t, []ImageDrift{
+		{File: "Dockerfile", Line: 2, Actual: "golang:1.23-alpine", Expected: "golang:1.24-alpine"},
+		{File: "Dockerfile", Line: 8,
+			Actual:   "docker.io/library/golang:1.23.4-bookworm@sha256:abc",
+			Expected: "docker.io/library/golang:1.24.5-bookworm"},
+		{File: "Dockerfile", Line: 11,
+			Actual:   "gcr.io/distroless/static-debian12:latest",
+			Expected: "gcr.io/distroless/static-debian12:nonroot"},
+	}, drifts[0].Images)
+	require.Contains(t, string(drifts[0].Files["Dockerfile"]), "FROM gcr.io/distroless/static-debian12:nonroot\n")
+}
+
+func TestScan_PinDigests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockFetcher := repo.NewMockFilesFetcher(ctrl)
+	mockResolver := NewMockDigestResolver(ctrl)
+	scanner := NewScanner(mockFetcher, mockResolver)
+
+	cfg := config.DockerfilesConfig{
+		Paths:      []string{"Dockerfile"},
+		GoVersion:  "1.25",
+		PinDigests: true,
+	}
+	targets := []Target{
+		{RepoURL: "https://github.com/example/a", GoVersion: "1.24.5"},
+		{RepoURL: "https://github.com/example/b", GoVersion: "1.24.5"},
+	}
+
+	mockFetcher.EXPECT().Fetch(gomock.Any(), "https://github.com/example/a", "main", "Dockerfile").
+		Return(map[string][]byte{"Dockerfile": []byte("FROM golang:1.24-alpine AS build\n")}, nil)
+	mockFetcher.EXPECT().Fetch(gomock.Any(), "https://github.com/example/b", "main", "Dockerfile").
+		Return(map[string][]byte{"Dockerfile": []byte("FROM golang:1.25-alpine@sha256:new\n")}, nil)
+	mockResolver.EXPECT().ResolveImageDigest(gomock.Any(), "golang:1.25-alpine").Return("sha256:new", nil).Times(1)
+
+	drifts, err := scanner.Scan(context.Background(), cfg, targets)
+	require.NoError(t, err)
+	require.Len(t, drifts, 1)
+	require.Equal(t, "FROM golang:1.25-alpine@sha256:new AS build\n", string(drifts[0].Files["Dockerfile"]))
+}
@@ -0,0 +1,142 @@
+//go:generate go run go.uber.org/mock/mockgen@v0.5.2 -destination=mock_detector.gen.go -package=filesync -source=detector.go DriftDetector
+
+package filesync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"text/template"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/repo"
+)
+
+// Drift represents a target file whose content differs from its source of truth.
+type Drift struct {
+	RepoURL    string
+	Path       string
+	SourceURL  string
+	SourcePath string
+	// Content is the expected content of the target file.
+	Content    []byte
+	Executable bool
+	// Missing is true when the target file does not exist yet.
+	Missing bool
+}
+
+// DriftDetector detects synchronized files that diverge from their source of truth.
+type DriftDetector interface {
+	// Detect returns the drifted targets, sorted by repository and path.
+	Detect(ctx context.Context, files []config.FileSync) ([]Drift, error)
+}
+
+type driftDetector struct {
+	fetcher repo.FilesFetcher
+}
+
+// NewDriftDetector creates a new DriftDetector using the given fetcher.
+func NewDriftDetector(fetcher repo.FilesFetcher) DriftDetector {
+	return &driftDetector{fetcher: fetcher}
+}
+
+// Detect implements the DriftDetector interface.
+func (d *driftDetector) Detect(ctx context.Context, files []config.FileSync) ([]Drift, error) {
+	var drifts []Drift
+	for _, file := range files {
+		source, err := d.fetchFile(ctx, file.Source.Repository, file.Source.Ref, file.Source.Path)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching source %s from %s: %w",
+				file.Source.Path, file.Source.Repository, err)
+		}
+
+		for _, target := range file.Targets {
+			drift, err := d.detectTarget(ctx, file, source, target)
+			if err != nil {
+				return nil, err
+			}
+			if drift != nil {
+				drifts = append(drifts, *drift)
+			}
+		}
+	}
+
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].RepoURL != drifts[j].RepoURL {
+			return drifts[i].RepoURL < drifts[j].RepoURL
+		}
+		return drifts[i].Path < drifts[j].Path
+	})
+	return drifts, nil
+}
+
+// detectTarget compares a single target with the rendered source and returns a drift if they differ.
+func (d *driftDetector) detectTarget(
+	ctx context.Context,
+	file config.FileSync,
+	source []byte,
+	target config.FileTarget,
+) (*Drift, error) {
+	expected, err := Render(target.Path, source, file.Source.Delims, target.Variables)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering %s for %s: %w", file.Source.Path, target.Repository, err)
+	}
+
+	actual, err := d.fetchFile(ctx, target.Repository, adapters.DefaultBranch, target.Path)
+	missing := errors.Is(err, forge.ErrFileNotFound)
+	if err != nil && !missing {
+		return nil, fmt.Errorf("error fetching %s from %s: %w", target.Path, target.Repository, err)
+	}
+	if !missing && bytes.Equal(actual, expected) {
+		return nil, nil
+	}
+
+	return &Drift{
+		RepoURL:    target.Repository,
+		Path:       target.Path,
+		SourceURL:  file.Source.Repository,
+		SourcePath: file.Source.Path,
+		Content:    expected,
+		Executable: file.Executable,
+		Missing:    missing,
+	}, nil
+}
+
+// fetchFile fetches a single file from a repository.
+func (d *driftDetector) fetchFile(ctx context.Context, repoURL, ref, path string) ([]byte, error) {
+	results, err := d.fetcher.Fetch(ctx, repoURL, ref, path)
+	if err != nil {
+		return nil, err
+	}
+	return results[path], nil
+}
+
+// Render renders the source content as a Go template with the given variables.
+// The content is returned as is when there is no variable.
+func Render(name string, content []byte, delims []string, variables map[string]string) ([]byte, error) {
+	if len(variables) == 0 {
+		return content, nil
+	}
+
+	tmpl := template.New(name).Option("missingkey=error")
+	if len(delims) == 2 {
+		tmpl = tmpl.Delims(delims[0], delims[1])
+	} else if len(delims) != 0 {
+		return nil, fmt.Errorf("invalid template delimiters: %v", delims)
+	}
+
+	tmpl, err := tmpl.Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, variables); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,105 @@
+//go:build unit
+// +build unit
+
+package filesync
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/repo"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDetect_DriftedAndMissingTargets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockFetcher := repo.NewMockFilesFetcher(ctrl)
+	detector := NewDriftDetector(mockFetcher)
+
+	files := []config.FileSync{{
+		Source: config.FileSource{
+			Repository: "https://github.com/example/templates",
+			Path:       "lint.yml",
+			Ref:        "main",
+		},
+		Targets: []config.FileTarget{
+			{Repository: "https://github.com/example/a", Path: ".golangci.yml"},
+			{Repository: "https://github.com/example/b", Path: ".golangci.yml"},
+			{Repository: "https://github.com/example/c", Path: ".golangci.yml"},
+		},
+	}}
+
+	mockFetcher.EXPECT().Fetch(gomock.Any(), "https://github.com/example/templates", "main", "lint.yml").
+		Return(map[string][]byte{"lint.yml": []byte("linters: {}\n")}, nil)
+	mockFetcher.EXPECT().Fetch(gomock.Any(), "https://github.com/example/a", "main", ".golangci.yml").
+		Return(map[string][]byte{".golangci.yml": []byte("linters: {}\n")}, nil)
+	mockFetcher.EXPECT().Fetch(gomock.Any(), "https://github.com/example/b", "main", ".golangci.yml").
+		Return(map[string][]byte{".golangci.yml": []byte("linters: old\n")}, nil)
+	mockFetcher.EXPECT().Fetch(gomock.Any(), "https://github.com/example/c", "main", ".golangci.yml").
+		Return(nil, fmt.Errorf("%w: .golangci.yml", forge.ErrFileNotFound))
+
+	drifts, err := detector.Detect(context.Background(), files)
+	require.NoError(t, err)
+	require.Equal(t, []Drift{
+		{
+			RepoURL:    "https://github.com/example/b",
+			Path:       ".golangci.yml",
+			SourceURL:  "https://github.com/example/templates",
+			SourcePath: "lint.yml",
+			Content:    []byte("linters: {}\n"),
+		},
+		{
+			RepoURL:    "https://github.com/example/c",
+			Path:       ".golangci.yml",
+			SourceURL:  "https://github.com/example/templates",
+			SourcePath: "lint.yml",
+			Content:    []byte("linters: {}\n"),
+			Missing:    true,
+		},
+	}, drifts)
+}
+
+func TestDetect_SourceError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockFetcher := repo.NewMockFilesFetcher(ctrl)
+	detector := NewDriftDetector(mockFetcher)
+
+	files := []config.FileSync{{
+		Source:  config.FileSource{Repository: "https://github.com/example/templates", Path: "lint.yml", Ref: "main"},
+		Targets: []config.FileTarget{{Repository: "https://github.com/example/a", Path: ".golangci.yml"}},
+	}}
+
+	mockFetcher.EXPECT().Fetch(gomock.Any(), "https://github.com/example/templates", "main", "lint.yml").
+		Return(nil, fmt.Errorf("API error"))
+
+	_, err := detector.Detect(context.Background(), files)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "error fetching source")
+}
+
+func TestRender(t *testing.T) {
+	content := []byte("module: {{ .module }}\non: ${{ github.event }}\n")
+
+	// Without variables, the content is copied verbatim
+	out, err := Render("file", content, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, content, out)
+
+	// With custom delimiters, "{{" sequences are preserved
+	out, err = Render("file", []byte("module: [[ .module ]]\non: ${{ github.event }}\n"),
+		[]string{"[[", "]]"}, map[string]string{"module": "github.com/example/a"})
+	require.NoError(t, err)
+	require.Equal(t, "module: github.com/example/a\non: ${{ github.event }}\n", string(out))
+
+	// Missing variables are reported
+	_, err = Render("file", []byte("{{ .unknown }}"), nil, map[string]string{"module": "a"})
+	require.Error(t, err)
+}
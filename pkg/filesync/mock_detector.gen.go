@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: detector.go
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_detector.gen.go -package=filesync -source=detector.go DriftDetector
+//
+
+// Package filesync is a generated GoMock package.
+package filesync
+
+import (
+	context "context"
+	reflect "reflect"
+
+	config "github.com/cryptellation/depsync/pkg/config"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDriftDetector is a mock of DriftDetector interface.
+type MockDriftDetector struct {
+	ctrl     *gomock.Controller
+	recorder *MockDriftDetectorMockRecorder
+	isgomock struct{}
+}
+
+// MockDriftDetectorMockRecorder is the mock recorder for MockDriftDetector.
+type MockDriftDetectorMockRecorder struct {
+	mock *MockDriftDetector
+}
+
+// NewMockDriftDetector creates a new mock instance.
+func NewMockDriftDetector(ctrl *gomock.Controller) *MockDriftDetector {
+	mock := &MockDriftDetector{ctrl: ctrl}
+	mock.recorder = &MockDriftDetectorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDriftDetector) EXPECT() *MockDriftDetectorMockRecorder {
+	return m.recorder
+}
+
+// Detect mocks base method.
+func (m *MockDriftDetector) Detect(ctx context.Context, files []config.FileSync) ([]Drift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Detect", ctx, files)
+	ret0, _ := ret[0].([]Drift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Detect indicates an expected call of Detect.
+func (mr *MockDriftDetectorMockRecorder) Detect(ctx, files any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Detect", reflect.TypeOf((*MockDriftDetector)(nil).Detect), ctx, files)
+}
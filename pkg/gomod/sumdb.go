@@ -0,0 +1,144 @@
+package gomod
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/note"
+)
+
+// knownSumDBs are the verifier keys of the known checksum databases, by name.
+var knownSumDBs = map[string]string{
+	"sum.golang.org": "sum.golang.org+033de0ae+Ac4zctda0e5eza+HJyk9SxEdh+s3Ux18htTTAD8OuAn8",
+}
+
+// sumDBTimeout is the timeout of the requests to the checksum database.
+const sumDBTimeout = 30 * time.Second
+
+// sumDB looks up the checksums of the modules in a checksum database, verifying its
+// signed tree and the inclusion of the records.
+type sumDB struct {
+	client *sumdb.Client
+}
+
+// newSumDB creates a checksum database client from a GOSUMDB value: a known database
+// name, or a verifier key followed by an optional URL (default: https://<name>).
+func newSumDB(gosumdb string, httpClient *http.Client) (*sumDB, error) {
+	fields := strings.Fields(gosumdb)
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, fmt.Errorf("invalid checksum database: %q", gosumdb)
+	}
+	key := fields[0]
+	if known, ok := knownSumDBs[key]; ok {
+		key = known
+	}
+	verifier, err := note.NewVerifier(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checksum database key %q: %w", key, err)
+	}
+	url := "https://" + verifier.Name()
+	if len(fields) == 2 {
+		url = fields[1]
+	}
+
+	ops := &sumDBOps{
+		key:   key,
+		url:   strings.TrimSuffix(url, "/"),
+		http:  httpClient,
+		cache: make(map[string][]byte),
+	}
+	return &sumDB{client: sumdb.NewClient(ops)}, nil
+}
+
+// lookup returns the go.sum lines of a module version and of its go.mod file.
+func (db *sumDB) lookup(path, version string) ([]string, error) {
+	zipLines, err := db.client.Lookup(path, version)
+	if err != nil {
+		return nil, err
+	}
+	modLines, err := db.client.Lookup(path, version+"/go.mod")
+	if err != nil {
+		return nil, err
+	}
+	return append(zipLines, modLines...), nil
+}
+
+// sumDBOps implements the operations of the checksum database client, in memory.
+type sumDBOps struct {
+	key  string
+	url  string
+	http *http.Client
+
+	mu sync.Mutex
+	// latest is the latest signed tree of the database.
+	latest []byte
+	// cache contains the records and tiles read from the database.
+	cache map[string][]byte
+}
+
+// ReadRemote reads a path of the database.
+func (o *sumDBOps) ReadRemote(path string) ([]byte, error) {
+	client := *o.http
+	client.Timeout = sumDBTimeout
+	resp, err := client.Get(o.url + path)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to read %s%s: %s", o.url, path, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// ReadConfig returns the verifier key and the latest signed tree.
+func (o *sumDBOps) ReadConfig(file string) ([]byte, error) {
+	if file == "key" {
+		return []byte(o.key), nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.latest, nil
+}
+
+// WriteConfig updates the latest signed tree.
+func (o *sumDBOps) WriteConfig(_ string, old, new []byte) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !bytes.Equal(o.latest, old) {
+		return sumdb.ErrWriteConflict
+	}
+	o.latest = new
+	return nil
+}
+
+// ReadCache reads a record or tile from the cache.
+func (o *sumDBOps) ReadCache(file string) ([]byte, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	data, ok := o.cache[file]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return data, nil
+}
+
+// WriteCache writes a record or tile to the cache.
+func (o *sumDBOps) WriteCache(file string, data []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.cache[file] = data
+}
+
+// Log ignores the messages of the client.
+func (o *sumDBOps) Log(string) {}
+
+// SecurityError ignores the security errors, which are returned by the lookups.
+func (o *sumDBOps) SecurityError(string) {}
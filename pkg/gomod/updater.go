@@ -0,0 +1,350 @@
+//go:generate go run go.uber.org/mock/mockgen@v0.5.2 -destination=mock_updater.gen.go -package=gomod -source=updater.go Updater
+
+package gomod
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// Default values of the Options.
+const (
+	DefaultProxy = "https://proxy.golang.org"
+	DefaultSumDB = "sum.golang.org"
+	// SumDBOff disables the checksum database: the checksums are computed from the proxy.
+	SumDBOff = "off"
+)
+
+// ErrTidyRequired is returned when the update would change more than the required versions
+// of the updated modules, such as the versions of other modules or the go directive, and
+// must be applied with "go get" and "go mod tidy".
+var ErrTidyRequired = errors.New("go mod tidy required")
+
+// UpdateParams contains parameters for Update.
+type UpdateParams struct {
+	GoMod []byte
+	GoSum []byte
+	// Modules are the modules to update, with their target version.
+	Modules []module.Version
+}
+
+// UpdateResult contains the updated go.mod and go.sum files.
+type UpdateResult struct {
+	GoMod []byte
+	GoSum []byte
+}
+
+// Updater updates the required versions of modules in go.mod and go.sum files, without
+// the go command.
+type Updater interface {
+	Update(ctx context.Context, params UpdateParams) (UpdateResult, error)
+}
+
+// Options contains the options of the updater.
+type Options struct {
+	// Proxy is the URL of the Go module proxy (default: DefaultProxy).
+	Proxy string
+	// SumDB is the checksum database, as the GOSUMDB variable: a known database name, a
+	// verifier key with an optional URL, or SumDBOff (default: DefaultSumDB).
+	SumDB string
+	// Private are the module path patterns of the private modules, as the GOPRIVATE
+	// variable. Their updates require the go command.
+	Private []string
+	// HTTPClient is the client of the proxy and the checksum database (default: http.DefaultClient).
+	HTTPClient *http.Client
+}
+
+// updater implements the Updater interface with a Go module proxy and a checksum database.
+type updater struct {
+	proxy   string
+	private string
+	http    *http.Client
+	// sumdb is the checksum database client, nil when disabled.
+	sumdb *sumDB
+}
+
+// NewUpdater creates an updater with the given options.
+func NewUpdater(opts Options) (Updater, error) {
+	if opts.Proxy == "" {
+		opts.Proxy = DefaultProxy
+	}
+	if opts.SumDB == "" {
+		opts.SumDB = DefaultSumDB
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+
+	u := &updater{
+		proxy:   strings.TrimSuffix(opts.Proxy, "/"),
+		private: strings.Join(opts.Private, ","),
+		http:    opts.HTTPClient,
+	}
+	if opts.SumDB != SumDBOff {
+		db, err := newSumDB(opts.SumDB, opts.HTTPClient)
+		if err != nil {
+			return nil, err
+		}
+		u.sumdb = db
+	}
+	return u, nil
+}
+
+// Update updates the required versions of the modules in the go.mod file, and adds their
+// checksums to the go.sum file. It returns ErrTidyRequired when the update is not a plain
+// version bump: the new versions must not require newer versions of other modules, nor a
+// newer Go version, than the current ones.
+func (u *updater) Update(ctx context.Context, params UpdateParams) (UpdateResult, error) {
+	f, err := modfile.Parse("go.mod", params.GoMod, nil)
+	if err != nil {
+		return UpdateResult{}, fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+	if f.Go == nil {
+		return UpdateResult{}, fmt.Errorf("%w: no go directive", ErrTidyRequired)
+	}
+
+	required := make(map[string]string, len(f.Require))
+	for _, r := range f.Require {
+		required[r.Mod.Path] = r.Mod.Version
+	}
+	replaced := make(map[string]bool, len(f.Replace))
+	for _, r := range f.Replace {
+		replaced[r.Old.Path] = true
+	}
+
+	// The required versions after the update, to check the requirements of the new versions
+	updated := make(map[string]string, len(required))
+	for path, version := range required {
+		updated[path] = version
+	}
+	for _, m := range params.Modules {
+		updated[m.Path] = m.Version
+	}
+
+	sums := newSumFile(params.GoSum)
+	for _, m := range params.Modules {
+		current, ok := required[m.Path]
+		switch {
+		case !ok:
+			return UpdateResult{}, fmt.Errorf("%w: %s is not required", ErrTidyRequired, m.Path)
+		case replaced[m.Path]:
+			return UpdateResult{}, fmt.Errorf("%w: %s is replaced", ErrTidyRequired, m.Path)
+		case module.MatchPrefixPatterns(u.private, m.Path):
+			return UpdateResult{}, fmt.Errorf("%w: %s is private", ErrTidyRequired, m.Path)
+		}
+
+		newMod, err := u.checkRequirements(ctx, f.Go.Version, updated, module.Version{Path: m.Path, Version: current}, m)
+		if err != nil {
+			return UpdateResult{}, err
+		}
+		lines, err := u.checksums(ctx, m, newMod)
+		if err != nil {
+			return UpdateResult{}, err
+		}
+
+		// The checksum of the previous go.mod file is kept, as other modules may require this version
+		sums.remove(m.Path + " " + current + " ")
+		sums.add(lines...)
+
+		if err := f.AddRequire(m.Path, m.Version); err != nil {
+			return UpdateResult{}, fmt.Errorf("failed to update %s: %w", m.Path, err)
+		}
+	}
+
+	f.Cleanup()
+	goMod, err := f.Format()
+	if err != nil {
+		return UpdateResult{}, fmt.Errorf("failed to format go.mod: %w", err)
+	}
+	return UpdateResult{GoMod: goMod, GoSum: sums.bytes()}, nil
+}
+
+// checkRequirements checks that the new version of a module requires neither a newer Go
+// version nor newer versions of other modules than the updated go.mod file and the current
+// version of the module, which would change the build list. It returns the go.mod file of
+// the new version.
+func (u *updater) checkRequirements(ctx context.Context, goVersion string, updated map[string]string,
+	current, target module.Version) ([]byte, error) {
+	newMod, err := u.fetch(ctx, target, ".mod")
+	if err != nil {
+		return nil, err
+	}
+	newFile, err := modfile.ParseLax("go.mod", newMod, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.mod of %s@%s: %w", target.Path, target.Version, err)
+	}
+	if newFile.Go != nil && semver.Compare("v"+newFile.Go.Version, "v"+goVersion) > 0 {
+		return nil, fmt.Errorf("%w: %s@%s requires go %s", ErrTidyRequired, target.Path, target.Version,
+			newFile.Go.Version)
+	}
+	if len(newFile.Require) == 0 {
+		return newMod, nil
+	}
+
+	// The requirements missing from the go.mod file are only kept in the build list
+	// by the current version of the module
+	currentMod, err := u.fetch(ctx, current, ".mod")
+	if err != nil {
+		return nil, err
+	}
+	currentFile, err := modfile.ParseLax("go.mod", currentMod, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.mod of %s@%s: %w", current.Path, current.Version, err)
+	}
+	previous := make(map[string]string, len(currentFile.Require))
+	for _, r := range currentFile.Require {
+		previous[r.Mod.Path] = r.Mod.Version
+	}
+
+	for _, r := range newFile.Require {
+		selected, ok := updated[r.Mod.Path]
+		if !ok {
+			selected, ok = previous[r.Mod.Path]
+		}
+		if !ok || semver.Compare(r.Mod.Version, selected) > 0 {
+			return nil, fmt.Errorf("%w: %s@%s requires %s@%s", ErrTidyRequired, target.Path, target.Version,
+				r.Mod.Path, r.Mod.Version)
+		}
+	}
+	return newMod, nil
+}
+
+// checksums returns the go.sum lines of a module version, from the checksum database or
+// computed from the proxy.
+func (u *updater) checksums(ctx context.Context, m module.Version, goMod []byte) ([]string, error) {
+	if u.sumdb != nil {
+		lines, err := u.sumdb.lookup(m.Path, m.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up the checksums of %s@%s: %w", m.Path, m.Version, err)
+		}
+		return lines, nil
+	}
+
+	modHash, err := dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(goMod)), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash go.mod of %s@%s: %w", m.Path, m.Version, err)
+	}
+	zipHash, err := u.zipHash(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		fmt.Sprintf("%s %s %s", m.Path, m.Version, zipHash),
+		fmt.Sprintf("%s %s/go.mod %s", m.Path, m.Version, modHash),
+	}, nil
+}
+
+// zipHash downloads the zip file of a module version from the proxy and returns its hash.
+func (u *updater) zipHash(ctx context.Context, m module.Version) (string, error) {
+	content, err := u.fetch(ctx, m, ".zip")
+	if err != nil {
+		return "", err
+	}
+
+	// The zip file is hashed from the disk, as it may be large
+	tmp, err := os.CreateTemp("", "depsync-*.zip")
+	if err != nil {
+		return "", fmt.Errorf("failed to create zip file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write zip file: %w", err)
+	}
+
+	hash, err := dirhash.HashZip(tmp.Name(), dirhash.Hash1)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash zip of %s@%s: %w", m.Path, m.Version, err)
+	}
+	return hash, nil
+}
+
+// fetch downloads a file of a module version from the proxy (e.g. ".mod", ".zip").
+func (u *updater) fetch(ctx context.Context, m module.Version, ext string) ([]byte, error) {
+	path, err := module.EscapePath(m.Path)
+	if err != nil {
+		return nil, err
+	}
+	version, err := module.EscapeVersion(m.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/%s/@v/%s%s", u.proxy, path, version, ext), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := u.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s@%s%s: %w", m.Path, m.Version, ext, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s@%s%s: %s", m.Path, m.Version, ext, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// sumFile is the set of lines of a go.sum file.
+type sumFile map[string]struct{}
+
+// newSumFile parses the lines of a go.sum file.
+func newSumFile(content []byte) sumFile {
+	sums := make(sumFile)
+	for _, line := range strings.Split(string(content), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			sums[line] = struct{}{}
+		}
+	}
+	return sums
+}
+
+// add adds lines to the file.
+func (s sumFile) add(lines ...string) {
+	for _, line := range lines {
+		s[line] = struct{}{}
+	}
+}
+
+// remove removes the lines starting with the prefix.
+func (s sumFile) remove(prefix string) {
+	for line := range s {
+		if strings.HasPrefix(line, prefix) {
+			delete(s, line)
+		}
+	}
+}
+
+// bytes returns the content of the file, with its lines sorted as by the go command.
+func (s sumFile) bytes() []byte {
+	lines := make([]string, 0, len(s))
+	for line := range s {
+		lines = append(lines, line)
+	}
+	sort.Strings(lines)
+
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
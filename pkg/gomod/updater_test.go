@@ -0,0 +1,193 @@
+//go:build unit
+// +build unit
+
+package gomod
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/dirhash"
+	"golang.org/x/mod/sumdb/note"
+)
+
+const serviceGoMod = `module example.com/service
+
+go 1.22
+
+require (
+	example.com/dep v1.0.0
+	golang.org/x/text v0.14.0 // indirect
+)
+`
+
+const serviceGoSum = `example.com/dep v1.0.0 h1:old=
+example.com/dep v1.0.0/go.mod h1:oldmod=
+golang.org/x/text v0.14.0 h1:text=
+golang.org/x/text v0.14.0/go.mod h1:textmod=
+`
+
+// newTestProxy serves the go.mod files of the module versions, by "path@version",
+// and a zip file containing the go.mod file.
+func newTestProxy(t *testing.T, mods map[string]string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, file, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/@v/")
+		require.True(t, ok, r.URL.Path)
+		version, ext := file[:strings.LastIndex(file, ".")], file[strings.LastIndex(file, "."):]
+		mod, ok := mods[path+"@"+version]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		switch ext {
+		case ".mod":
+			_, _ = w.Write([]byte(mod))
+		case ".zip":
+			zw := zip.NewWriter(w)
+			f, err := zw.Create(path + "@" + version + "/go.mod")
+			require.NoError(t, err)
+			_, _ = f.Write([]byte(mod))
+			require.NoError(t, zw.Close())
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// hash1 returns the hash of the files, as in go.sum files.
+func hash1(t *testing.T, files map[string]string) string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	hash, err := dirhash.Hash1(names, func(name string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(files[name])), nil
+	})
+	require.NoError(t, err)
+	return hash
+}
+
+func TestUpdate_ProxyChecksums(t *testing.T) {
+	newMod := "module example.com/dep\n\ngo 1.21\n\nrequire golang.org/x/text v0.14.0\n"
+	proxy := newTestProxy(t, map[string]string{
+		"example.com/dep@v1.0.0": "module example.com/dep\n\ngo 1.21\n\nrequire golang.org/x/text v0.13.0\n",
+		"example.com/dep@v1.1.0": newMod,
+	})
+	u, err := NewUpdater(Options{Proxy: proxy.URL, SumDB: SumDBOff})
+	require.NoError(t, err)
+
+	result, err := u.Update(context.Background(), UpdateParams{
+		GoMod:   []byte(serviceGoMod),
+		GoSum:   []byte(serviceGoSum),
+		Modules: []module.Version{{Path: "example.com/dep", Version: "v1.1.0"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, strings.Replace(serviceGoMod, "dep v1.0.0", "dep v1.1.0", 1), string(result.GoMod))
+
+	// The checksum of the previous zip is removed, and the one of its go.mod kept
+	require.Equal(t, fmt.Sprintf(`example.com/dep v1.0.0/go.mod h1:oldmod=
+example.com/dep v1.1.0 %s
+example.com/dep v1.1.0/go.mod %s
+golang.org/x/text v0.14.0 h1:text=
+golang.org/x/text v0.14.0/go.mod h1:textmod=
+`, hash1(t, map[string]string{"example.com/dep@v1.1.0/go.mod": newMod}), hash1(t, map[string]string{"go.mod": newMod})),
+		string(result.GoSum))
+}
+
+func TestUpdate_TidyRequired(t *testing.T) {
+	proxy := newTestProxy(t, map[string]string{
+		"example.com/dep@v1.0.0": "module example.com/dep\n\ngo 1.21\n\nrequire example.com/other v1.0.0\n",
+		// Newer requirement of a module required by the service
+		"example.com/dep@v1.1.0": "module example.com/dep\n\ngo 1.21\n\nrequire golang.org/x/text v0.15.0\n",
+		// Newer requirement of a module only required by the dependency
+		"example.com/dep@v1.2.0": "module example.com/dep\n\ngo 1.21\n\nrequire example.com/other v1.1.0\n",
+		// New requirement
+		"example.com/dep@v1.3.0": "module example.com/dep\n\ngo 1.21\n\nrequire example.com/new v1.0.0\n",
+		// Newer Go version
+		"example.com/dep@v1.4.0": "module example.com/dep\n\ngo 1.23\n",
+	})
+	u, err := NewUpdater(Options{Proxy: proxy.URL, SumDB: SumDBOff, Private: []string{"example.com/private"}})
+	require.NoError(t, err)
+
+	cases := []module.Version{
+		{Path: "example.com/dep", Version: "v1.1.0"},
+		{Path: "example.com/dep", Version: "v1.2.0"},
+		{Path: "example.com/dep", Version: "v1.3.0"},
+		{Path: "example.com/dep", Version: "v1.4.0"},
+		{Path: "example.com/missing", Version: "v1.0.0"},
+		{Path: "example.com/private/lib", Version: "v1.0.0"},
+	}
+	goMod := serviceGoMod + "\nrequire example.com/private/lib v0.9.0\n"
+	for _, m := range cases {
+		_, err := u.Update(context.Background(), UpdateParams{
+			GoMod:   []byte(goMod),
+			GoSum:   []byte(serviceGoSum),
+			Modules: []module.Version{m},
+		})
+		require.ErrorIs(t, err, ErrTidyRequired, m.String())
+	}
+
+	// The proxy errors are not tidy errors
+	_, err = u.Update(context.Background(), UpdateParams{
+		GoMod:   []byte(serviceGoMod),
+		GoSum:   []byte(serviceGoSum),
+		Modules: []module.Version{{Path: "example.com/dep", Version: "v9.0.0"}},
+	})
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrTidyRequired)
+}
+
+func TestUpdate_SumDB(t *testing.T) {
+	proxy := newTestProxy(t, map[string]string{
+		"example.com/dep@v1.1.0": "module example.com/dep\n\ngo 1.21\n",
+	})
+
+	// Serve a checksum database signed with a test key
+	signer, verifier, err := note.GenerateKey(rand.Reader, "sumdb.test")
+	require.NoError(t, err)
+	gosum := func(path, version string) ([]byte, error) {
+		return []byte(fmt.Sprintf("%s %s h1:zip=\n%s %s/go.mod h1:mod=\n", path, version, path, version)), nil
+	}
+	db := httptest.NewServer(sumdb.NewServer(sumdb.NewTestServer(signer, gosum)))
+	defer db.Close()
+
+	u, err := NewUpdater(Options{Proxy: proxy.URL, SumDB: verifier + " " + db.URL})
+	require.NoError(t, err)
+	result, err := u.Update(context.Background(), UpdateParams{
+		GoMod:   []byte(serviceGoMod),
+		GoSum:   []byte(serviceGoSum),
+		Modules: []module.Version{{Path: "example.com/dep", Version: "v1.1.0"}},
+	})
+	require.NoError(t, err)
+	require.Contains(t, string(result.GoSum), "example.com/dep v1.1.0 h1:zip=\nexample.com/dep v1.1.0/go.mod h1:mod=\n")
+	require.True(t, bytes.Contains(result.GoMod, []byte("example.com/dep v1.1.0")))
+
+	// The records are verified with the key of the database
+	_, otherVerifier, err := note.GenerateKey(rand.Reader, "sumdb.test")
+	require.NoError(t, err)
+	u, err = NewUpdater(Options{Proxy: proxy.URL, SumDB: otherVerifier + " " + db.URL})
+	require.NoError(t, err)
+	_, err = u.Update(context.Background(), UpdateParams{
+		GoMod:   []byte(serviceGoMod),
+		GoSum:   []byte(serviceGoSum),
+		Modules: []module.Version{{Path: "example.com/dep", Version: "v1.1.0"}},
+	})
+	require.Error(t, err)
+}
+
+func TestNewUpdater_InvalidSumDB(t *testing.T) {
+	_, err := NewUpdater(Options{SumDB: "unknown.example.com"})
+	require.Error(t, err)
+}
@@ -0,0 +1,77 @@
+package gomod
+
+import (
+	"sort"
+	"strings"
+)
+
+// VendorModulesFile is the file listing the vendored modules, whose presence marks a
+// repository committing its vendor directory.
+const VendorModulesFile = "vendor/modules.txt"
+
+// VendorUpdate is a vendored module whose version changed.
+type VendorUpdate struct {
+	Path string
+	From string
+	To   string
+}
+
+// VendorDiff contains the changes of the vendored modules, sorted by module path.
+type VendorDiff struct {
+	// Added modules have no From version, and removed modules no To version.
+	Added   []VendorUpdate
+	Removed []VendorUpdate
+	Updated []VendorUpdate
+}
+
+// Empty checks if no vendored module changed.
+func (d VendorDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Updated) == 0
+}
+
+// ParseVendorModules parses a vendor/modules.txt file and returns the versions of the
+// vendored modules, by path. The version of a replaced module includes its replacement
+// (e.g. "v1.0.0 => example.com/fork v1.0.1").
+func ParseVendorModules(content []byte) map[string]string {
+	modules := make(map[string]string)
+	for _, line := range strings.Split(string(content), "\n") {
+		// Module lines start with "# ", their annotations with "## "
+		line, ok := strings.CutPrefix(strings.TrimSpace(line), "# ")
+		if !ok {
+			continue
+		}
+		path, version, _ := strings.Cut(line, " ")
+		if path != "" {
+			modules[path] = strings.TrimSpace(version)
+		}
+	}
+	return modules
+}
+
+// DiffVendorModules compares the vendored modules of two vendor/modules.txt files.
+func DiffVendorModules(before, after []byte) VendorDiff {
+	previous, current := ParseVendorModules(before), ParseVendorModules(after)
+
+	var diff VendorDiff
+	for path, version := range current {
+		old, ok := previous[path]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, VendorUpdate{Path: path, To: version})
+		case old != version:
+			diff.Updated = append(diff.Updated, VendorUpdate{Path: path, From: old, To: version})
+		}
+	}
+	for path, version := range previous {
+		if _, ok := current[path]; !ok {
+			diff.Removed = append(diff.Removed, VendorUpdate{Path: path, From: version})
+		}
+	}
+
+	for _, updates := range [][]VendorUpdate{diff.Added, diff.Removed, diff.Updated} {
+		sort.Slice(updates, func(i, j int) bool {
+			return updates[i].Path < updates[j].Path
+		})
+	}
+	return diff
+}
@@ -0,0 +1,56 @@
+//go:build unit
+// +build unit
+
+package gomod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const vendorModules = `# example.com/dep v1.0.0
+## explicit; go 1.21
+example.com/dep
+example.com/dep/sub
+# example.com/old v0.1.0
+## explicit
+example.com/old
+# golang.org/x/text v0.14.0 => example.com/text v0.14.1
+golang.org/x/text/language
+# example.com/local => ../local
+`
+
+func TestParseVendorModules(t *testing.T) {
+	require.Equal(t, map[string]string{
+		"example.com/dep":   "v1.0.0",
+		"example.com/old":   "v0.1.0",
+		"golang.org/x/text": "v0.14.0 => example.com/text v0.14.1",
+		"example.com/local": "=> ../local",
+	}, ParseVendorModules([]byte(vendorModules)))
+}
+
+func TestDiffVendorModules(t *testing.T) {
+	after := `# example.com/dep v1.1.0
+## explicit; go 1.21
+example.com/dep
+# example.com/new v1.0.0
+example.com/new
+# golang.org/x/text v0.14.0 => example.com/text v0.14.2
+golang.org/x/text/language
+# example.com/local => ../local
+`
+	diff := DiffVendorModules([]byte(vendorModules), []byte(after))
+	require.Equal(t, VendorDiff{
+		Added:   []VendorUpdate{{Path: "example.com/new", To: "v1.0.0"}},
+		Removed: []VendorUpdate{{Path: "example.com/old", From: "v0.1.0"}},
+		Updated: []VendorUpdate{
+			{Path: "example.com/dep", From: "v1.0.0", To: "v1.1.0"},
+			{Path: "golang.org/x/text", From: "v0.14.0 => example.com/text v0.14.1",
+				To: "v0.14.0 => example.com/text v0.14.2"},
+		},
+	}, diff)
+	require.False(t, diff.Empty())
+
+	require.True(t, DiffVendorModules([]byte(vendorModules), []byte(vendorModules)).Empty())
+}
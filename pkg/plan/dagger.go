@@ -0,0 +1,160 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+)
+
+// DigestResolver resolves the digests of images without side effect, such as with the
+// registry API.
+type DigestResolver interface {
+	ResolveImageDigest(ctx context.Context, image string) (string, error)
+}
+
+// daggerPlanner is a Dagger implementation recording the operations in a plan, without
+// Dagger. Branch existence is checked with the GitHub API.
+type daggerPlanner struct {
+	client   forge.Client
+	resolver DigestResolver
+	plan     *Plan
+}
+
+// workspace is the workspace of the plan, identifying the repository updated by the operations.
+type workspace struct {
+	repoURL string
+}
+
+// RepoURL implements the dagger.Workspace interface.
+func (w *workspace) RepoURL() string {
+	return w.repoURL
+}
+
+// NewDagger creates a Dagger implementation that records the operations in the plan.
+// It checks the branches existence with the GitHub client, and resolves the image digests
+// with the resolver, which may be nil when the digests are not pinned.
+func NewDagger(client forge.Client, plan *Plan, resolver DigestResolver) dagger.Dagger {
+	return &daggerPlanner{
+		client:   client,
+		resolver: resolver,
+		plan:     plan,
+	}
+}
+
+// CloneRepo records the repository clone.
+func (d *daggerPlanner) CloneRepo(_ context.Context, repoURL, branch string) (dagger.Workspace, error) {
+	d.plan.Add(Action{Type: ActionClone, RepoURL: repoURL, BranchName: branch})
+	return &workspace{repoURL: repoURL}, nil
+}
+
+// ResetRepo returns the workspace, as the plan has no working copy to reset.
+func (d *daggerPlanner) ResetRepo(_ context.Context, ws dagger.Workspace) (dagger.Workspace, error) {
+	return ws, nil
+}
+
+// RemoveRepo implements the Dagger interface.
+func (d *daggerPlanner) RemoveRepo(_ context.Context, _ dagger.Workspace) error {
+	return nil
+}
+
+// UpdateGoDependency records the dependency update.
+func (d *daggerPlanner) UpdateGoDependency(_ context.Context, params dagger.UpdateGoDependencyParams) (
+	dagger.Workspace, error) {
+	d.addUpdate(params.Dir, withVerification(fmt.Sprintf("go get %s@%s", params.ModulePath, params.TargetVersion),
+		params.Verify))
+	return params.Dir, nil
+}
+
+// UpdateGoDependencies records the dependencies update.
+func (d *daggerPlanner) UpdateGoDependencies(_ context.Context, params dagger.UpdateGoDependenciesParams) (
+	dagger.Workspace, error) {
+	d.addUpdate(params.Dir, withVerification("go get "+formatModules(params.Modules), params.Verify))
+	return params.Dir, nil
+}
+
+// UpdateGoTools records the tools update.
+func (d *daggerPlanner) UpdateGoTools(_ context.Context, params dagger.UpdateGoToolsParams) (
+	dagger.Workspace, error) {
+	details := "go get " + formatModules(params.Tools)
+	if params.Generate {
+		details += " && go generate ./..."
+	}
+	d.addUpdate(params.Dir, withVerification(details, params.Verify))
+	return params.Dir, nil
+}
+
+// WriteFiles records the files update.
+func (d *daggerPlanner) WriteFiles(_ context.Context, params dagger.WriteFilesParams) (dagger.Workspace, error) {
+	paths := make([]string, 0, len(params.Files))
+	for _, f := range params.Files {
+		paths = append(paths, f.Path)
+	}
+	d.addUpdate(params.Dir, "write "+strings.Join(paths, ", "))
+	return params.Dir, nil
+}
+
+// CheckBranchExists checks the branch existence with the GitHub API.
+func (d *daggerPlanner) CheckBranchExists(ctx context.Context, params dagger.CheckBranchExistsParams) (bool, error) {
+	return d.client.BranchExists(ctx, forge.BranchExistsParams{
+		RepoURL:    params.RepoURL,
+		BranchName: params.BranchName,
+	})
+}
+
+// CommitAndPush records the branch push.
+func (d *daggerPlanner) CommitAndPush(_ context.Context, params dagger.CommitAndPushParams) (string, error) {
+	message := params.CommitMessage
+	if message == "" {
+		message = adapters.FormatCommitMessage(params.ModulePath, params.TargetVersion)
+	}
+	d.plan.Add(Action{
+		Type:       ActionPushBranch,
+		RepoURL:    params.RepoURL,
+		BranchName: params.BranchName,
+		Details:    message,
+	})
+	return params.BranchName, nil
+}
+
+// ResolveImageDigest resolves the digest of the image with the resolver, as it has no side effect.
+func (d *daggerPlanner) ResolveImageDigest(ctx context.Context, image string) (string, error) {
+	if d.resolver == nil {
+		return "", fmt.Errorf("no digest resolver to resolve the digest of %s", image)
+	}
+	return d.resolver.ResolveImageDigest(ctx, image)
+}
+
+// Close implements the Dagger interface.
+func (d *daggerPlanner) Close() error {
+	return nil
+}
+
+// addUpdate records an update of the repository of the workspace.
+func (d *daggerPlanner) addUpdate(ws dagger.Workspace, details string) {
+	var repoURL string
+	if ws != nil {
+		repoURL = ws.RepoURL()
+	}
+	d.plan.Add(Action{Type: ActionUpdate, RepoURL: repoURL, Details: details})
+}
+
+// withVerification appends the verification commands of an update to its details.
+func withVerification(details string, commands []string) string {
+	for _, command := range commands {
+		details += " && " + command
+	}
+	return details
+}
+
+// formatModules formats modules as "path@version" arguments.
+func formatModules(modules []dagger.Module) string {
+	args := make([]string, 0, len(modules))
+	for _, m := range modules {
+		args = append(args, m.Path+"@"+m.Version)
+	}
+	return strings.Join(args, " ")
+}
@@ -0,0 +1,85 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// FileVersion is the version of the plan file format.
+const FileVersion = 1
+
+// ServiceSnapshot is the state of a service of the dependency graph when the plan was made.
+type ServiceSnapshot struct {
+	ModulePath    string `json:"module_path"`
+	RepoURL       string `json:"repo_url"`
+	LatestVersion string `json:"latest_version,omitempty"`
+	// Dependencies contains the current version of the service dependencies, by module path.
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+}
+
+// Preconditions are the conditions an operation was planned under. The operation
+// must not be applied if they no longer hold.
+type Preconditions struct {
+	// MainSHA is the head commit of the main branch of the service repository.
+	MainSHA string `json:"main_sha"`
+	// TargetVersion is the latest version of the dependency.
+	TargetVersion string `json:"target_version"`
+}
+
+// Operation is a dependency update of a service.
+type Operation struct {
+	Service       string        `json:"service"`
+	RepoURL       string        `json:"repo_url"`
+	Dependency    string        `json:"dependency"`
+	From          string        `json:"from"`
+	To            string        `json:"to"`
+	Preconditions Preconditions `json:"preconditions"`
+}
+
+// File is a serialized plan, to be reviewed before being applied.
+type File struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// Graph is the dependency graph snapshot, sorted by module path.
+	Graph []ServiceSnapshot `json:"graph"`
+	// Operations are the dependency updates to apply, sorted by service and dependency.
+	Operations []Operation `json:"operations"`
+	// Actions are the actions the operations lead to, for review.
+	Actions []Action `json:"actions"`
+}
+
+// Write writes the plan file to the given path, through a temporary file to never leave it partially written.
+func Write(path string, file *File) error {
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write plan file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace plan file: %w", err)
+	}
+	return nil
+}
+
+// Read reads the plan file at the given path.
+func Read(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file %s: %w", path, err)
+	}
+
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse plan file %s: %w", path, err)
+	}
+	if file.Version != FileVersion {
+		return nil, fmt.Errorf("unsupported plan file version %d (expected %d)", file.Version, FileVersion)
+	}
+	return &file, nil
+}
@@ -0,0 +1,85 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+)
+
+// githubClient is a GitHub client recording its write operations in a plan instead of
+// executing them. Read operations are delegated to the actual client.
+type githubClient struct {
+	forge.Client
+	plan *Plan
+}
+
+// NewGitHubClient creates a GitHub client that records the write operations in the plan.
+func NewGitHubClient(client forge.Client, plan *Plan) forge.Client {
+	return &githubClient{
+		Client: client,
+		plan:   plan,
+	}
+}
+
+// CreateMergeRequest records the pull request creation. It returns 0 as pull request number.
+func (c *githubClient) CreateMergeRequest(_ context.Context, params forge.CreateMergeRequestParams) (int, error) {
+	details := params.Title
+	if details == "" {
+		details = adapters.FormatCommitMessage(params.ModulePath, params.TargetVersion)
+	}
+	c.plan.Add(Action{
+		Type:       ActionCreatePR,
+		RepoURL:    params.RepoURL,
+		BranchName: params.SourceBranch,
+		Details:    details,
+	})
+	return 0, nil
+}
+
+// CreateCommit records the branch push, with the committed files. It returns an empty SHA.
+func (c *githubClient) CreateCommit(_ context.Context, params forge.CreateCommitParams) (string, error) {
+	paths := make([]string, 0, len(params.Files))
+	for _, f := range params.Files {
+		paths = append(paths, f.Path)
+	}
+	c.plan.Add(Action{
+		Type:       ActionPushBranch,
+		RepoURL:    params.RepoURL,
+		BranchName: params.BranchName,
+		Details:    fmt.Sprintf("%s [%s]", params.Message, strings.Join(paths, ", ")),
+	})
+	return "", nil
+}
+
+// MergeMergeRequest records the pull request merge.
+func (c *githubClient) MergeMergeRequest(_ context.Context, params forge.MergeMergeRequestParams) error {
+	c.plan.Add(Action{
+		Type:     ActionMerge,
+		RepoURL:  params.RepoURL,
+		PRNumber: params.PRNumber,
+	})
+	return nil
+}
+
+// DeleteBranch records the branch deletion.
+func (c *githubClient) DeleteBranch(_ context.Context, params forge.DeleteBranchParams) error {
+	c.plan.Add(Action{
+		Type:       ActionDeleteBranch,
+		RepoURL:    params.RepoURL,
+		BranchName: params.BranchName,
+	})
+	return nil
+}
+
+// DeletePullRequest records the conflicted pull request closing.
+func (c *githubClient) DeletePullRequest(_ context.Context, params forge.DeletePullRequestParams) error {
+	c.plan.Add(Action{
+		Type:     ActionCloseConflictedPR,
+		RepoURL:  params.RepoURL,
+		PRNumber: params.PRNumber,
+	})
+	return nil
+}
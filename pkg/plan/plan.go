@@ -0,0 +1,99 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// ActionType is the type of an action depsync would take.
+type ActionType string
+
+const (
+	// ActionClone clones a repository.
+	ActionClone ActionType = "clone"
+	// ActionUpdate updates the content of a cloned repository (dependencies, files, etc).
+	ActionUpdate ActionType = "update"
+	// ActionPushBranch commits the update and pushes it on a new branch.
+	ActionPushBranch ActionType = "push_branch"
+	// ActionCreatePR opens a pull request.
+	ActionCreatePR ActionType = "create_pr"
+	// ActionMerge merges a pull request.
+	ActionMerge ActionType = "merge"
+	// ActionCloseConflictedPR closes a conflicted pull request.
+	ActionCloseConflictedPR ActionType = "close_conflicted_pr"
+	// ActionDeleteBranch deletes a branch.
+	ActionDeleteBranch ActionType = "delete_branch"
+)
+
+// Action is an action depsync would take.
+type Action struct {
+	Type       ActionType `json:"type"`
+	RepoURL    string     `json:"repo_url"`
+	BranchName string     `json:"branch_name,omitempty"`
+	PRNumber   int        `json:"pr_number,omitempty"`
+	// Details describes the action (e.g. the updated dependencies, the pull request title).
+	Details string `json:"details,omitempty"`
+}
+
+// String returns a human readable description of the action.
+func (a Action) String() string {
+	parts := []string{string(a.Type), a.RepoURL}
+	if a.BranchName != "" {
+		parts = append(parts, "branch="+a.BranchName)
+	}
+	if a.PRNumber > 0 {
+		parts = append(parts, fmt.Sprintf("pr=#%d", a.PRNumber))
+	}
+	if a.Details != "" {
+		parts = append(parts, "("+a.Details+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+// Plan is the list of actions depsync would take, in order.
+type Plan struct {
+	mu      sync.Mutex
+	actions []Action
+}
+
+// New creates an empty plan.
+func New() *Plan {
+	return &Plan{}
+}
+
+// Add appends an action to the plan.
+func (p *Plan) Add(action Action) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.actions = append(p.actions, action)
+}
+
+// Actions returns the actions of the plan.
+func (p *Plan) Actions() []Action {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Action(nil), p.actions...)
+}
+
+// Print writes the plan in a human readable format.
+func (p *Plan) Print(w io.Writer) error {
+	actions := p.Actions()
+	if len(actions) == 0 {
+		_, err := fmt.Fprintln(w, "No action to take.")
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "%d action(s) to take:\n", len(actions)); err != nil {
+		return err
+	}
+	for i, a := range actions {
+		if _, err := fmt.Fprintf(w, "%3d. %s\n", i+1, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
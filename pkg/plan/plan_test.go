@@ -0,0 +1,181 @@
+//go:build unit
+// +build unit
+
+package plan
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/depsync/pkg/adapters/dagger"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPlan_PrintEmpty(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, New().Print(&out))
+	require.Equal(t, "No action to take.\n", out.String())
+}
+
+func TestGitHubClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := forge.NewMockClient(ctrl)
+	p := New()
+	client := NewGitHubClient(mock, p)
+	ctx := context.Background()
+
+	// Read operations are delegated
+	mock.EXPECT().CheckPullRequestExists(ctx, forge.CheckPullRequestExistsParams{
+		RepoURL:      "https://github.com/test/repo",
+		SourceBranch: "depsync/branch",
+	}).Return(7, nil)
+	number, err := client.CheckPullRequestExists(ctx, forge.CheckPullRequestExistsParams{
+		RepoURL:      "https://github.com/test/repo",
+		SourceBranch: "depsync/branch",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 7, number)
+
+	// Write operations are recorded
+	number, err = client.CreateMergeRequest(ctx, forge.CreateMergeRequestParams{
+		RepoURL:      "https://github.com/test/repo",
+		SourceBranch: "depsync/branch",
+		Title:        "chores(depsync): align tools versions",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, number)
+	require.NoError(t, client.DeletePullRequest(ctx, forge.DeletePullRequestParams{
+		RepoURL:  "https://github.com/test/repo",
+		PRNumber: 7,
+	}))
+	sha, err := client.CreateCommit(ctx, forge.CreateCommitParams{
+		RepoURL:    "https://github.com/test/repo",
+		BaseBranch: "main",
+		BranchName: "depsync/update",
+		Message:    "chores(depsync): update",
+		Files:      []forge.CommitFile{{Path: "go.mod"}, {Path: "go.sum"}},
+	})
+	require.NoError(t, err)
+	require.Empty(t, sha)
+
+	require.Equal(t, []Action{
+		{
+			Type:       ActionCreatePR,
+			RepoURL:    "https://github.com/test/repo",
+			BranchName: "depsync/branch",
+			Details:    "chores(depsync): align tools versions",
+		},
+		{Type: ActionCloseConflictedPR, RepoURL: "https://github.com/test/repo", PRNumber: 7},
+		{
+			Type:       ActionPushBranch,
+			RepoURL:    "https://github.com/test/repo",
+			BranchName: "depsync/update",
+			Details:    "chores(depsync): update [go.mod, go.sum]",
+		},
+	}, p.Actions())
+}
+
+func TestDagger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := forge.NewMockClient(ctrl)
+	resolver := dagger.NewMockDagger(ctrl)
+	p := New()
+	d := NewDagger(mock, p, resolver)
+	ctx := context.Background()
+
+	ws, err := d.CloneRepo(ctx, "https://github.com/test/repo", "main")
+	require.NoError(t, err)
+	other, err := d.CloneRepo(ctx, "https://github.com/test/other", "main")
+	require.NoError(t, err)
+	_, err = d.UpdateGoTools(ctx, dagger.UpdateGoToolsParams{
+		Dir:      ws,
+		Tools:    []dagger.Module{{Path: "go.uber.org/mock", Version: "v0.5.2"}},
+		Generate: true,
+		Verify:   []string{"go build ./..."},
+	})
+	require.NoError(t, err)
+	// The updates of a reset workspace are the ones of its repository
+	other, err = d.ResetRepo(ctx, other)
+	require.NoError(t, err)
+	_, err = d.WriteFiles(ctx, dagger.WriteFilesParams{
+		Dir:   other,
+		Files: []dagger.File{{Path: "Dockerfile"}, {Path: ".golangci.yml"}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, d.RemoveRepo(ctx, other))
+
+	// The digests are resolved, as it has no side effect
+	resolver.EXPECT().ResolveImageDigest(ctx, "golang:1.24").Return("sha256:abc", nil)
+	digest, err := d.ResolveImageDigest(ctx, "golang:1.24")
+	require.NoError(t, err)
+	require.Equal(t, "sha256:abc", digest)
+
+	mock.EXPECT().BranchExists(ctx, forge.BranchExistsParams{
+		RepoURL:    "https://github.com/test/repo",
+		BranchName: "depsync/branch",
+	}).Return(true, nil)
+	exists, err := d.CheckBranchExists(ctx, dagger.CheckBranchExistsParams{
+		RepoURL:    "https://github.com/test/repo",
+		BranchName: "depsync/branch",
+	})
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	require.Equal(t, []Action{
+		{Type: ActionClone, RepoURL: "https://github.com/test/repo", BranchName: "main"},
+		{Type: ActionClone, RepoURL: "https://github.com/test/other", BranchName: "main"},
+		{
+			Type:    ActionUpdate,
+			RepoURL: "https://github.com/test/repo",
+			Details: "go get go.uber.org/mock@v0.5.2 && go generate ./... && go build ./...",
+		},
+		{Type: ActionUpdate, RepoURL: "https://github.com/test/other", Details: "write Dockerfile, .golangci.yml"},
+	}, p.Actions())
+}
+
+func TestDagger_NoResolver(t *testing.T) {
+	d := NewDagger(forge.NewMockClient(gomock.NewController(t)), New(), nil)
+	_, err := d.ResolveImageDigest(context.Background(), "golang:1.24")
+	require.Error(t, err)
+}
+
+func TestFile_WriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	file := &File{
+		Version:   FileVersion,
+		CreatedAt: time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC),
+		Graph: []ServiceSnapshot{{
+			ModulePath:   "github.com/test/repo",
+			RepoURL:      "https://github.com/test/repo",
+			Dependencies: map[string]string{"github.com/test/dep": "v1.0.0"},
+		}},
+		Operations: []Operation{{
+			Service:       "github.com/test/repo",
+			RepoURL:       "https://github.com/test/repo",
+			Dependency:    "github.com/test/dep",
+			From:          "v1.0.0",
+			To:            "v1.1.0",
+			Preconditions: Preconditions{MainSHA: "abc123", TargetVersion: "v1.1.0"},
+		}},
+		Actions: []Action{{Type: ActionClone, RepoURL: "https://github.com/test/repo", BranchName: "main"}},
+	}
+
+	require.NoError(t, Write(path, file))
+	read, err := Read(path)
+	require.NoError(t, err)
+	require.Equal(t, file, read)
+}
+
+func TestFile_ReadUnsupportedVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 2}`), 0o600))
+
+	_, err := Read(path)
+	require.ErrorContains(t, err, "unsupported plan file version 2")
+}
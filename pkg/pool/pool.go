@@ -0,0 +1,53 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ForEach calls fn for each index in [0, n), with at most workers concurrent calls.
+//
+// Once a call fails or the context is canceled, the remaining indexes are not started,
+// but the calls in progress are awaited. The errors of the calls are returned joined in
+// index order, so that the result does not depend on the scheduling.
+func ForEach(ctx context.Context, workers, n int, fn func(ctx context.Context, i int) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	errs := make([]error, n)
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+
+	started := 0
+	for ; started < n; started++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil || failed.Load() {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := fn(ctx, i); err != nil {
+				errs[i] = err
+				failed.Store(true)
+			}
+		}(started)
+	}
+	wg.Wait()
+
+	err := errors.Join(errs...)
+	if err == nil && started < n {
+		return ctx.Err()
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+//go:build unit
+// +build unit
+
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEach(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	var mu sync.Mutex
+	done := make(map[int]bool)
+
+	err := ForEach(context.Background(), 3, 10, func(_ context.Context, i int) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		done[i] = true
+		mu.Unlock()
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, done, 10)
+	require.LessOrEqual(t, maxRunning.Load(), int32(3))
+}
+
+func TestForEach_ErrorsInIndexOrder(t *testing.T) {
+	err1, err3 := errors.New("error 1"), errors.New("error 3")
+
+	// Both calls fail, the last indexes being the first to fail
+	err := ForEach(context.Background(), 4, 4, func(_ context.Context, i int) error {
+		switch i {
+		case 1:
+			time.Sleep(20 * time.Millisecond)
+			return err1
+		case 3:
+			return err3
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, err1)
+	require.ErrorIs(t, err, err3)
+	require.Equal(t, "error 1\nerror 3", err.Error())
+}
+
+func TestForEach_StopsAfterError(t *testing.T) {
+	var calls atomic.Int32
+	err := ForEach(context.Background(), 1, 5, func(_ context.Context, i int) error {
+		calls.Add(1)
+		if i == 1 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	require.EqualError(t, err, "failed")
+	require.Equal(t, int32(2), calls.Load())
+}
+
+func TestForEach_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	err := ForEach(ctx, 1, 5, func(_ context.Context, _ int) error {
+		calls.Add(1)
+		cancel()
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, int32(1), calls.Load())
+}
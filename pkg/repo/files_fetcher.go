@@ -4,7 +4,8 @@ import (
 	"context"
 	"errors"
 
-	"github.com/cryptellation/depsync/pkg/adapters/github"
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
 )
 
 //go:generate go run go.uber.org/mock/mockgen@v0.5.2 -source=files_fetcher.go -destination=mock_fetcher.gen.go -package=repo
@@ -15,17 +16,18 @@ var ErrInvalidRepoURL = errors.New("invalid repository URL")
 // FilesFetcher defines the interface for fetching repository files.
 type FilesFetcher interface {
 	Fetch(ctx context.Context, repoURL, ref string, files ...string) (map[string][]byte, error)
+	List(ctx context.Context, repoURL, ref, dir string) ([]string, error)
 }
 
 // fetcher fetches content from configured repositories using the GitHub adapter.
 type fetcher struct {
-	client github.Client
+	client forge.Client
 }
 
 // Ensure fetcher implements Fetcher.
 var _ FilesFetcher = (*fetcher)(nil)
 
-func NewFilesFetcher(client github.Client) FilesFetcher {
+func NewFilesFetcher(client forge.Client) FilesFetcher {
 	return &fetcher{client: client}
 }
 
@@ -35,13 +37,14 @@ func (f *fetcher) Fetch(
 	repoURL, ref string,
 	files ...string,
 ) (map[string][]byte, error) {
-	owner, name := parseOwnerAndRepo(repoURL)
-	if owner == "" || name == "" {
+	host, owner, name := adapters.ParseRepository(repoURL)
+	if host == "" {
 		return nil, ErrInvalidRepoURL
 	}
 	results := make(map[string][]byte)
 	for _, file := range files {
-		content, err := f.client.GetFileContent(ctx, github.GetFileContentParams{
+		content, err := f.client.GetFileContent(ctx, forge.GetFileContentParams{
+			Host:  host,
 			Owner: owner,
 			Repo:  name,
 			Path:  file,
@@ -54,3 +57,18 @@ func (f *fetcher) Fetch(
 	}
 	return results, nil
 }
+
+// List lists the paths of the files in the given directory of the specified repository URL and ref.
+func (f *fetcher) List(ctx context.Context, repoURL, ref, dir string) ([]string, error) {
+	host, owner, name := adapters.ParseRepository(repoURL)
+	if host == "" {
+		return nil, ErrInvalidRepoURL
+	}
+	return f.client.ListDirectory(ctx, forge.ListDirectoryParams{
+		Host:  host,
+		Owner: owner,
+		Repo:  name,
+		Path:  dir,
+		Ref:   ref,
+	})
+}
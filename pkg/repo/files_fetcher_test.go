@@ -8,7 +8,7 @@ import (
 	"errors"
 	"testing"
 
-	"github.com/cryptellation/depsync/pkg/adapters/github"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -17,18 +17,20 @@ func TestFetchRepositoryFiles(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	t.Cleanup(ctrl.Finish)
 
-	mockClient := github.NewMockClient(ctrl)
+	mockClient := forge.NewMockClient(ctrl)
 	fetcher := NewFilesFetcher(mockClient)
 
 	repoURL := "https://github.com/owner1/repo1.git"
 	ctx := context.Background()
-	mockClient.EXPECT().GetFileContent(ctx, github.GetFileContentParams{
+	mockClient.EXPECT().GetFileContent(ctx, forge.GetFileContentParams{
+		Host:  "github.com",
 		Owner: "owner1",
 		Repo:  "repo1",
 		Path:  "README.md",
 		Ref:   "main",
 	}).Return([]byte("content1"), nil)
-	mockClient.EXPECT().GetFileContent(ctx, github.GetFileContentParams{
+	mockClient.EXPECT().GetFileContent(ctx, forge.GetFileContentParams{
+		Host:  "github.com",
 		Owner: "owner1",
 		Repo:  "repo1",
 		Path:  "LICENSE",
@@ -47,7 +49,7 @@ func TestFetchRepositoryFiles_InvalidURL(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	t.Cleanup(ctrl.Finish)
 
-	mockClient := github.NewMockClient(ctrl)
+	mockClient := forge.NewMockClient(ctrl)
 	fetcher := NewFilesFetcher(mockClient)
 
 	ctx := context.Background()
@@ -59,12 +61,13 @@ func TestFetchRepositoryFiles_ErrorOnFile(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	t.Cleanup(ctrl.Finish)
 
-	mockClient := github.NewMockClient(ctrl)
+	mockClient := forge.NewMockClient(ctrl)
 	fetcher := NewFilesFetcher(mockClient)
 
 	repoURL := "https://github.com/owner1/repo1.git"
 	ctx := context.Background()
-	mockClient.EXPECT().GetFileContent(ctx, github.GetFileContentParams{
+	mockClient.EXPECT().GetFileContent(ctx, forge.GetFileContentParams{
+		Host:  "github.com",
 		Owner: "owner1",
 		Repo:  "repo1",
 		Path:  "README.md",
@@ -74,3 +77,24 @@ func TestFetchRepositoryFiles_ErrorOnFile(t *testing.T) {
 	_, err := fetcher.Fetch(ctx, repoURL, "main", "README.md")
 	require.Error(t, err)
 }
+
+func TestListRepositoryFiles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockClient := forge.NewMockClient(ctrl)
+	fetcher := NewFilesFetcher(mockClient)
+
+	ctx := context.Background()
+	mockClient.EXPECT().ListDirectory(ctx, forge.ListDirectoryParams{
+		Host:  "github.com",
+		Owner: "owner1",
+		Repo:  "repo1",
+		Path:  ".github/workflows",
+		Ref:   "main",
+	}).Return([]string{".github/workflows/ci.yaml"}, nil)
+
+	paths, err := fetcher.List(ctx, "https://github.com/owner1/repo1.git", "main", ".github/workflows")
+	require.NoError(t, err)
+	require.Equal(t, []string{".github/workflows/ci.yaml"}, paths)
+}
@@ -59,3 +59,18 @@ func (mr *MockFilesFetcherMockRecorder) Fetch(ctx, repoURL, ref any, files ...an
 	varargs := append([]any{ctx, repoURL, ref}, files...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fetch", reflect.TypeOf((*MockFilesFetcher)(nil).Fetch), varargs...)
 }
+
+// List mocks base method.
+func (m *MockFilesFetcher) List(ctx context.Context, repoURL, ref, dir string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, repoURL, ref, dir)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockFilesFetcherMockRecorder) List(ctx, repoURL, ref, dir any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockFilesFetcher)(nil).List), ctx, repoURL, ref, dir)
+}
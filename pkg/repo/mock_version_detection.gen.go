@@ -13,7 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
-	github "github.com/cryptellation/depsync/pkg/adapters/github"
+	forge "github.com/cryptellation/depsync/pkg/adapters/forge"
 	depgraph "github.com/cryptellation/depsync/pkg/depgraph"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -43,7 +43,7 @@ func (m *MockVersionDetector) EXPECT() *MockVersionDetectorMockRecorder {
 }
 
 // DetectAndSetCurrentVersions mocks base method.
-func (m *MockVersionDetector) DetectAndSetCurrentVersions(ctx context.Context, client github.Client, services map[string]*depgraph.Service) error {
+func (m *MockVersionDetector) DetectAndSetCurrentVersions(ctx context.Context, client forge.Client, services map[string]*depgraph.Service) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DetectAndSetCurrentVersions", ctx, client, services)
 	ret0, _ := ret[0].(error)
@@ -8,9 +8,10 @@ import (
 	"regexp"
 	"sort"
 
-	"github.com/cryptellation/depsync/pkg/adapters/github"
+	"github.com/cryptellation/depsync/pkg/adapters"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
 	"github.com/cryptellation/depsync/pkg/depgraph"
-	gh "github.com/google/go-github/v55/github"
+	"github.com/cryptellation/depsync/pkg/pool"
 	"golang.org/x/mod/semver"
 )
 
@@ -19,19 +20,19 @@ import (
 // and sets the field. Fails fast on any error except for no tags.
 func DetectAndSetCurrentVersions(
 	ctx context.Context,
-	client github.Client,
+	client forge.Client,
 	services map[string]*depgraph.Service,
 ) error {
 	for _, svc := range services {
-		owner, repo := parseOwnerAndRepo(svc.ModulePath)
-		if owner == "" || repo == "" {
-			return fmt.Errorf("invalid module path: %s", svc.ModulePath)
+		params, err := listTagsParams(svc)
+		if err != nil {
+			return err
 		}
-		tags, err := client.ListTags(ctx, owner, repo)
+		tags, err := client.ListTags(ctx, params)
 		if err != nil {
 			return fmt.Errorf("error fetching tags for %s: %w", svc.ModulePath, err)
 		}
-		latest := latestSemverTag(tags)
+		latest := LatestSemverTag(tags)
 		if latest != "" {
 			svc.LatestVersion = latest
 		}
@@ -39,15 +40,27 @@ func DetectAndSetCurrentVersions(
 	return nil
 }
 
-// latestSemverTag returns the latest semantic version tag (ignoring pre-releases and non-semver tags).
-func latestSemverTag(tags []*gh.RepositoryTag) string {
+// listTagsParams returns the parameters listing the tags of the repository of a service,
+// from its repository URL or, when unknown, from its module path.
+func listTagsParams(svc *depgraph.Service) (forge.ListTagsParams, error) {
+	location := svc.RepoURL
+	if location == "" {
+		location = svc.ModulePath
+	}
+	host, owner, repo := adapters.ParseRepository(location)
+	if host == "" {
+		return forge.ListTagsParams{}, fmt.Errorf("invalid module path: %s", svc.ModulePath)
+	}
+	return forge.ListTagsParams{Host: host, Owner: owner, Repo: repo}, nil
+}
+
+// LatestSemverTag returns the latest semantic version tag (ignoring pre-releases and non-semver tags).
+// It returns an empty string if there is no such tag.
+func LatestSemverTag(tags []forge.Tag) string {
 	semverRE := regexp.MustCompile(`^v[0-9]+\.[0-9]+\.[0-9]+$`)
 	var versions []string
 	for _, tag := range tags {
-		if tag == nil || tag.Name == nil {
-			continue
-		}
-		name := *tag.Name
+		name := tag.Name
 		if semverRE.MatchString(name) && semver.Prerelease(name) == "" {
 			versions = append(versions, name)
 		}
@@ -63,33 +76,46 @@ func latestSemverTag(tags []*gh.RepositoryTag) string {
 
 // VersionDetector defines the interface for version detection.
 type VersionDetector interface {
-	DetectAndSetCurrentVersions(ctx context.Context, client github.Client, services map[string]*depgraph.Service) error
+	DetectAndSetCurrentVersions(ctx context.Context, client forge.Client, services map[string]*depgraph.Service) error
 }
 
-type versionDetector struct{}
+type versionDetector struct {
+	concurrency int
+}
 
-func NewVersionDetector() VersionDetector {
-	return &versionDetector{}
+// NewVersionDetector creates a VersionDetector fetching the tags of at most concurrency
+// repositories at once.
+func NewVersionDetector(concurrency int) VersionDetector {
+	return &versionDetector{concurrency: concurrency}
 }
 
 func (v *versionDetector) DetectAndSetCurrentVersions(
 	ctx context.Context,
-	client github.Client,
+	client forge.Client,
 	services map[string]*depgraph.Service,
 ) error {
-	for _, svc := range services {
-		owner, repo := parseOwnerAndRepo(svc.ModulePath)
-		if owner == "" || repo == "" {
-			return fmt.Errorf("invalid module path: %s", svc.ModulePath)
+	// Sort the services so that the errors do not depend on the map order
+	paths := make([]string, 0, len(services))
+	for path := range services {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// Each call only sets the version of its own service
+	return pool.ForEach(ctx, v.concurrency, len(paths), func(ctx context.Context, i int) error {
+		svc := services[paths[i]]
+		params, err := listTagsParams(svc)
+		if err != nil {
+			return err
 		}
-		tags, err := client.ListTags(ctx, owner, repo)
+		tags, err := client.ListTags(ctx, params)
 		if err != nil {
 			return fmt.Errorf("error fetching tags for %s: %w", svc.ModulePath, err)
 		}
-		latest := latestSemverTag(tags)
+		latest := LatestSemverTag(tags)
 		if latest != "" {
 			svc.LatestVersion = latest
 		}
-	}
-	return nil
+		return nil
+	})
 }
@@ -7,9 +7,8 @@ import (
 	"context"
 	"testing"
 
-	"github.com/cryptellation/depsync/pkg/adapters/github"
+	"github.com/cryptellation/depsync/pkg/adapters/forge"
 	"github.com/cryptellation/depsync/pkg/depgraph"
-	gh "github.com/google/go-github/v55/github"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -18,7 +17,7 @@ func TestDetectAndSetCurrentVersions_HappyPath(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockClient := github.NewMockClient(ctrl)
+	mockClient := forge.NewMockClient(ctrl)
 	services := map[string]*depgraph.Service{
 		"github.com/example/A": {
 			ModulePath:   "github.com/example/A",
@@ -30,12 +29,14 @@ func TestDetectAndSetCurrentVersions_HappyPath(t *testing.T) {
 		},
 	}
 
-	mockClient.EXPECT().ListTags(gomock.Any(), "example", "A").Return([]*gh.RepositoryTag{
-		{Name: gh.String("v1.2.3")},
-		{Name: gh.String("v1.2.0")},
-		{Name: gh.String("v1.2.3-beta")}, // should be ignored
+	tagsA := forge.ListTagsParams{Host: "github.com", Owner: "example", Repo: "A"}
+	mockClient.EXPECT().ListTags(gomock.Any(), tagsA).Return([]forge.Tag{
+		{Name: "v1.2.3"},
+		{Name: "v1.2.0"},
+		{Name: "v1.2.3-beta"}, // should be ignored
 	}, nil)
-	mockClient.EXPECT().ListTags(gomock.Any(), "example", "B").Return([]*gh.RepositoryTag{}, nil) // no tags
+	tagsB := forge.ListTagsParams{Host: "github.com", Owner: "example", Repo: "B"}
+	mockClient.EXPECT().ListTags(gomock.Any(), tagsB).Return([]forge.Tag{}, nil) // no tags
 
 	err := DetectAndSetCurrentVersions(context.Background(), mockClient, services)
 	require.NoError(t, err)
@@ -0,0 +1,71 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// fileFormat is the content of the state file.
+type fileFormat struct {
+	Records []Record `json:"records"`
+}
+
+// fileStore is a Store persisting the records in a JSON file.
+type fileStore struct {
+	*memoryStore
+	path string
+}
+
+// NewFileStore creates a Store persisting the records in the JSON file at the given path.
+// The file is created on the first record if it does not exist.
+func NewFileStore(path string) (Store, error) {
+	var content fileFormat
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read state file %s: %w", path, err)
+	default:
+		if err := json.Unmarshal(data, &content); err != nil {
+			return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+		}
+	}
+
+	return &fileStore{
+		memoryStore: newMemoryStore(content.Records),
+		path:        path,
+	}, nil
+}
+
+// Record implements the Store interface.
+func (s *fileStore) Record(_ context.Context, key Key, event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.record(key, event)
+	return s.save()
+}
+
+// save writes the records to the file, through a temporary file to never leave it partially written.
+func (s *fileStore) save() error {
+	data, err := json.MarshalIndent(fileFormat{Records: s.list()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package state
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryStore is a Store keeping the records in memory.
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[Key]*Record
+	now     func() time.Time
+}
+
+// NewMemoryStore creates a Store that keeps the records in memory, for the duration of the process.
+func NewMemoryStore() Store {
+	return newMemoryStore(nil)
+}
+
+func newMemoryStore(records []Record) *memoryStore {
+	s := &memoryStore{
+		records: make(map[Key]*Record, len(records)),
+		now:     time.Now,
+	}
+	for i := range records {
+		s.records[records[i].Key] = &records[i]
+	}
+	return s
+}
+
+// Get implements the Store interface.
+func (s *memoryStore) Get(_ context.Context, key Key) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[key]
+	if !ok {
+		return Record{}, ErrNotFound
+	}
+	return copyRecord(r), nil
+}
+
+// Record implements the Store interface.
+func (s *memoryStore) Record(_ context.Context, key Key, event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.record(key, event)
+	return nil
+}
+
+func (s *memoryStore) record(key Key, event Event) {
+	if event.Time.IsZero() {
+		event.Time = s.now().UTC()
+	}
+
+	r, ok := s.records[key]
+	if !ok {
+		r = &Record{Key: key}
+		s.records[key] = r
+	}
+	r.apply(event)
+}
+
+// List implements the Store interface.
+func (s *memoryStore) List(_ context.Context) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.list(), nil
+}
+
+func (s *memoryStore) list() []Record {
+	records := make([]Record, 0, len(s.records))
+	for _, r := range s.records {
+		records = append(records, copyRecord(r))
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i].Key, records[j].Key
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.Dependency != b.Dependency {
+			return a.Dependency < b.Dependency
+		}
+		return a.Version < b.Version
+	})
+	return records
+}
+
+// Close implements the Store interface.
+func (s *memoryStore) Close() error {
+	return nil
+}
+
+// copyRecord returns a copy of the record that does not share its events.
+func copyRecord(r *Record) Record {
+	c := *r
+	c.Events = append([]Event(nil), r.Events...)
+	return c
+}
@@ -0,0 +1,128 @@
+//go:generate go run go.uber.org/mock/mockgen@v0.5.2 -destination=mock_state.gen.go -package=state -source=state.go Store
+
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cryptellation/depsync/pkg/config"
+)
+
+// ErrNotFound is returned when there is no record for a key.
+var ErrNotFound = errors.New("record not found")
+
+// Store backends.
+const (
+	// BackendMemory keeps the records in memory: they are lost at the end of the process.
+	BackendMemory = "memory"
+	BackendFile   = "file"
+)
+
+// DefaultPath is the default path of the state file, relative to the working directory.
+const DefaultPath = ".depsync/state.json"
+
+// Key identifies the lifecycle of an update: a dependency (or any other updated
+// subject, such as a synchronized file) of a service, at a target version.
+type Key struct {
+	Service    string `json:"service"`
+	Dependency string `json:"dependency"`
+	Version    string `json:"version"`
+}
+
+// EventType is the type of an event of an update lifecycle.
+type EventType string
+
+const (
+	// EventMismatchDetected is recorded when the update is detected as needed.
+	EventMismatchDetected EventType = "mismatch_detected"
+	// EventBranchPushed is recorded when the update branch is pushed.
+	EventBranchPushed EventType = "branch_pushed"
+	// EventPROpened is recorded when the pull request of the update is opened.
+	EventPROpened EventType = "pr_opened"
+	// EventPRMerged is recorded when the pull request is merged.
+	EventPRMerged EventType = "pr_merged"
+	// EventPRClosed is recorded when the pull request is closed without depsync (e.g. by a human).
+	EventPRClosed EventType = "pr_closed"
+	// EventPRDeleted is recorded when depsync deletes the pull request (e.g. because of conflicts).
+	EventPRDeleted EventType = "pr_deleted"
+	// EventVerificationFailed is recorded when the verification of the update fails, and its
+	// branch is not pushed. The update is retried by the next runs.
+	EventVerificationFailed EventType = "verification_failed"
+)
+
+// Event is an event of an update lifecycle.
+type Event struct {
+	Type       EventType `json:"type"`
+	Time       time.Time `json:"time"`
+	RepoURL    string    `json:"repo_url,omitempty"`
+	BranchName string    `json:"branch_name,omitempty"`
+	PRNumber   int       `json:"pr_number,omitempty"`
+	// Details describes the event (e.g. the failed verification command).
+	Details string `json:"details,omitempty"`
+}
+
+// Record is the lifecycle of an update.
+type Record struct {
+	Key Key `json:"key"`
+	// Status is the type of the last event.
+	Status     EventType `json:"status"`
+	RepoURL    string    `json:"repo_url,omitempty"`
+	BranchName string    `json:"branch_name,omitempty"`
+	PRNumber   int       `json:"pr_number,omitempty"`
+	Events     []Event   `json:"events"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// apply updates the record with a new event.
+func (r *Record) apply(event Event) {
+	r.Status = event.Type
+	if event.RepoURL != "" {
+		r.RepoURL = event.RepoURL
+	}
+	if event.BranchName != "" {
+		r.BranchName = event.BranchName
+	}
+	if event.PRNumber != 0 {
+		r.PRNumber = event.PRNumber
+	}
+	r.Events = append(r.Events, event)
+	r.UpdatedAt = event.Time
+}
+
+// Store persists the lifecycle of the updates between runs.
+type Store interface {
+	// Get returns the record of the key, or ErrNotFound.
+	Get(ctx context.Context, key Key) (Record, error)
+	// Record appends an event to the record of the key, creating it if needed.
+	// The event time is set to the current time when zero.
+	Record(ctx context.Context, key Key, event Event) error
+	// List returns every record, sorted by key.
+	List(ctx context.Context) ([]Record, error)
+	// Close releases the resources of the store.
+	Close() error
+}
+
+// Open opens the store of the configured backend. The records persist in a file by default,
+// at DefaultPath unless configured.
+func Open(cfg config.StateConfig) (Store, error) {
+	backend := cfg.Backend
+	if backend == "" {
+		backend = BackendFile
+	}
+
+	switch backend {
+	case BackendMemory:
+		return NewMemoryStore(), nil
+	case BackendFile:
+		path := cfg.Path
+		if path == "" {
+			path = DefaultPath
+		}
+		return NewFileStore(path)
+	default:
+		return nil, fmt.Errorf("unknown state backend %q", backend)
+	}
+}
@@ -0,0 +1,90 @@
+//go:build unit
+// +build unit
+
+package state
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_Record(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	key := Key{Service: "github.com/example/A", Dependency: "github.com/example/B", Version: "v1.1.0"}
+
+	_, err := store.Get(ctx, key)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Record(ctx, key, Event{Type: EventMismatchDetected}))
+	require.NoError(t, store.Record(ctx, key, Event{Type: EventBranchPushed, BranchName: "depsync/update"}))
+	require.NoError(t, store.Record(ctx, key, Event{Type: EventPROpened, PRNumber: 42}))
+
+	record, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, EventPROpened, record.Status)
+	require.Equal(t, "depsync/update", record.BranchName)
+	require.Equal(t, 42, record.PRNumber)
+	require.Len(t, record.Events, 3)
+	require.False(t, record.Events[0].Time.IsZero())
+	require.Equal(t, record.Events[2].Time, record.UpdatedAt)
+}
+
+func TestFileStore_Persistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state", "depsync.json")
+	keyA := Key{Service: "github.com/example/A", Dependency: "github.com/example/C", Version: "v1.1.0"}
+	keyB := Key{Service: "github.com/example/A", Dependency: "github.com/example/B", Version: "v1.0.0"}
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	store, err := NewFileStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Record(ctx, keyA, Event{Type: EventPROpened, PRNumber: 1, Time: at}))
+	require.NoError(t, store.Record(ctx, keyB, Event{Type: EventPRClosed, PRNumber: 2, Time: at}))
+	require.NoError(t, store.Close())
+
+	reopened, err := NewFileStore(path)
+	require.NoError(t, err)
+	records, err := reopened.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []Record{
+		{
+			Key:       keyB,
+			Status:    EventPRClosed,
+			PRNumber:  2,
+			Events:    []Event{{Type: EventPRClosed, PRNumber: 2, Time: at}},
+			UpdatedAt: at,
+		},
+		{
+			Key:       keyA,
+			Status:    EventPROpened,
+			PRNumber:  1,
+			Events:    []Event{{Type: EventPROpened, PRNumber: 1, Time: at}},
+			UpdatedAt: at,
+		},
+	}, records)
+}
+
+func TestOpen(t *testing.T) {
+	// The records persist in a file by default
+	store, err := Open(config.StateConfig{})
+	require.NoError(t, err)
+	require.IsType(t, &fileStore{}, store)
+	require.Equal(t, DefaultPath, store.(*fileStore).path)
+
+	store, err = Open(config.StateConfig{Path: filepath.Join(t.TempDir(), "state.json")})
+	require.NoError(t, err)
+	require.IsType(t, &fileStore{}, store)
+
+	store, err = Open(config.StateConfig{Backend: BackendMemory})
+	require.NoError(t, err)
+	require.IsType(t, &memoryStore{}, store)
+
+	_, err = Open(config.StateConfig{Backend: "unknown"})
+	require.Error(t, err)
+}
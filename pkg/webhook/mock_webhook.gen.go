@@ -0,0 +1,107 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webhook.go
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock_webhook.gen.go -package=webhook -source=webhook.go Reactor,Dispatcher
+//
+
+// Package webhook is a generated GoMock package.
+package webhook
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReactor is a mock of Reactor interface.
+type MockReactor struct {
+	ctrl     *gomock.Controller
+	recorder *MockReactorMockRecorder
+	isgomock struct{}
+}
+
+// MockReactorMockRecorder is the mock recorder for MockReactor.
+type MockReactorMockRecorder struct {
+	mock *MockReactor
+}
+
+// NewMockReactor creates a new mock instance.
+func NewMockReactor(ctrl *gomock.Controller) *MockReactor {
+	mock := &MockReactor{ctrl: ctrl}
+	mock.recorder = &MockReactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReactor) EXPECT() *MockReactorMockRecorder {
+	return m.recorder
+}
+
+// CheckBranchPullRequests mocks base method.
+func (m *MockReactor) CheckBranchPullRequests(ctx context.Context, repoURL, branch string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckBranchPullRequests", ctx, repoURL, branch)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckBranchPullRequests indicates an expected call of CheckBranchPullRequests.
+func (mr *MockReactorMockRecorder) CheckBranchPullRequests(ctx, repoURL, branch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckBranchPullRequests", reflect.TypeOf((*MockReactor)(nil).CheckBranchPullRequests), ctx, repoURL, branch)
+}
+
+// PropagateTag mocks base method.
+func (m *MockReactor) PropagateTag(ctx context.Context, repoURL, tag string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PropagateTag", ctx, repoURL, tag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PropagateTag indicates an expected call of PropagateTag.
+func (mr *MockReactorMockRecorder) PropagateTag(ctx, repoURL, tag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PropagateTag", reflect.TypeOf((*MockReactor)(nil).PropagateTag), ctx, repoURL, tag)
+}
+
+// MockDispatcher is a mock of Dispatcher interface.
+type MockDispatcher struct {
+	ctrl     *gomock.Controller
+	recorder *MockDispatcherMockRecorder
+	isgomock struct{}
+}
+
+// MockDispatcherMockRecorder is the mock recorder for MockDispatcher.
+type MockDispatcherMockRecorder struct {
+	mock *MockDispatcher
+}
+
+// NewMockDispatcher creates a new mock instance.
+func NewMockDispatcher(ctrl *gomock.Controller) *MockDispatcher {
+	mock := &MockDispatcher{ctrl: ctrl}
+	mock.recorder = &MockDispatcherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDispatcher) EXPECT() *MockDispatcherMockRecorder {
+	return m.recorder
+}
+
+// Trigger mocks base method.
+func (m *MockDispatcher) Trigger(name string, job func(context.Context) error) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trigger", name, job)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Trigger indicates an expected call of Trigger.
+func (mr *MockDispatcherMockRecorder) Trigger(name, job any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trigger", reflect.TypeOf((*MockDispatcher)(nil).Trigger), name, job)
+}
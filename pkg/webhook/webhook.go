@@ -0,0 +1,129 @@
+//go:generate go run go.uber.org/mock/mockgen@v0.5.2 -destination=mock_webhook.gen.go -package=webhook -source=webhook.go Reactor,Dispatcher
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cryptellation/depsync/pkg/logging"
+	"github.com/google/go-github/v55/github"
+	"go.uber.org/zap"
+)
+
+// tagRefPrefix is the prefix of the tag references of push events.
+const tagRefPrefix = "refs/tags/"
+
+// Reactor reacts to the GitHub events with targeted re-evaluations.
+type Reactor interface {
+	// PropagateTag updates the dependencies on the module of the repository to the new tag.
+	PropagateTag(ctx context.Context, repoURL, tag string) error
+	// CheckBranchPullRequests follows up on the open pull requests of the repository branch.
+	CheckBranchPullRequests(ctx context.Context, repoURL, branch string) error
+}
+
+// Dispatcher executes the reactions asynchronously, as GitHub expects quick answers.
+type Dispatcher interface {
+	// Trigger queues the job and returns false if it can't.
+	Trigger(name string, job func(ctx context.Context) error) bool
+}
+
+// handler receives the GitHub webhooks.
+type handler struct {
+	secret     []byte
+	reactor    Reactor
+	dispatcher Dispatcher
+}
+
+// NewHandler creates an HTTP handler receiving GitHub webhooks signed with the secret.
+// It reacts to new tags (create and push events), completed checks (check_suite and
+// check_run events) and pull requests updates (pull_request events).
+func NewHandler(secret []byte, reactor Reactor, dispatcher Dispatcher) http.Handler {
+	return &handler{
+		secret:     secret,
+		reactor:    reactor,
+		dispatcher: dispatcher,
+	}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logging.C(r.Context())
+
+	payload, err := github.ValidatePayload(r, h.secret)
+	if err != nil {
+		logger.Warn("Invalid webhook signature", zap.Error(err))
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	eventType := github.WebHookType(r)
+	event, err := github.ParseWebHook(eventType, payload)
+	if err != nil {
+		logger.Warn("Invalid webhook payload", zap.String("event", eventType), zap.Error(err))
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	name, job := h.reaction(event)
+	if job == nil {
+		logger.Debug("Ignoring webhook event", zap.String("event", eventType))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !h.dispatcher.Trigger(name, job) {
+		logger.Error("Failed to queue webhook reaction", zap.String("reaction", name))
+		http.Error(w, "busy", http.StatusServiceUnavailable)
+		return
+	}
+	logger.Info("Webhook reaction queued", zap.String("event", eventType), zap.String("reaction", name))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// reaction returns the reaction to the event, or a nil job if the event should be ignored.
+func (h *handler) reaction(event interface{}) (string, func(context.Context) error) {
+	switch e := event.(type) {
+	case *github.CreateEvent:
+		if e.GetRefType() == "tag" {
+			return h.tagReaction(e.GetRepo().GetHTMLURL(), e.GetRef())
+		}
+	case *github.PushEvent:
+		if e.GetCreated() && strings.HasPrefix(e.GetRef(), tagRefPrefix) {
+			return h.tagReaction(e.GetRepo().GetHTMLURL(), strings.TrimPrefix(e.GetRef(), tagRefPrefix))
+		}
+	case *github.CheckSuiteEvent:
+		if e.GetAction() == "completed" {
+			return h.branchReaction(e.GetRepo().GetHTMLURL(), e.GetCheckSuite().GetHeadBranch())
+		}
+	case *github.CheckRunEvent:
+		if e.GetAction() == "completed" {
+			return h.branchReaction(e.GetRepo().GetHTMLURL(), e.GetCheckRun().GetCheckSuite().GetHeadBranch())
+		}
+	case *github.PullRequestEvent:
+		switch e.GetAction() {
+		case "closed", "reopened", "synchronize":
+			return h.branchReaction(e.GetRepo().GetHTMLURL(), e.GetPullRequest().GetHead().GetRef())
+		}
+	}
+	return "", nil
+}
+
+// tagReaction returns the reaction to a new tag.
+func (h *handler) tagReaction(repoURL, tag string) (string, func(context.Context) error) {
+	return fmt.Sprintf("propagate tag %s of %s", tag, repoURL), func(ctx context.Context) error {
+		return h.reactor.PropagateTag(ctx, repoURL, tag)
+	}
+}
+
+// branchReaction returns the reaction to an update of the pull requests of a branch.
+func (h *handler) branchReaction(repoURL, branch string) (string, func(context.Context) error) {
+	if branch == "" {
+		return "", nil
+	}
+	return fmt.Sprintf("check pull requests of %s on %s", branch, repoURL), func(ctx context.Context) error {
+		return h.reactor.CheckBranchPullRequests(ctx, repoURL, branch)
+	}
+}
@@ -0,0 +1,135 @@
+//go:build unit
+// +build unit
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var testSecret = []byte("s3cr3t")
+
+// newRequest creates a webhook request signed with the secret.
+func newRequest(event string, payload []byte, secret []byte) *http.Request {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+// expectTriggerAndRun expects a job to be triggered and runs it immediately.
+func expectTriggerAndRun(dispatcher *MockDispatcher) {
+	dispatcher.EXPECT().Trigger(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ string, job func(context.Context) error) bool {
+			return job(context.Background()) == nil
+		})
+}
+
+func TestHandler_CreateTag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	reactor := NewMockReactor(ctrl)
+	dispatcher := NewMockDispatcher(ctrl)
+	h := NewHandler(testSecret, reactor, dispatcher)
+
+	expectTriggerAndRun(dispatcher)
+	reactor.EXPECT().PropagateTag(gomock.Any(), "https://github.com/test/lib", "v1.2.0").Return(nil)
+
+	payload := []byte(`{"ref":"v1.2.0","ref_type":"tag","repository":{"html_url":"https://github.com/test/lib"}}`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newRequest("create", payload, testSecret))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+}
+
+func TestHandler_PushTag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	reactor := NewMockReactor(ctrl)
+	dispatcher := NewMockDispatcher(ctrl)
+	h := NewHandler(testSecret, reactor, dispatcher)
+
+	expectTriggerAndRun(dispatcher)
+	reactor.EXPECT().PropagateTag(gomock.Any(), "https://github.com/test/lib", "v1.3.0").Return(nil)
+
+	payload := []byte(`{"ref":"refs/tags/v1.3.0","created":true,"repository":{"html_url":"https://github.com/test/lib"}}`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newRequest("push", payload, testSecret))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+}
+
+func TestHandler_CheckSuiteCompleted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	reactor := NewMockReactor(ctrl)
+	dispatcher := NewMockDispatcher(ctrl)
+	h := NewHandler(testSecret, reactor, dispatcher)
+
+	expectTriggerAndRun(dispatcher)
+	reactor.EXPECT().CheckBranchPullRequests(gomock.Any(), "https://github.com/test/svc", "depsync/update").Return(nil)
+
+	payload := []byte(`{"action":"completed","check_suite":{"head_branch":"depsync/update"},` +
+		`"repository":{"html_url":"https://github.com/test/svc"}}`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newRequest("check_suite", payload, testSecret))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+}
+
+func TestHandler_PullRequestClosed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	reactor := NewMockReactor(ctrl)
+	dispatcher := NewMockDispatcher(ctrl)
+	h := NewHandler(testSecret, reactor, dispatcher)
+
+	expectTriggerAndRun(dispatcher)
+	reactor.EXPECT().CheckBranchPullRequests(gomock.Any(), "https://github.com/test/svc", "depsync/update").Return(nil)
+
+	payload := []byte(`{"action":"closed","number":3,"pull_request":{"head":{"ref":"depsync/update"}},` +
+		`"repository":{"html_url":"https://github.com/test/svc"}}`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newRequest("pull_request", payload, testSecret))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+}
+
+func TestHandler_IgnoredEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	h := NewHandler(testSecret, NewMockReactor(ctrl), NewMockDispatcher(ctrl))
+
+	payload := []byte(`{"ref":"feature","ref_type":"branch","repository":{"html_url":"https://github.com/test/lib"}}`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newRequest("create", payload, testSecret))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestHandler_InvalidSignature(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	h := NewHandler(testSecret, NewMockReactor(ctrl), NewMockDispatcher(ctrl))
+
+	payload := []byte(`{"ref":"v1.2.0","ref_type":"tag"}`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newRequest("create", payload, []byte("wrong")))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestHandler_QueueFull(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dispatcher := NewMockDispatcher(ctrl)
+	h := NewHandler(testSecret, NewMockReactor(ctrl), dispatcher)
+
+	dispatcher.EXPECT().Trigger(gomock.Any(), gomock.Any()).Return(false)
+
+	payload := []byte(`{"ref":"v1.2.0","ref_type":"tag","repository":{"html_url":"https://github.com/test/lib"}}`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newRequest("create", payload, testSecret))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
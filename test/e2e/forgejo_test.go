@@ -0,0 +1,176 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/depsync"
+	"github.com/stretchr/testify/require"
+)
+
+// forgejo is a client of the Forgejo API used to set up the repositories of the tests.
+type forgejo struct {
+	t       *testing.T
+	baseURL string
+	token   string
+	owner   string
+}
+
+// newForgejo creates a client of the Forgejo instance of the FORGEJO_URL and FORGEJO_TOKEN
+// environment variables, or skips the test when they are not set.
+func newForgejo(t *testing.T) *forgejo {
+	baseURL, token := os.Getenv("FORGEJO_URL"), os.Getenv("FORGEJO_TOKEN")
+	if baseURL == "" || token == "" {
+		t.Skip("FORGEJO_URL and FORGEJO_TOKEN environment variables are required")
+	}
+
+	f := &forgejo{t: t, baseURL: strings.TrimSuffix(baseURL, "/"), token: token}
+	var user struct {
+		Login string `json:"login"`
+	}
+	f.do(http.MethodGet, "/user", nil, &user)
+	f.owner = user.Login
+	return f
+}
+
+// do sends a request to the API and decodes the JSON response in out, unless nil.
+func (f *forgejo) do(method, path string, body, out any) {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(f.t, err)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, f.baseURL+"/api/v1"+path, reader)
+	require.NoError(f.t, err)
+	req.Header.Set("Authorization", "token "+f.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(f.t, err)
+	defer resp.Body.Close()
+	require.Less(f.t, resp.StatusCode, http.StatusBadRequest, "%s %s", method, path)
+
+	if out != nil {
+		require.NoError(f.t, json.NewDecoder(resp.Body).Decode(out))
+	}
+}
+
+// host returns the host of the instance, with its port.
+func (f *forgejo) host() string {
+	u, err := url.Parse(f.baseURL)
+	require.NoError(f.t, err)
+	return u.Host
+}
+
+// createRepository creates a repository with the given files on its main branch, and
+// deletes it at the end of the test. It returns the repository URL.
+func (f *forgejo) createRepository(name string, files map[string]string) string {
+	f.do(http.MethodPost, "/user/repos", map[string]any{
+		"name":           name,
+		"auto_init":      true,
+		"default_branch": "main",
+	}, nil)
+	f.t.Cleanup(func() { f.do(http.MethodDelete, fmt.Sprintf("/repos/%s/%s", f.owner, name), nil, nil) })
+
+	for path, content := range files {
+		f.do(http.MethodPost, fmt.Sprintf("/repos/%s/%s/contents/%s", f.owner, name, path), map[string]any{
+			"content": base64.StdEncoding.EncodeToString([]byte(content)),
+			"message": "add " + path,
+			"branch":  "main",
+		}, nil)
+	}
+	return fmt.Sprintf("%s/%s/%s.git", f.baseURL, f.owner, name)
+}
+
+// pullRequest is a Forgejo pull request.
+type pullRequest struct {
+	Number int  `json:"number"`
+	Merged bool `json:"merged"`
+	Head   struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+}
+
+func TestRun_Forgejo(t *testing.T) {
+	f := newForgejo(t)
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+
+	// The service is consistent with the library, but misses the synchronized file
+	hostname := strings.Split(f.host(), ":")[0]
+	lib := fmt.Sprintf("lib-%d", suffix)
+	libURL := f.createRepository(lib, map[string]string{
+		"go.mod": fmt.Sprintf("module %s/%s/%s\n\ngo 1.23\n", hostname, f.owner, lib),
+	})
+	f.do(http.MethodPost, fmt.Sprintf("/repos/%s/%s/tags", f.owner, lib), map[string]any{
+		"tag_name": "v1.0.0",
+		"target":   "main",
+	}, nil)
+
+	svc := fmt.Sprintf("svc-%d", suffix)
+	svcURL := f.createRepository(svc, map[string]string{
+		"go.mod": fmt.Sprintf("module %s/%s/%s\n\ngo 1.23\n\nrequire %s/%s/%s v1.0.0\n",
+			hostname, f.owner, svc, hostname, f.owner, lib),
+	})
+	templatesURL := f.createRepository(fmt.Sprintf("templates-%d", suffix), map[string]string{
+		".golangci.yml": "run:\n  timeout: 5m\n",
+	})
+
+	cfg := &config.Config{
+		Repositories: []string{libURL, svcURL},
+		Git:          config.GitConfig{Author: config.GitAuthor{Name: "depsync", Email: "depsync@example.com"}},
+		State:        config.StateConfig{Path: filepath.Join(t.TempDir(), "state.json")},
+		Forges: []config.ForgeConfig{{
+			Type:     config.ForgeGitea,
+			Host:     f.host(),
+			BaseURL:  f.baseURL + "/api/v1/",
+			TokenEnv: "FORGEJO_TOKEN",
+		}},
+		Files: []config.FileSync{{
+			Source:  config.FileSource{Repository: templatesURL, Path: ".golangci.yml", Ref: "main"},
+			Targets: []config.FileTarget{{Repository: svcURL, Path: ".golangci.yml"}},
+		}},
+		DeleteConflictedPRs: true,
+		Concurrency:         1,
+	}
+	c, err := depsync.New(cfg, "")
+	require.NoError(t, err)
+	defer c.Close()
+
+	// The first run opens the pull request of the synchronized file
+	require.NoError(t, c.Run(ctx))
+	var prs []pullRequest
+	f.do(http.MethodGet, fmt.Sprintf("/repos/%s/%s/pulls?state=open", f.owner, svc), nil, &prs)
+	require.Len(t, prs, 1)
+	require.True(t, strings.HasPrefix(prs[0].Head.Ref, "depsync/sync-"), prs[0].Head.Ref)
+
+	// The next run merges it once its checks passed
+	f.do(http.MethodPost, fmt.Sprintf("/repos/%s/%s/statuses/%s", f.owner, svc, prs[0].Head.SHA), map[string]any{
+		"state":   "success",
+		"context": "e2e",
+	}, nil)
+	require.NoError(t, c.Run(ctx))
+
+	var pr pullRequest
+	f.do(http.MethodGet, fmt.Sprintf("/repos/%s/%s/pulls/%d", f.owner, svc, prs[0].Number), nil, &pr)
+	require.True(t, pr.Merged)
+}
@@ -0,0 +1,101 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cryptellation/depsync/pkg/adapters/local"
+	"github.com/cryptellation/depsync/pkg/config"
+	"github.com/cryptellation/depsync/pkg/depsync"
+	"github.com/stretchr/testify/require"
+)
+
+// git runs a git command in a directory.
+func git(t *testing.T, dir string, args ...string) {
+	args = append([]string{"-c", "user.name=e2e", "-c", "user.email=e2e@example.com"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+// createLocalRepository creates the bare repository <root>/org/<name>.git with the given
+// files on its main branch. It returns the repository URL.
+func createLocalRepository(t *testing.T, root, name string, files map[string]string) string {
+	bare := filepath.Join(root, "org", name+".git")
+	require.NoError(t, os.MkdirAll(bare, 0o755))
+	git(t, bare, "init", "--bare", "--initial-branch=main")
+
+	work := t.TempDir()
+	git(t, work, "clone", bare, ".")
+	git(t, work, "checkout", "-b", "main")
+	for path, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(work, path), []byte(content), 0o600))
+	}
+	git(t, work, "add", ".")
+	git(t, work, "commit", "-m", "initial")
+	git(t, work, "tag", "v1.0.0")
+	git(t, work, "push", "origin", "main", "--tags")
+	return "file://" + bare
+}
+
+// localPullRequests reads the pull requests of a repository of the local forge.
+func localPullRequests(t *testing.T, root, name string) []local.PullRequest {
+	data, err := os.ReadFile(filepath.Join(root, local.PullRequestsFile))
+	require.NoError(t, err)
+	var prs map[string][]local.PullRequest
+	require.NoError(t, json.Unmarshal(data, &prs))
+	return prs[filepath.Join("org", name+".git")]
+}
+
+func TestRun_Local(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+
+	// The service is consistent with the library, but misses the synchronized file
+	libURL := createLocalRepository(t, root, "lib", map[string]string{
+		"go.mod": "module example.com/org/lib\n\ngo 1.23\n",
+	})
+	svcURL := createLocalRepository(t, root, "svc", map[string]string{
+		"go.mod": "module example.com/org/svc\n\ngo 1.23\n\nrequire example.com/org/lib v1.0.0\n",
+	})
+	templatesURL := createLocalRepository(t, root, "templates", map[string]string{
+		".golangci.yml": "run:\n  timeout: 5m\n",
+	})
+
+	cfg := &config.Config{
+		Repositories: []string{libURL, svcURL},
+		Git:          config.GitConfig{Author: config.GitAuthor{Name: "depsync", Email: "depsync@example.com"}},
+		State:        config.StateConfig{Path: filepath.Join(t.TempDir(), "state.json")},
+		Forges:       []config.ForgeConfig{{Type: config.ForgeLocal, Host: config.LocalForgeHost, Path: root}},
+		Files: []config.FileSync{{
+			Source:  config.FileSource{Repository: templatesURL, Path: ".golangci.yml", Ref: "main"},
+			Targets: []config.FileTarget{{Repository: svcURL, Path: ".golangci.yml"}},
+		}},
+		DeleteConflictedPRs: true,
+		Concurrency:         1,
+	}
+	c, err := depsync.New(cfg, "")
+	require.NoError(t, err)
+	defer c.Close()
+
+	// The first run opens the pull request of the synchronized file
+	require.NoError(t, c.Run(ctx))
+	prs := localPullRequests(t, root, "svc")
+	require.Len(t, prs, 1)
+	require.True(t, strings.HasPrefix(prs[0].Head, "depsync/sync-"), prs[0].Head)
+	require.Equal(t, "open", prs[0].State)
+
+	// The next run merges it, as the simulated checks passed
+	require.NoError(t, c.Run(ctx))
+	prs = localPullRequests(t, root, "svc")
+	require.Equal(t, "merged", prs[0].State)
+}